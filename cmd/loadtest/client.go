@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+type operation struct {
+	WalletUUID  string `json:"valletId"`
+	Operation   string `json:"operationType"`
+	Amount      int    `json:"amount"`
+	ReferenceID string `json:"referenceId"`
+}
+
+// delta is how much the operation changes the balance of its wallet once
+// applied with the given fee.
+func (o operation) delta(fee int) int {
+	if o.Operation == types.OperationTypeWithdraw {
+		return -(o.Amount + fee)
+	}
+	return o.Amount
+}
+
+// result is the outcome of a single request. Status is 0 when no response
+// was received, so the operation may or may not have been applied.
+type result struct {
+	op      operation
+	status  int
+	latency time.Duration
+	fee     int
+	// code and message come from the problem+json body of an error response
+	code    string
+	message string
+}
+
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+func newClient(cfg *config) *client {
+	return &client{
+		baseURL: strings.TrimRight(cfg.url, "/"),
+		apiKey:  cfg.apiKey,
+		http:    newHTTPClient(cfg),
+	}
+}
+
+func (c *client) do(ctx context.Context, path string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	return c.http.Do(req)
+}
+
+func (c *client) updateBalance(ctx context.Context, op operation) result {
+	start := time.Now()
+	resp, err := c.do(ctx, "/api/v1/wallet", op)
+	if err != nil {
+		return result{op: op, latency: time.Since(start), message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Fee    types.Fee `json:"fee"`
+		Code   string    `json:"code"`
+		Detail string    `json:"detail"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	res := result{op: op, status: resp.StatusCode, latency: time.Since(start), code: body.Code, message: body.Detail}
+
+	if resp.StatusCode == http.StatusOK {
+		if decodeErr != nil {
+			// Операция проведена, но комиссию узнать не удалось
+			res.status = 0
+			res.message = fmt.Sprintf("failed to decode response: %v", decodeErr)
+			return res
+		}
+		res.fee = body.Fee.Total
+	}
+	return res
+}
+
+// balances reads the current balances of the wallets. Every wallet must exist.
+func (c *client) balances(ctx context.Context, wallets []string) (map[string]int, error) {
+	resp, err := c.do(ctx, "/api/v1/wallet/balances", types.BalancesRequest{WalletUUIDs: wallets})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var body struct {
+		Balances []types.WalletBalance `json:"balances"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	balances := make(map[string]int, len(body.Balances))
+	for _, b := range body.Balances {
+		if !b.Found {
+			return nil, fmt.Errorf("wallet %s: %w", b.WalletUUID, types.ErrWalletNotFound)
+		}
+		balances[b.WalletUUID] = b.Balance
+	}
+	return balances, nil
+}
@@ -0,0 +1,265 @@
+// loadtest sends DEPOSIT and WITHDRAW operations to a running wallet service
+// at a fixed or growing rate, reports latencies and response codes and checks
+// that the balances moved exactly by the operations that succeeded.
+//
+//	loadtest -rate 100 -duration 30s
+//	loadtest -mode ramp -start-rate 10 -rate 500 -duration 1m
+//	loadtest -distribution hot -hot-wallet a1b2c3e4-... -withdraw-ratio 0.3
+//	loadtest -rate 200 -json > report.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+const (
+	modeConstant = "constant"
+	modeRamp     = "ramp"
+
+	distributionUniform = "uniform"
+	distributionHot     = "hot"
+)
+
+// кошельки из seed-данных
+var defaultWallets = []string{
+	"a1b2c3e4-5678-9012-3456-789012345678",
+	"b2c3d4e5-6789-0123-4567-890123456789",
+	"c3d4e5f6-7890-1234-5678-901234567890",
+	"d4e5f6a7-8901-2345-6789-012345678901",
+	"e5f6a7b8-9012-3456-7890-123456789012",
+}
+
+type config struct {
+	url           string
+	apiKey        string
+	mode          string
+	rate          float64
+	startRate     float64
+	duration      time.Duration
+	wallets       []string
+	distribution  string
+	hotWallet     string
+	withdrawRatio float64
+	minAmount     int
+	maxAmount     int
+	maxInFlight   int
+	timeout       time.Duration
+	check         bool
+	json          bool
+}
+
+func main() {
+	ok, err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(2)
+	}
+}
+
+func parseFlags(args []string) (*config, error) {
+	cfg := &config{}
+	var wallets string
+
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	fs.StringVar(&cfg.url, "url", "http://localhost:3000", "base URL of the wallet service")
+	fs.StringVar(&cfg.apiKey, "api-key", os.Getenv("API_KEY"), "API key with wallet:read and wallet:write scopes")
+	fs.StringVar(&cfg.mode, "mode", modeConstant, "constant or ramp")
+	fs.Float64Var(&cfg.rate, "rate", 100, "requests per second, the final rate in ramp mode")
+	fs.Float64Var(&cfg.startRate, "start-rate", 1, "requests per second at the start of a ramp")
+	fs.DurationVar(&cfg.duration, "duration", 10*time.Second, "how long to generate load")
+	fs.StringVar(&wallets, "wallets", strings.Join(defaultWallets, ","), "comma-separated wallet UUIDs")
+	fs.StringVar(&cfg.distribution, "distribution", distributionUniform, "uniform or hot")
+	fs.StringVar(&cfg.hotWallet, "hot-wallet", "", "wallet that gets all the load in hot mode, the first of -wallets by default")
+	fs.Float64Var(&cfg.withdrawRatio, "withdraw-ratio", 0.5, "share of WITHDRAW operations")
+	fs.IntVar(&cfg.minAmount, "min-amount", 10, "minimal operation amount")
+	fs.IntVar(&cfg.maxAmount, "max-amount", 1000, "maximal operation amount")
+	fs.IntVar(&cfg.maxInFlight, "max-in-flight", 200, "requests waiting for a response at most, the rest are dropped")
+	fs.DurationVar(&cfg.timeout, "timeout", 5*time.Second, "timeout of a single request")
+	fs.BoolVar(&cfg.check, "check", true, "compare the balances with the successful operations at the end")
+	fs.BoolVar(&cfg.json, "json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	for _, w := range strings.Split(wallets, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			cfg.wallets = append(cfg.wallets, w)
+		}
+	}
+	if cfg.distribution == distributionHot {
+		if cfg.hotWallet == "" && len(cfg.wallets) > 0 {
+			cfg.hotWallet = cfg.wallets[0]
+		}
+		cfg.wallets = []string{cfg.hotWallet}
+	}
+
+	return cfg, cfg.validate()
+}
+
+func (c *config) validate() error {
+	switch {
+	case c.mode != modeConstant && c.mode != modeRamp:
+		return fmt.Errorf("unknown mode %q", c.mode)
+	case c.distribution != distributionUniform && c.distribution != distributionHot:
+		return fmt.Errorf("unknown distribution %q", c.distribution)
+	case len(c.wallets) == 0 || c.wallets[0] == "":
+		return errors.New("no wallets to load")
+	case len(c.wallets) > types.MaxBalancesLookup:
+		return fmt.Errorf("at most %d wallets are supported", types.MaxBalancesLookup)
+	case c.rate <= 0 || (c.mode == modeRamp && c.startRate <= 0):
+		return errors.New("rates must be positive")
+	case c.duration <= 0:
+		return errors.New("duration must be positive")
+	case c.withdrawRatio < 0 || c.withdrawRatio > 1:
+		return errors.New("withdraw-ratio must be between 0 and 1")
+	case c.minAmount <= 0 || c.maxAmount < c.minAmount:
+		return errors.New("amounts must be positive and min-amount must not exceed max-amount")
+	case c.maxInFlight <= 0:
+		return errors.New("max-in-flight must be positive")
+	}
+
+	for _, w := range c.wallets {
+		if _, err := uuid.Parse(w); err != nil {
+			return fmt.Errorf("wallet %q is not a valid UUID", w)
+		}
+	}
+	return nil
+}
+
+// rateAt is the request rate the generator keeps after elapsed time. A ramp
+// grows linearly from the start rate to the final one.
+func (c *config) rateAt(elapsed time.Duration) float64 {
+	if c.mode == modeRamp {
+		return c.startRate + (c.rate-c.startRate)*elapsed.Seconds()/c.duration.Seconds()
+	}
+	return c.rate
+}
+
+// nextOperation picks the wallet, type and amount of the next request. The
+// reference ID is generated here, like a real client would, so that a retry
+// can never apply the operation twice.
+func (c *config) nextOperation() operation {
+	op := operation{
+		WalletUUID:  c.wallets[rand.IntN(len(c.wallets))],
+		Operation:   types.OperationTypeDeposit,
+		Amount:      c.minAmount + rand.IntN(c.maxAmount-c.minAmount+1),
+		ReferenceID: uuid.NewString(),
+	}
+	if rand.Float64() < c.withdrawRatio {
+		op.Operation = types.OperationTypeWithdraw
+	}
+	return op
+}
+
+// run returns false if the consistency check found a mismatch.
+func run(args []string) (bool, error) {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := newClient(cfg)
+
+	var initial map[string]int
+	if cfg.check {
+		if initial, err = c.balances(ctx, cfg.wallets); err != nil {
+			return false, fmt.Errorf("failed to read initial balances: %w", err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "loadtest: %s load on %d wallet(s) for %s\n", cfg.mode, len(cfg.wallets), cfg.duration)
+	st := newStats()
+	elapsed := generate(ctx, cfg, c, st)
+
+	rep := st.report(cfg, elapsed)
+	if cfg.check {
+		// Проверку делаем и после Ctrl+C: все отправленные запросы уже завершены
+		final, err := c.balances(context.Background(), cfg.wallets)
+		if err != nil {
+			return false, fmt.Errorf("failed to read final balances: %w", err)
+		}
+		rep.Consistency = st.checkConsistency(initial, final)
+	}
+
+	if cfg.json {
+		err = printJSON(rep)
+	} else {
+		err = printText(rep)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return rep.Consistency == nil || rep.Consistency.OK, nil
+}
+
+// generate sends requests at the configured rate until the duration is over
+// or ctx is cancelled, waits for the responses and returns how long it took.
+// Requests are sent on schedule whether or not earlier ones have finished, so
+// a slow service shows up as growing latency. A request that would exceed
+// max-in-flight is dropped and counted instead.
+func generate(ctx context.Context, cfg *config, c *client, st *stats) time.Duration {
+	var (
+		wg       sync.WaitGroup
+		inFlight = make(chan struct{}, cfg.maxInFlight)
+		start    = time.Now()
+		next     = start
+		timer    = time.NewTimer(0)
+	)
+	defer timer.Stop()
+
+	for next.Sub(start) < cfg.duration {
+		timer.Reset(time.Until(next))
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return time.Since(start)
+		case <-timer.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+			wg.Add(1)
+			go func(op operation) {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+				st.record(c.updateBalance(ctx, op))
+			}(cfg.nextOperation())
+		default:
+			st.drop()
+		}
+
+		next = next.Add(time.Duration(float64(time.Second) / cfg.rateAt(next.Sub(start))))
+	}
+
+	wg.Wait()
+	return time.Since(start)
+}
+
+func newHTTPClient(cfg *config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.maxInFlight
+	transport.MaxIdleConnsPerHost = cfg.maxInFlight
+	return &http.Client{Transport: transport, Timeout: cfg.timeout}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// stats collects the results of the requests as they complete.
+type stats struct {
+	mu         sync.Mutex
+	latencies  []time.Duration
+	statuses   map[int]int
+	conflicts  int
+	deadlocks  int
+	duplicates int
+	dropped    int
+	// deltas is the expected balance change of each wallet
+	deltas map[string]int
+	// unknown counts the requests of each wallet without a response
+	unknown map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		statuses: make(map[int]int),
+		deltas:   make(map[string]int),
+		unknown:  make(map[string]int),
+	}
+}
+
+func (s *stats) record(res result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies = append(s.latencies, res.latency)
+	s.statuses[res.status]++
+
+	switch res.status {
+	case http.StatusOK:
+		s.deltas[res.op.WalletUUID] += res.op.delta(res.fee)
+	case http.StatusConflict:
+		switch res.code {
+		case types.CodeIdempotencyConflict:
+			s.duplicates++
+		case types.CodeDeadlock, types.CodeSerializationFailure:
+			s.deadlocks++
+		default:
+			s.conflicts++
+		}
+	case 0:
+		s.unknown[res.op.WalletUUID]++
+	}
+}
+
+func (s *stats) drop() {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+}
+
+type latencyReport struct {
+	P50 float64 `json:"p50Ms"`
+	P90 float64 `json:"p90Ms"`
+	P95 float64 `json:"p95Ms"`
+	P99 float64 `json:"p99Ms"`
+	Max float64 `json:"maxMs"`
+}
+
+type walletCheck struct {
+	WalletUUID string `json:"valletId"`
+	Initial    int    `json:"initial"`
+	Expected   int    `json:"expected"`
+	Actual     int    `json:"actual"`
+	// Unknown is how many requests to the wallet got no response, a
+	// mismatch of a wallet with such requests may be explained by them
+	Unknown int  `json:"unknown"`
+	OK      bool `json:"ok"`
+}
+
+type consistencyReport struct {
+	OK      bool          `json:"ok"`
+	Wallets []walletCheck `json:"wallets"`
+}
+
+type report struct {
+	Mode         string             `json:"mode"`
+	Distribution string             `json:"distribution"`
+	Elapsed      time.Duration      `json:"-"`
+	Seconds      float64            `json:"elapsedSeconds"`
+	Completed    int                `json:"completed"`
+	Dropped      int                `json:"dropped"`
+	Throughput   float64            `json:"throughput"`
+	Latency      latencyReport      `json:"latency"`
+	Statuses     map[string]int     `json:"statuses"`
+	ConflictRate float64            `json:"conflictRate"`
+	DeadlockRate float64            `json:"deadlockRate"`
+	Duplicates   int                `json:"duplicates"`
+	Consistency  *consistencyReport `json:"consistency,omitempty"`
+}
+
+func (s *stats) report(cfg *config, elapsed time.Duration) *report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	completed := len(s.latencies)
+	rep := &report{
+		Mode:         cfg.mode,
+		Distribution: cfg.distribution,
+		Elapsed:      elapsed,
+		Seconds:      elapsed.Seconds(),
+		Completed:    completed,
+		Dropped:      s.dropped,
+		Throughput:   float64(completed) / elapsed.Seconds(),
+		Statuses:     make(map[string]int, len(s.statuses)),
+		Duplicates:   s.duplicates,
+	}
+	for status, n := range s.statuses {
+		key := strconv.Itoa(status)
+		if status == 0 {
+			key = "no response"
+		}
+		rep.Statuses[key] = n
+	}
+
+	if completed > 0 {
+		rep.ConflictRate = float64(s.conflicts) / float64(completed)
+		rep.DeadlockRate = float64(s.deadlocks) / float64(completed)
+
+		sorted := slices.Clone(s.latencies)
+		slices.Sort(sorted)
+		rep.Latency = latencyReport{
+			P50: ms(percentile(sorted, 50)),
+			P90: ms(percentile(sorted, 90)),
+			P95: ms(percentile(sorted, 95)),
+			P99: ms(percentile(sorted, 99)),
+			Max: ms(sorted[len(sorted)-1]),
+		}
+	}
+
+	return rep
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// checkConsistency compares the final balances with the initial ones plus
+// the operations that succeeded. It assumes nothing else wrote to the wallets
+// during the test.
+func (s *stats) checkConsistency(initial, final map[string]int) *consistencyReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rep := &consistencyReport{OK: true}
+	for walletUUID, before := range initial {
+		check := walletCheck{
+			WalletUUID: walletUUID,
+			Initial:    before,
+			Expected:   before + s.deltas[walletUUID],
+			Actual:     final[walletUUID],
+			Unknown:    s.unknown[walletUUID],
+		}
+		check.OK = check.Expected == check.Actual
+		rep.OK = rep.OK && check.OK
+		rep.Wallets = append(rep.Wallets, check)
+	}
+	sort.Slice(rep.Wallets, func(i, j int) bool {
+		return rep.Wallets[i].WalletUUID < rep.Wallets[j].WalletUUID
+	})
+
+	return rep
+}
+
+func printJSON(rep *report) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+func printText(rep *report) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "mode\t%s, %s distribution\n", rep.Mode, rep.Distribution)
+	fmt.Fprintf(w, "elapsed\t%s\n", rep.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "completed\t%d (%.1f req/s)\n", rep.Completed, rep.Throughput)
+	fmt.Fprintf(w, "dropped\t%d\n", rep.Dropped)
+	fmt.Fprintf(w, "latency\tp50 %.1fms  p90 %.1fms  p95 %.1fms  p99 %.1fms  max %.1fms\n",
+		rep.Latency.P50, rep.Latency.P90, rep.Latency.P95, rep.Latency.P99, rep.Latency.Max)
+
+	statuses := make([]string, 0, len(rep.Statuses))
+	for status := range rep.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "status %s\t%d\n", status, rep.Statuses[status])
+	}
+
+	fmt.Fprintf(w, "conflict rate\t%.2f%%\n", rep.ConflictRate*100)
+	fmt.Fprintf(w, "deadlock rate\t%.2f%%\n", rep.DeadlockRate*100)
+	fmt.Fprintf(w, "duplicate references\t%d\n", rep.Duplicates)
+
+	if rep.Consistency != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "wallet\tinitial\texpected\tactual\tno response\tcheck")
+		for _, c := range rep.Consistency.Wallets {
+			mark := "ok"
+			if !c.OK {
+				mark = "MISMATCH"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", c.WalletUUID, c.Initial, c.Expected, c.Actual, c.Unknown, mark)
+		}
+		if rep.Consistency.OK {
+			fmt.Fprintln(w, "consistency\tok")
+		} else {
+			fmt.Fprintln(w, "consistency\tFAILED")
+		}
+	}
+
+	return w.Flush()
+}
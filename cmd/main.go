@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,9 +17,16 @@ import (
 
 	"github.com/artyomkorchagin/wallet-task/config"
 	"github.com/artyomkorchagin/wallet-task/internal/infrastructure"
+	"github.com/artyomkorchagin/wallet-task/internal/jwtauth"
 	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/artyomkorchagin/wallet-task/internal/ratelimit"
+	apikeypostgresql "github.com/artyomkorchagin/wallet-task/internal/repository/postgres/apikey"
+	schedulepostgresql "github.com/artyomkorchagin/wallet-task/internal/repository/postgres/schedule"
 	walletpostgresql "github.com/artyomkorchagin/wallet-task/internal/repository/postgres/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
 	"github.com/artyomkorchagin/wallet-task/internal/router"
+	apikeyservice "github.com/artyomkorchagin/wallet-task/internal/services/apikey"
+	scheduleservice "github.com/artyomkorchagin/wallet-task/internal/services/schedule"
 	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -29,14 +39,18 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
 	}
-	var zapLogger *zap.Logger
-
-	if cfg.LogMode == "DEV" {
-		zapLogger, err = logger.NewDevelopmentLogger()
-	} else {
-		zapLogger, err = logger.NewLogger()
+	logOpts := logger.Options{
+		Development:        cfg.LogMode == "DEV",
+		Level:              cfg.Log.Level,
+		Encoding:           cfg.Log.Encoding,
+		SamplingInitial:    cfg.Log.SamplingInitial,
+		SamplingThereafter: cfg.Log.SamplingThereafter,
+		Redact:             cfg.Log.Redact,
 	}
-
+	if cfg.Log.OutputPaths != "" {
+		logOpts.OutputPaths = strings.Split(cfg.Log.OutputPaths, ",")
+	}
+	zapLogger, logLevel, err := logger.New(logOpts)
 	if err != nil {
 		log.Fatal("Failed to initialize logger:", err)
 	}
@@ -55,11 +69,22 @@ func main() {
 	}
 	zapLogger.Info("Connected to database")
 
-	zapLogger.Info("Running migrations")
-	if err := walletpostgresql.RunMigrations(db); err != nil {
-		zapLogger.Fatal("Failed to run up migration", zap.Error(err))
+	if cfg.DB.SkipMigrations {
+		zapLogger.Info("Skipping migrations")
+	} else {
+		zapLogger.Info("Running migrations")
+		if err := walletpostgresql.RunMigrations(context.Background(), db); err != nil {
+			zapLogger.Fatal("Failed to run up migration", zap.Error(err))
+		}
+		zapLogger.Info("Succesfully ran up migration")
+	}
+
+	if cfg.DB.Seed {
+		zapLogger.Info("Seeding test data")
+		if err := walletpostgresql.Seed(context.Background(), db); err != nil {
+			zapLogger.Fatal("Failed to seed test data", zap.Error(err))
+		}
 	}
-	zapLogger.Info("Succesfully ran up migration")
 
 	zapLogger.Info("Connecting to redis")
 	rAddr := cfg.Redis.Host + cfg.Redis.Port
@@ -71,9 +96,55 @@ func main() {
 	zapLogger.Info("Connected to redis")
 
 	walletRepo := walletpostgresql.NewRepository(db)
-	walletSvc := walletservice.NewService(walletRepo, rdb, zapLogger)
+	dbRetry := retry.Policy{
+		MaxAttempts: cfg.Retry.DBMaxAttempts,
+		BaseDelay:   cfg.Retry.DBBaseDelay,
+		MaxDelay:    cfg.Retry.DBMaxDelay,
+		Jitter:      cfg.Retry.Jitter,
+	}
+	walletSvc := walletservice.NewService(walletRepo, rdb, zapLogger,
+		walletservice.WithDBRetry(dbRetry),
+		walletservice.WithCacheRetry(retry.Policy{
+			MaxAttempts: cfg.Retry.RedisMaxAttempts,
+			BaseDelay:   cfg.Retry.RedisBaseDelay,
+			MaxDelay:    cfg.Retry.RedisMaxDelay,
+			Jitter:      cfg.Retry.Jitter,
+		}),
+	)
+
+	apikeyRepo := apikeypostgresql.NewRepository(db)
+	apikeySvc := apikeyservice.NewService(apikeyRepo, zapLogger)
+
+	scheduleRepo := schedulepostgresql.NewRepository(db)
+	scheduleSvc := scheduleservice.NewService(scheduleRepo, zapLogger)
+
+	handlerOpts := []router.Option{
+		router.WithAPIKeys(apikeySvc),
+		router.WithSchedules(scheduleSvc),
+		router.WithLogLevel(logLevel),
+		router.WithTrustedProxies(cfg.Server.TrustedProxyList()),
+	}
+	if cfg.JWT.Enabled() {
+		verifier, err := newJWTVerifier(cfg.JWT)
+		if err != nil {
+			zapLogger.Fatal("Failed to load JWT keys", zap.Error(err))
+		}
+		handlerOpts = append(handlerOpts, router.WithJWT(verifier))
+		zapLogger.Info("JWT auth enabled")
+	}
+
+	if cfg.Limits.Enabled {
+		limiter := ratelimit.NewFallbackLimiter(
+			ratelimit.NewRedisLimiter(rdb),
+			ratelimit.NewMemoryLimiter(),
+			zapLogger)
+		handlerOpts = append(handlerOpts, router.WithRateLimits(limiter,
+			ratelimit.Limit{Rate: cfg.Limits.ClientRPS, Burst: cfg.Limits.ClientBurst},
+			ratelimit.Limit{Rate: cfg.Limits.WalletRPS, Burst: cfg.Limits.WalletBurst}),
+			router.WithIPRateLimit(ratelimit.Limit{Rate: cfg.Limits.IPRPS, Burst: cfg.Limits.IPBurst}))
+	}
 
-	handler := router.NewHandler(walletSvc, zapLogger)
+	handler := router.NewHandler(walletSvc, zapLogger, handlerOpts...)
 	r := handler.InitRouter()
 
 	port := cfg.Server.Port
@@ -82,6 +153,44 @@ func main() {
 		Handler: r,
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	if cfg.Scheduler.Enabled {
+		executor := scheduleservice.NewExecutor(scheduleRepo, walletSvc, zapLogger,
+			scheduleservice.WithInterval(cfg.Scheduler.Interval),
+			scheduleservice.WithBatchSize(cfg.Scheduler.BatchSize),
+			scheduleservice.WithMaxFailures(cfg.Scheduler.MaxFailures),
+			scheduleservice.WithRetryDelay(cfg.Scheduler.RetryDelay))
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			executor.Run(jobsCtx)
+		}()
+	}
+	if cfg.Async.Enabled {
+		worker := walletservice.NewAsyncWorker(walletRepo, zapLogger,
+			walletservice.WithAsyncWorkers(cfg.Async.Workers),
+			walletservice.WithPollInterval(cfg.Async.PollInterval),
+			walletservice.WithAsyncBatchSize(cfg.Async.BatchSize),
+			walletservice.WithLease(cfg.Async.Lease),
+			walletservice.WithMaxAttempts(cfg.Async.MaxAttempts),
+			walletservice.WithAsyncRetry(dbRetry))
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			worker.Run(jobsCtx)
+		}()
+	}
+	if cfg.Snapshots.Enabled {
+		snapshots := walletservice.NewSnapshotJob(walletSvc, zapLogger,
+			walletservice.WithSnapshotDelay(cfg.Snapshots.Delay))
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			snapshots.Run(jobsCtx)
+		}()
+	}
+
 	go func() {
 		zapLogger.Info("Server starting", zap.String("port", port))
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -104,9 +213,41 @@ func main() {
 
 	zapLogger.Info("Server exited")
 
+	stopJobs()
+	jobs.Wait()
+
 	if err := db.Close(); err != nil {
 		zapLogger.Error("Error closing database connection", zap.Error(err))
 	}
 
 	zapLogger.Info("Shutdown completed")
 }
+
+func newJWTVerifier(cfg config.JWTConfig) (*jwtauth.Verifier, error) {
+	keys := make(map[string]crypto.PublicKey)
+
+	if cfg.JWKSFile != "" {
+		jwks, err := jwtauth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range jwks {
+			keys[kid] = key
+		}
+	}
+
+	if cfg.PublicKeyFiles != "" {
+		pemKeys, err := jwtauth.LoadPEMKeys(strings.Split(cfg.PublicKeyFiles, ","))
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range pemKeys {
+			keys[kid] = key
+		}
+	}
+
+	return jwtauth.NewVerifier(keys,
+		jwtauth.WithIssuer(cfg.Issuer),
+		jwtauth.WithAudience(cfg.Audience),
+		jwtauth.WithLeeway(cfg.Leeway))
+}
@@ -0,0 +1,434 @@
+// walletctl is the admin CLI of the wallet service. It talks to the database
+// directly using the same config.env as the server and prints JSON.
+//
+//	walletctl migrate status
+//	walletctl migrate to -version 20261019160000
+//	walletctl wallet create -owner user-1 -credit-limit 1000
+//	walletctl wallet freeze -uuid 5f1c...
+//	walletctl op apply -wallet 5f1c... -type DEPOSIT -amount 100
+//	walletctl reconcile
+//	walletctl sweep-pending -older-than 10m
+//	walletctl apikey create -name billing -scopes wallet:read,wallet:write
+//	walletctl apikey list
+//	walletctl apikey revoke -id 3
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/artyomkorchagin/wallet-task/config"
+	apikeypostgresql "github.com/artyomkorchagin/wallet-task/internal/repository/postgres/apikey"
+	walletpostgresql "github.com/artyomkorchagin/wallet-task/internal/repository/postgres/wallet"
+	apikeyservice "github.com/artyomkorchagin/wallet-task/internal/services/apikey"
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const usage = `usage: walletctl <command> [subcommand] [flags]
+
+commands:
+  migrate up|down|redo|status
+  migrate to -version VERSION
+  seed
+  wallet create [-owner OWNER] [-credit-limit N]
+  wallet show -uuid UUID
+  wallet freeze -uuid UUID [-unfreeze]
+  op apply -wallet UUID -type DEPOSIT|WITHDRAW -amount N [-ref UUID]
+           [-description TEXT] [-external-ref REF] [-meta KEY=VALUE[,KEY=VALUE...]]
+  op show -ref UUID
+  op list -wallet UUID [-external-ref REF] [-meta KEY[=VALUE][,...]] [-limit N] [-before ID]
+  op failures [-since DURATION]
+  reconcile
+  sweep-pending [-older-than DURATION]
+  apikey create -name NAME -scopes SCOPE[,SCOPE...]
+  apikey list
+  apikey revoke -id ID
+`
+
+type app struct {
+	db      *sql.DB
+	wallets *walletservice.Service
+	apikeys *apikeyservice.Service
+}
+
+// commands without subcommands
+var singleCommands = map[string]bool{
+	"seed":          true,
+	"reconcile":     true,
+	"sweep-pending": true,
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "walletctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 || (len(args) < 2 && !singleCommands[args[0]]) {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("not enough arguments")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := sql.Open("pgx", cfg.GetDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	// Команды CLI не читают закешированные балансы, поэтому клиент
+	// не проверяет соединение с redis при старте
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Host + cfg.Redis.Port,
+		Password: cfg.Redis.Password,
+	})
+	defer rdb.Close()
+
+	a := &app{
+		db:      db,
+		wallets: walletservice.NewService(walletpostgresql.NewRepository(db), rdb, zap.NewNop()),
+		apikeys: apikeyservice.NewService(apikeypostgresql.NewRepository(db), zap.NewNop()),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if singleCommands[args[0]] {
+		switch args[0] {
+		case "seed":
+			return a.seed(ctx, args[1:])
+		case "reconcile":
+			return a.reconcile(ctx, args[1:])
+		case "sweep-pending":
+			return a.sweepPending(ctx, args[1:])
+		}
+	}
+
+	switch args[0] + " " + args[1] {
+	case "migrate up", "migrate down", "migrate redo", "migrate to", "migrate status":
+		return a.migrate(ctx, args[1], args[2:])
+	case "wallet create":
+		return a.walletCreate(ctx, args[2:])
+	case "wallet show":
+		return a.walletShow(ctx, args[2:])
+	case "wallet freeze":
+		return a.walletFreeze(ctx, args[2:])
+	case "op apply":
+		return a.opApply(ctx, args[2:])
+	case "op show":
+		return a.opShow(ctx, args[2:])
+	case "op list":
+		return a.opList(ctx, args[2:])
+	case "op failures":
+		return a.opFailures(ctx, args[2:])
+	case "apikey create":
+		return a.apikeyCreate(ctx, args[2:])
+	case "apikey list":
+		return a.apikeyList(ctx, args[2:])
+	case "apikey revoke":
+		return a.apikeyRevoke(ctx, args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", strings.Join(args[:2], " "))
+	}
+}
+
+func (a *app) apikeyCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the key owner")
+	scopes := fs.String("scopes", "", "comma separated scopes: wallet:read, wallet:write, admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, rawKey, err := a.apikeys.CreateAPIKey(ctx, *name, splitList(*scopes))
+	if err != nil {
+		return err
+	}
+
+	// Ключ показывается только один раз, в базе хранится лишь его хеш
+	return printJSON(struct {
+		*apikeyOutput
+		Key string `json:"key"`
+	}{newAPIKeyOutput(key), rawKey})
+}
+
+func (a *app) apikeyList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikey list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	keys, err := a.apikeys.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([]*apikeyOutput, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, newAPIKeyOutput(key))
+	}
+	return printJSON(out)
+}
+
+func (a *app) apikeyRevoke(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apikey revoke", flag.ContinueOnError)
+	id := fs.Int64("id", 0, "id of the key to revoke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	if err := a.apikeys.RevokeAPIKey(ctx, *id); err != nil {
+		return err
+	}
+	return printJSON(map[string]any{"id": *id, "revoked": true})
+}
+
+func (a *app) migrate(ctx context.Context, command string, args []string) error {
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	version := fs.Int64("version", -1, "version to migrate to, 0 rolls back everything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := walletpostgresql.NewMigrator(a.db)
+	if err != nil {
+		return err
+	}
+
+	var results []walletpostgresql.MigrationResult
+	switch command {
+	case "up":
+		results, err = m.Up(ctx)
+	case "down":
+		results, err = m.Down(ctx)
+	case "redo":
+		results, err = m.Redo(ctx)
+	case "to":
+		if *version < 0 {
+			return fmt.Errorf("-version is required")
+		}
+		results, err = m.To(ctx, *version)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printJSON(statuses)
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(results)
+}
+
+func (a *app) seed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := walletpostgresql.Seed(ctx, a.db); err != nil {
+		return err
+	}
+	return printJSON(map[string]any{"seeded": true})
+}
+
+func (a *app) walletCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wallet create", flag.ContinueOnError)
+	owner := fs.String("owner", "", "owner of the wallet, empty for an unowned wallet")
+	creditLimit := fs.Int("credit-limit", 0, "how far below zero the balance may go")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wallet, err := a.wallets.CreateWallet(ctx, *owner, *creditLimit)
+	if err != nil {
+		return err
+	}
+	return printJSON(wallet)
+}
+
+func (a *app) walletShow(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wallet show", flag.ContinueOnError)
+	walletUUID := fs.String("uuid", "", "wallet UUID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *walletUUID == "" {
+		return fmt.Errorf("-uuid is required")
+	}
+
+	wallet, err := a.wallets.GetWallet(ctx, *walletUUID)
+	if err != nil {
+		return err
+	}
+	return printJSON(wallet)
+}
+
+func (a *app) walletFreeze(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wallet freeze", flag.ContinueOnError)
+	walletUUID := fs.String("uuid", "", "wallet UUID")
+	unfreeze := fs.Bool("unfreeze", false, "unfreeze the wallet instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *walletUUID == "" {
+		return fmt.Errorf("-uuid is required")
+	}
+
+	wallet, err := a.wallets.SetWalletFrozen(ctx, *walletUUID, !*unfreeze)
+	if err != nil {
+		return err
+	}
+	return printJSON(wallet)
+}
+
+func (a *app) opApply(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("op apply", flag.ContinueOnError)
+	walletUUID := fs.String("wallet", "", "wallet UUID")
+	operation := fs.String("type", "", "DEPOSIT or WITHDRAW")
+	amount := fs.Int("amount", 0, "amount of the operation")
+	referenceID := fs.String("ref", "", "reference ID, generated when empty")
+	description := fs.String("description", "", "description of the operation")
+	externalRef := fs.String("external-ref", "", "reference in an external system")
+	meta := fs.String("meta", "", "comma separated KEY=VALUE metadata")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *referenceID == "" {
+		*referenceID = uuid.NewString()
+	}
+
+	req := types.NewWalletUpdateRequest(*walletUUID, strings.ToUpper(*operation), *amount, *referenceID)
+	req.Description = *description
+	req.ExternalRef = *externalRef
+	req.Metadata = splitMetadata(*meta)
+	fee, err := a.wallets.UpdateBalance(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	op, err := a.wallets.GetOperation(ctx, *referenceID)
+	if err != nil {
+		return err
+	}
+	return printJSON(struct {
+		*types.Operation
+		Fee types.Fee `json:"fee"`
+	}{op, fee})
+}
+
+func (a *app) opShow(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("op show", flag.ContinueOnError)
+	referenceID := fs.String("ref", "", "reference ID of the operation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *referenceID == "" {
+		return fmt.Errorf("-ref is required")
+	}
+
+	op, err := a.wallets.GetOperation(ctx, *referenceID)
+	if err != nil {
+		return err
+	}
+	return printJSON(op)
+}
+
+func (a *app) opList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("op list", flag.ContinueOnError)
+	walletUUID := fs.String("wallet", "", "wallet UUID")
+	externalRef := fs.String("external-ref", "", "only operations with this external reference")
+	meta := fs.String("meta", "", "comma separated KEY=VALUE metadata to match, a bare KEY only has to be present")
+	limit := fs.Int("limit", types.DefaultOperationsPage, "maximum number of operations")
+	before := fs.Int64("before", 0, "only operations older than this ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *walletUUID == "" {
+		return fmt.Errorf("-wallet is required")
+	}
+
+	page, err := a.wallets.ListOperations(ctx, *walletUUID, types.OperationFilter{
+		ExternalRef: *externalRef,
+		Metadata:    splitMetadata(*meta),
+		Limit:       *limit,
+		BeforeID:    *before,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(page)
+}
+
+func (a *app) opFailures(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("op failures", flag.ContinueOnError)
+	since := fs.Duration("since", 24*time.Hour, "count async operations failed within this period")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := a.wallets.FailureReport(ctx, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+func (a *app) reconcile(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mismatches, err := a.wallets.ReconcileBalances(ctx)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(mismatches); err != nil {
+		return err
+	}
+
+	// Ненулевой код выхода, чтобы расхождения было видно из cron
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%d wallets are out of sync", len(mismatches))
+	}
+	return nil
+}
+
+func (a *app) sweepPending(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("sweep-pending", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", 10*time.Minute, "fail operations pending for longer than this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := a.wallets.SweepPendingOperations(ctx, *olderThan)
+	if err != nil {
+		return err
+	}
+	return printJSON(map[string]any{"failed": n})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+type apikeyOutput struct {
+	*types.APIKey
+	Revoked bool `json:"revoked"`
+}
+
+func newAPIKeyOutput(key *types.APIKey) *apikeyOutput {
+	return &apikeyOutput{APIKey: key, Revoked: key.Revoked()}
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitMetadata parses comma separated KEY=VALUE pairs, a KEY without '=' gets
+// an empty value.
+func splitMetadata(s string) map[string]string {
+	items := splitList(s)
+	if len(items) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(items))
+	for _, item := range items {
+		key, value, _ := strings.Cut(item, "=")
+		metadata[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return metadata
+}
@@ -2,15 +2,25 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DB      DBConfig     `mapstructure:",squash"`
-	Server  ServerConfig `mapstructure:",squash"`
-	Redis   RedisConfig  `mapstructure:",squash"`
-	LogMode string       `mapstructure:"LOG_MODE"`
+	DB        DBConfig        `mapstructure:",squash"`
+	Server    ServerConfig    `mapstructure:",squash"`
+	Redis     RedisConfig     `mapstructure:",squash"`
+	Retry     RetryConfig     `mapstructure:",squash"`
+	JWT       JWTConfig       `mapstructure:",squash"`
+	Limits    RateLimitConfig `mapstructure:",squash"`
+	Scheduler SchedulerConfig `mapstructure:",squash"`
+	Snapshots SnapshotConfig  `mapstructure:",squash"`
+	Async     AsyncConfig     `mapstructure:",squash"`
+	Log       LogConfig       `mapstructure:",squash"`
+	LogMode   string          `mapstructure:"LOG_MODE"`
 }
 
 type DBConfig struct {
@@ -20,11 +30,19 @@ type DBConfig struct {
 	Password string `mapstructure:"DB_PASSWORD"`
 	Name     string `mapstructure:"DB_NAME"`
 	SSLMode  string `mapstructure:"DB_SSLMODE"`
+
+	// SkipMigrations leaves migrations to `walletctl migrate`.
+	SkipMigrations bool `mapstructure:"DB_SKIP_MIGRATIONS"`
+	// Seed loads the development test wallets at startup.
+	Seed bool `mapstructure:"DB_SEED"`
 }
 
 type ServerConfig struct {
 	Host string `mapstructure:"SERVER_HOST"`
 	Port string `mapstructure:"SERVER_PORT"`
+	// TrustedProxies are comma-separated IPs or CIDRs of the proxies allowed
+	// to pass the client IP in X-Forwarded-For. Empty trusts no proxy.
+	TrustedProxies string `mapstructure:"SERVER_TRUSTED_PROXIES"`
 }
 
 type RedisConfig struct {
@@ -33,6 +51,97 @@ type RedisConfig struct {
 	Password string `mapstructure:"REDIS_PASSWORD"`
 }
 
+type RetryConfig struct {
+	DBMaxAttempts    int           `mapstructure:"RETRY_DB_MAX_ATTEMPTS"`
+	DBBaseDelay      time.Duration `mapstructure:"RETRY_DB_BASE_DELAY"`
+	DBMaxDelay       time.Duration `mapstructure:"RETRY_DB_MAX_DELAY"`
+	RedisMaxAttempts int           `mapstructure:"RETRY_REDIS_MAX_ATTEMPTS"`
+	RedisBaseDelay   time.Duration `mapstructure:"RETRY_REDIS_BASE_DELAY"`
+	RedisMaxDelay    time.Duration `mapstructure:"RETRY_REDIS_MAX_DELAY"`
+	Jitter           float64       `mapstructure:"RETRY_JITTER"`
+}
+
+// JWTConfig configures verification of end-user tokens. JWT auth is enabled
+// when a JWKS file or at least one PEM public key file is set.
+type JWTConfig struct {
+	JWKSFile       string        `mapstructure:"JWT_JWKS_FILE"`
+	PublicKeyFiles string        `mapstructure:"JWT_PUBLIC_KEY_FILES"`
+	Issuer         string        `mapstructure:"JWT_ISSUER"`
+	Audience       string        `mapstructure:"JWT_AUDIENCE"`
+	Leeway         time.Duration `mapstructure:"JWT_LEEWAY"`
+}
+
+// TrustedProxyList splits TrustedProxies.
+func (c ServerConfig) TrustedProxyList() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+func (c JWTConfig) Enabled() bool {
+	return c.JWKSFile != "" || c.PublicKeyFiles != ""
+}
+
+// RateLimitConfig sets token buckets per client IP, per client and per
+// wallet. A zero RPS disables the corresponding limit.
+type RateLimitConfig struct {
+	Enabled     bool    `mapstructure:"RATE_LIMIT_ENABLED"`
+	IPRPS       float64 `mapstructure:"RATE_LIMIT_IP_RPS"`
+	IPBurst     int     `mapstructure:"RATE_LIMIT_IP_BURST"`
+	ClientRPS   float64 `mapstructure:"RATE_LIMIT_CLIENT_RPS"`
+	ClientBurst int     `mapstructure:"RATE_LIMIT_CLIENT_BURST"`
+	WalletRPS   float64 `mapstructure:"RATE_LIMIT_WALLET_RPS"`
+	WalletBurst int     `mapstructure:"RATE_LIMIT_WALLET_BURST"`
+}
+
+// SchedulerConfig configures the executor of scheduled operations. The
+// schedule endpoints are available even when the executor is disabled, e.g.
+// when it runs in another instance.
+type SchedulerConfig struct {
+	Enabled     bool          `mapstructure:"SCHEDULER_ENABLED"`
+	Interval    time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	BatchSize   int           `mapstructure:"SCHEDULER_BATCH_SIZE"`
+	MaxFailures int           `mapstructure:"SCHEDULER_MAX_FAILURES"`
+	RetryDelay  time.Duration `mapstructure:"SCHEDULER_RETRY_DELAY"`
+}
+
+// SnapshotConfig configures the daily job that stores end-of-day balances.
+type SnapshotConfig struct {
+	Enabled bool          `mapstructure:"SNAPSHOT_ENABLED"`
+	Delay   time.Duration `mapstructure:"SNAPSHOT_DELAY"`
+}
+
+// AsyncConfig configures the worker pool that applies operations queued with
+// POST /api/v1/wallet?async=true. Operations are still queued when the workers
+// are disabled, e.g. when they run in another instance.
+type AsyncConfig struct {
+	Enabled      bool          `mapstructure:"ASYNC_ENABLED"`
+	Workers      int           `mapstructure:"ASYNC_WORKERS"`
+	PollInterval time.Duration `mapstructure:"ASYNC_POLL_INTERVAL"`
+	BatchSize    int           `mapstructure:"ASYNC_BATCH_SIZE"`
+	Lease        time.Duration `mapstructure:"ASYNC_LEASE"`
+	MaxAttempts  int           `mapstructure:"ASYNC_MAX_ATTEMPTS"`
+}
+
+// LogConfig configures the logger. Empty level and encoding take the defaults
+// of LOG_MODE; the level can also be changed at runtime by an admin.
+type LogConfig struct {
+	Level              string `mapstructure:"LOG_LEVEL"`
+	Encoding           string `mapstructure:"LOG_ENCODING"`
+	OutputPaths        string `mapstructure:"LOG_OUTPUT_PATHS"`
+	SamplingInitial    int    `mapstructure:"LOG_SAMPLING_INITIAL"`
+	SamplingThereafter int    `mapstructure:"LOG_SAMPLING_THEREAFTER"`
+	Redact             bool   `mapstructure:"LOG_REDACT"`
+}
+
+var logLevels = map[string]bool{
+	"debug": true, "info": true, "warn": true, "error": true, "dpanic": true, "panic": true, "fatal": true,
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("env")
@@ -43,6 +152,48 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	viper.SetDefault("DB_SKIP_MIGRATIONS", false)
+	viper.SetDefault("DB_SEED", false)
+	viper.SetDefault("RETRY_DB_MAX_ATTEMPTS", 3)
+	viper.SetDefault("RETRY_DB_BASE_DELAY", 10*time.Millisecond)
+	viper.SetDefault("RETRY_DB_MAX_DELAY", 200*time.Millisecond)
+	viper.SetDefault("RETRY_REDIS_MAX_ATTEMPTS", 2)
+	viper.SetDefault("RETRY_REDIS_BASE_DELAY", 5*time.Millisecond)
+	viper.SetDefault("RETRY_REDIS_MAX_DELAY", 50*time.Millisecond)
+	viper.SetDefault("RETRY_JITTER", 0.5)
+	viper.SetDefault("JWT_JWKS_FILE", "")
+	viper.SetDefault("JWT_PUBLIC_KEY_FILES", "")
+	viper.SetDefault("JWT_ISSUER", "")
+	viper.SetDefault("JWT_AUDIENCE", "")
+	viper.SetDefault("JWT_LEEWAY", 30*time.Second)
+	viper.SetDefault("SERVER_TRUSTED_PROXIES", "")
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_IP_RPS", 200)
+	viper.SetDefault("RATE_LIMIT_IP_BURST", 400)
+	viper.SetDefault("RATE_LIMIT_CLIENT_RPS", 100)
+	viper.SetDefault("RATE_LIMIT_CLIENT_BURST", 200)
+	viper.SetDefault("RATE_LIMIT_WALLET_RPS", 20)
+	viper.SetDefault("RATE_LIMIT_WALLET_BURST", 40)
+	viper.SetDefault("SCHEDULER_ENABLED", true)
+	viper.SetDefault("SCHEDULER_INTERVAL", 10*time.Second)
+	viper.SetDefault("SCHEDULER_BATCH_SIZE", 100)
+	viper.SetDefault("SCHEDULER_MAX_FAILURES", 3)
+	viper.SetDefault("SCHEDULER_RETRY_DELAY", time.Hour)
+	viper.SetDefault("SNAPSHOT_ENABLED", true)
+	viper.SetDefault("SNAPSHOT_DELAY", 5*time.Minute)
+	viper.SetDefault("ASYNC_ENABLED", true)
+	viper.SetDefault("ASYNC_WORKERS", 8)
+	viper.SetDefault("ASYNC_POLL_INTERVAL", 200*time.Millisecond)
+	viper.SetDefault("ASYNC_BATCH_SIZE", 100)
+	viper.SetDefault("ASYNC_LEASE", 30*time.Second)
+	viper.SetDefault("ASYNC_MAX_ATTEMPTS", 5)
+	viper.SetDefault("LOG_LEVEL", "")
+	viper.SetDefault("LOG_ENCODING", "")
+	viper.SetDefault("LOG_OUTPUT_PATHS", "")
+	viper.SetDefault("LOG_SAMPLING_INITIAL", 100)
+	viper.SetDefault("LOG_SAMPLING_THEREAFTER", 100)
+	viper.SetDefault("LOG_REDACT", true)
+
 	viper.AutomaticEnv()
 
 	var cfg Config
@@ -89,5 +240,63 @@ func (cfg *Config) Validate() error {
 	if cfg.Server.Port == "" {
 		return fmt.Errorf("SERVER_PORT is required")
 	}
+	for _, proxy := range cfg.Server.TrustedProxyList() {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("SERVER_TRUSTED_PROXIES must be IPs or CIDRs, got %q", proxy)
+			}
+		}
+	}
+	if cfg.Retry.DBMaxAttempts < 0 || cfg.Retry.RedisMaxAttempts < 0 {
+		return fmt.Errorf("RETRY_*_MAX_ATTEMPTS must not be negative")
+	}
+	if cfg.Retry.DBMaxDelay < cfg.Retry.DBBaseDelay {
+		return fmt.Errorf("RETRY_DB_MAX_DELAY must not be less than RETRY_DB_BASE_DELAY")
+	}
+	if cfg.Retry.RedisMaxDelay < cfg.Retry.RedisBaseDelay {
+		return fmt.Errorf("RETRY_REDIS_MAX_DELAY must not be less than RETRY_REDIS_BASE_DELAY")
+	}
+	if cfg.Retry.Jitter < 0 || cfg.Retry.Jitter > 1 {
+		return fmt.Errorf("RETRY_JITTER must be between 0 and 1")
+	}
+	if cfg.JWT.Leeway < 0 {
+		return fmt.Errorf("JWT_LEEWAY must not be negative")
+	}
+	if cfg.Limits.IPRPS < 0 || cfg.Limits.ClientRPS < 0 || cfg.Limits.WalletRPS < 0 {
+		return fmt.Errorf("RATE_LIMIT_*_RPS must not be negative")
+	}
+	if (cfg.Limits.IPRPS > 0 && cfg.Limits.IPBurst <= 0) ||
+		(cfg.Limits.ClientRPS > 0 && cfg.Limits.ClientBurst <= 0) ||
+		(cfg.Limits.WalletRPS > 0 && cfg.Limits.WalletBurst <= 0) {
+		return fmt.Errorf("RATE_LIMIT_*_BURST must be positive when the limit is set")
+	}
+	if cfg.Scheduler.Enabled {
+		if cfg.Scheduler.Interval <= 0 || cfg.Scheduler.RetryDelay <= 0 {
+			return fmt.Errorf("SCHEDULER_INTERVAL and SCHEDULER_RETRY_DELAY must be positive")
+		}
+		if cfg.Scheduler.BatchSize <= 0 || cfg.Scheduler.MaxFailures <= 0 {
+			return fmt.Errorf("SCHEDULER_BATCH_SIZE and SCHEDULER_MAX_FAILURES must be positive")
+		}
+	}
+	if cfg.Snapshots.Delay < 0 || cfg.Snapshots.Delay >= 24*time.Hour {
+		return fmt.Errorf("SNAPSHOT_DELAY must be between 0 and 24h")
+	}
+	if cfg.Async.Enabled {
+		if cfg.Async.PollInterval <= 0 || cfg.Async.Lease <= 0 {
+			return fmt.Errorf("ASYNC_POLL_INTERVAL and ASYNC_LEASE must be positive")
+		}
+		if cfg.Async.Workers <= 0 || cfg.Async.BatchSize <= 0 || cfg.Async.MaxAttempts <= 0 {
+			return fmt.Errorf("ASYNC_WORKERS, ASYNC_BATCH_SIZE and ASYNC_MAX_ATTEMPTS must be positive")
+		}
+	}
+	if cfg.Log.Level != "" && !logLevels[cfg.Log.Level] {
+		return fmt.Errorf("LOG_LEVEL must be one of debug, info, warn, error, dpanic, panic, fatal")
+	}
+	if cfg.Log.Encoding != "" && cfg.Log.Encoding != "json" && cfg.Log.Encoding != "console" {
+		return fmt.Errorf("LOG_ENCODING must be json or console")
+	}
+	if cfg.Log.SamplingInitial < 0 || (cfg.Log.SamplingInitial > 0 && cfg.Log.SamplingThereafter <= 0) {
+		return fmt.Errorf("LOG_SAMPLING_THEREAFTER must be positive when LOG_SAMPLING_INITIAL is set")
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -139,6 +140,220 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "SERVER_PORT is required",
 		},
+		{
+			name: "retry max delay less than base delay",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Retry: RetryConfig{
+					DBMaxAttempts: 3,
+					DBBaseDelay:   time.Second,
+					DBMaxDelay:    time.Millisecond,
+				},
+			},
+			wantErr: true,
+			errMsg:  "RETRY_DB_MAX_DELAY must not be less than RETRY_DB_BASE_DELAY",
+		},
+		{
+			name: "retry jitter out of range",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Retry: RetryConfig{
+					Jitter: 1.5,
+				},
+			},
+			wantErr: true,
+			errMsg:  "RETRY_JITTER must be between 0 and 1",
+		},
+		{
+			name: "negative jwt leeway",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				JWT: JWTConfig{
+					Leeway: -time.Second,
+				},
+			},
+			wantErr: true,
+			errMsg:  "JWT_LEEWAY must not be negative",
+		},
+		{
+			name: "rate limit without burst",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Limits: RateLimitConfig{
+					WalletRPS: 10,
+				},
+			},
+			wantErr: true,
+			errMsg:  "RATE_LIMIT_*_BURST must be positive when the limit is set",
+		},
+		{
+			name: "invalid trusted proxy",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port:           "8080",
+					TrustedProxies: "10.0.0.0/8, proxy.local",
+				},
+			},
+			wantErr: true,
+			errMsg:  `SERVER_TRUSTED_PROXIES must be IPs or CIDRs, got "proxy.local"`,
+		},
+		{
+			name: "scheduler without interval",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Scheduler: SchedulerConfig{
+					Enabled:     true,
+					BatchSize:   100,
+					MaxFailures: 3,
+					RetryDelay:  time.Hour,
+				},
+			},
+			wantErr: true,
+			errMsg:  "SCHEDULER_INTERVAL and SCHEDULER_RETRY_DELAY must be positive",
+		},
+		{
+			name: "async workers without lease",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Async: AsyncConfig{
+					Enabled:      true,
+					Workers:      8,
+					PollInterval: time.Second,
+					BatchSize:    100,
+					MaxAttempts:  5,
+				},
+			},
+			wantErr: true,
+			errMsg:  "ASYNC_POLL_INTERVAL and ASYNC_LEASE must be positive",
+		},
+		{
+			name: "snapshot delay of a day",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Snapshots: SnapshotConfig{
+					Enabled: true,
+					Delay:   24 * time.Hour,
+				},
+			},
+			wantErr: true,
+			errMsg:  "SNAPSHOT_DELAY must be between 0 and 24h",
+		},
+		{
+			name: "unknown log level",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Log: LogConfig{Level: "verbose"},
+			},
+			wantErr: true,
+			errMsg:  "LOG_LEVEL must be one of debug, info, warn, error, dpanic, panic, fatal",
+		},
+		{
+			name: "sampling without thereafter",
+			cfg: Config{
+				DB: DBConfig{
+					Host:     "localhost",
+					Port:     5432,
+					User:     "user",
+					Password: "pass",
+					Name:     "testdb",
+					SSLMode:  "disable",
+				},
+				Server: ServerConfig{
+					Port: "8080",
+				},
+				Log: LogConfig{SamplingInitial: 100},
+			},
+			wantErr: true,
+			errMsg:  "LOG_SAMPLING_THEREAFTER must be positive when LOG_SAMPLING_INITIAL is set",
+		},
 	}
 
 	for _, tt := range tests {
@@ -291,6 +506,33 @@ func TestLoadConfig(t *testing.T) {
 			validate: func(cfg *Config, t *testing.T) {
 				assert.Equal(t, "localhost", cfg.DB.Host)
 				assert.Equal(t, "8080", cfg.Server.Port)
+				assert.Equal(t, 3, cfg.Retry.DBMaxAttempts)
+				assert.Equal(t, 10*time.Millisecond, cfg.Retry.DBBaseDelay)
+				assert.Equal(t, 30*time.Second, cfg.JWT.Leeway)
+				assert.False(t, cfg.JWT.Enabled())
+				assert.True(t, cfg.Limits.Enabled)
+				assert.Equal(t, 20.0, cfg.Limits.WalletRPS)
+				assert.Equal(t, 40, cfg.Limits.WalletBurst)
+				assert.Equal(t, 200.0, cfg.Limits.IPRPS)
+				assert.Equal(t, 400, cfg.Limits.IPBurst)
+				assert.Empty(t, cfg.Server.TrustedProxyList())
+				assert.True(t, cfg.Scheduler.Enabled)
+				assert.Equal(t, 10*time.Second, cfg.Scheduler.Interval)
+				assert.Equal(t, 3, cfg.Scheduler.MaxFailures)
+				assert.Equal(t, time.Hour, cfg.Scheduler.RetryDelay)
+				assert.True(t, cfg.Snapshots.Enabled)
+				assert.Equal(t, 5*time.Minute, cfg.Snapshots.Delay)
+				assert.True(t, cfg.Async.Enabled)
+				assert.Equal(t, 8, cfg.Async.Workers)
+				assert.Equal(t, 200*time.Millisecond, cfg.Async.PollInterval)
+				assert.Equal(t, 30*time.Second, cfg.Async.Lease)
+				assert.Equal(t, 5, cfg.Async.MaxAttempts)
+				assert.Empty(t, cfg.Log.Level)
+				assert.Equal(t, 100, cfg.Log.SamplingInitial)
+				assert.Equal(t, 100, cfg.Log.SamplingThereafter)
+				assert.True(t, cfg.Log.Redact)
+				assert.False(t, cfg.DB.SkipMigrations)
+				assert.False(t, cfg.DB.Seed)
 			},
 		},
 	}
@@ -0,0 +1,108 @@
+package jwtauth
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms accepted by the verifier. Symmetric algorithms are not
+// supported: the service must never be able to issue user tokens itself.
+var validMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+var ErrNoKeys = errors.New("no verification keys configured")
+
+// Verifier checks end-user tokens signed with RS256 or ES256 and returns the
+// subject they were issued for.
+type Verifier struct {
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+type Option func(*Verifier)
+
+// WithIssuer requires the iss claim to be equal to issuer.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain audience.
+func WithAudience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway allows for clock skew when checking exp, nbf and iat.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// NewVerifier creates a verifier for keys indexed by key ID. A token with a
+// kid header is checked only against the key with that ID, a token without
+// one against every key.
+func NewVerifier(keys map[string]crypto.PublicKey, opts ...Option) (*Verifier, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	v := &Verifier{keys: keys}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v, nil
+}
+
+// Verify parses and validates a token. The returned error wraps
+// types.ErrTokenInvalid.
+func (v *Verifier) Verify(rawToken string) (string, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(rawToken, &jwt.RegisteredClaims{}, v.keyFunc, opts...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", types.ErrTokenInvalid, err)
+	}
+
+	subject, err := token.Claims.GetSubject()
+	if err != nil || subject == "" {
+		return "", fmt.Errorf("%w: subject is missing", types.ErrTokenInvalid)
+	}
+
+	return subject, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}
+
+	set := jwt.VerificationKeySet{Keys: make([]jwt.VerificationKey, 0, len(v.keys))}
+	for _, key := range v.keys {
+		set.Keys = append(set.Keys, key)
+	}
+	return set, nil
+}
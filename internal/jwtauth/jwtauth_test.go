@@ -0,0 +1,151 @@
+package jwtauth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/jwtauth"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key crypto.PrivateKey, kid string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+	return raw
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user-1",
+		Issuer:    "https://auth.example.com",
+		Audience:  jwt.ClaimStrings{"wallet"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	verifier, err := jwtauth.NewVerifier(map[string]crypto.PublicKey{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	},
+		jwtauth.WithIssuer("https://auth.example.com"),
+		jwtauth.WithAudience("wallet"),
+	)
+	require.NoError(t, err)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"other"}
+
+	noSubject := validClaims()
+	noSubject.Subject = ""
+
+	tests := []struct {
+		name    string
+		token   string
+		subject string
+		wantErr bool
+	}{
+		{
+			name:    "RS256 with kid",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", validClaims()),
+			subject: "user-1",
+		},
+		{
+			name:    "ES256 without kid",
+			token:   sign(t, jwt.SigningMethodES256, ecKey, "", validClaims()),
+			subject: "user-1",
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "missing", validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "signed by unknown key",
+			token:   sign(t, jwt.SigningMethodRS256, otherKey, "", validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "HS256 is rejected",
+			token:   sign(t, jwt.SigningMethodHS256, []byte("secret"), "", validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", expired),
+			wantErr: true,
+		},
+		{
+			name:    "no expiry",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", noExpiry),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", wrongIssuer),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", wrongAudience),
+			wantErr: true,
+		},
+		{
+			name:    "no subject",
+			token:   sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", noSubject),
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, types.ErrTokenInvalid)
+				assert.Empty(t, subject)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.subject, subject)
+		})
+	}
+}
+
+func TestNewVerifier_NoKeys(t *testing.T) {
+	_, err := jwtauth.NewVerifier(nil)
+	assert.ErrorIs(t, err, jwtauth.ErrNoKeys)
+}
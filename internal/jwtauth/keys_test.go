@@ -0,0 +1,100 @@
+package jwtauth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "r1", "use": "sig", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "e1", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": %q, "e": %q}
+	]}`,
+		b64(rsaKey.N), b64(big.NewInt(int64(rsaKey.E))),
+		b64(ecKey.X), b64(ecKey.Y),
+		b64(rsaKey.N), b64(big.NewInt(int64(rsaKey.E))))
+
+	keys, err := jwtauth.ParseJWKS([]byte(jwks))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["r1"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["e1"]))
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		_, err := jwtauth.ParseJWKS([]byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("point not on curve", func(t *testing.T) {
+		_, err := jwtauth.ParseJWKS([]byte(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := jwtauth.ParseJWKS([]byte(`{`))
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadPEMKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	write := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+		return path
+	}
+
+	ecDER, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	paths := []string{
+		write("mobile-rsa.pem", &pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}),
+		write("mobile-ec.pem", &pem.Block{Type: "PUBLIC KEY", Bytes: ecDER}),
+	}
+
+	keys, err := jwtauth.LoadPEMKeys(paths)
+	require.NoError(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["mobile-rsa"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["mobile-ec"]))
+
+	t.Run("private key is rejected", func(t *testing.T) {
+		path := write("private.pem", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+		_, err := jwtauth.LoadPEMKeys([]string{path})
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := jwtauth.LoadPEMKeys([]string{filepath.Join(dir, "missing.pem")})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// ContextWith returns ctx carrying l, so that code serving the request logs
+// with the request's fields: its ID, route and client.
+func ContextWith(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored by ContextWith, or fallback when ctx
+// does not carry one, as for background jobs and the CLI.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok && l != nil {
+		return l
+	}
+	return fallback
+}
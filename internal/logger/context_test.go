@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	fallback := zap.NewNop()
+
+	t.Run("without logger", func(t *testing.T) {
+		assert.Same(t, fallback, FromContext(context.Background(), fallback))
+	})
+
+	t.Run("with logger", func(t *testing.T) {
+		core, logs := observer.New(zap.InfoLevel)
+		requestLogger := zap.New(core).With(zap.String("request_id", "req-1"))
+		ctx := ContextWith(context.Background(), requestLogger)
+
+		FromContext(ctx, fallback).Info("handled")
+
+		entries := logs.All()
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+		}
+	})
+}
@@ -1,21 +1,92 @@
 package logger
 
 import (
+	"fmt"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-func NewLogger() (*zap.Logger, error) {
+// Options configure the logger built by New.
+type Options struct {
+	// Development selects the human-readable development defaults: console
+	// encoding, colored levels, debug level and stack traces on warnings.
+	Development bool
+	// Level is the initial level, the default of the mode when empty.
+	Level string
+	// Encoding is json or console, the default of the mode when empty.
+	Encoding string
+	// OutputPaths are zap sink URLs or files, stderr when empty.
+	OutputPaths []string
+	// SamplingInitial entries with the same level and message are logged
+	// every second, then only every SamplingThereafter-th one. Zero disables
+	// sampling.
+	SamplingInitial    int
+	SamplingThereafter int
+	// Redact hides secrets and masks wallet IDs, see redactingCore.
+	Redact bool
+}
+
+// New builds a logger from opts. The returned level changes the level of the
+// logger at runtime.
+func New(opts Options) (*zap.Logger, zap.AtomicLevel, error) {
 	config := zap.NewProductionConfig()
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	if opts.Development {
+		config = zap.NewDevelopmentConfig()
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	if opts.Level != "" {
+		level, err := zap.ParseAtomicLevel(opts.Level)
+		if err != nil {
+			return nil, zap.AtomicLevel{}, fmt.Errorf("invalid log level: %w", err)
+		}
+		config.Level = level
+	}
+	switch opts.Encoding {
+	case "":
+	case "json", "console":
+		config.Encoding = opts.Encoding
+	default:
+		return nil, zap.AtomicLevel{}, fmt.Errorf("unknown log encoding %q", opts.Encoding)
+	}
+	if opts.Encoding == "json" {
+		// Цветные уровни ломают JSON
+		config.EncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	}
+	if len(opts.OutputPaths) > 0 {
+		config.OutputPaths = opts.OutputPaths
+	}
 
-	return config.Build()
+	// Сэмплер оборачивает редактирующее ядро: redactingCore.Check не вызывает
+	// Check вложенного ядра, и сэмплер внутри него не сработал бы
+	config.Sampling = nil
+	wrap := zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if opts.Redact {
+			core = newRedactingCore(core)
+		}
+		if opts.SamplingInitial > 0 {
+			core = zapcore.NewSamplerWithOptions(core, time.Second, opts.SamplingInitial, opts.SamplingThereafter)
+		}
+		return core
+	})
+
+	logger, err := config.Build(wrap)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	return logger, config.Level, nil
+}
+
+func NewLogger() (*zap.Logger, error) {
+	logger, _, err := New(Options{Level: "info", SamplingInitial: 100, SamplingThereafter: 100, Redact: true})
+	return logger, err
 }
 
 func NewDevelopmentLogger() (*zap.Logger, error) {
-	config := zap.NewDevelopmentConfig()
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	return config.Build()
+	logger, _, err := New(Options{Development: true})
+	return logger, err
 }
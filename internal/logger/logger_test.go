@@ -3,6 +3,11 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -136,3 +141,98 @@ func TestLoggers_AreUsable(t *testing.T) {
 	devLogger.Info("test development log")
 	devLogger.Sync()
 }
+
+func TestNew(t *testing.T) {
+	t.Run("level can change at runtime", func(t *testing.T) {
+		logger, level, err := New(Options{Level: "warn", OutputPaths: []string{"stdout"}})
+		assert.NoError(t, err)
+		assert.False(t, logger.Core().Enabled(zap.InfoLevel))
+
+		level.SetLevel(zap.DebugLevel)
+		assert.True(t, logger.Core().Enabled(zap.DebugLevel))
+	})
+
+	t.Run("development defaults to debug", func(t *testing.T) {
+		_, level, err := New(Options{Development: true})
+		assert.NoError(t, err)
+		assert.Equal(t, zap.DebugLevel, level.Level())
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, _, err := New(Options{Level: "loud"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		_, _, err := New(Options{Encoding: "xml"})
+		assert.Error(t, err)
+	})
+
+	t.Run("writes json with redaction and sampling", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		logger, _, err := New(Options{
+			Level:              "info",
+			Encoding:           "json",
+			OutputPaths:        []string{path},
+			SamplingInitial:    2,
+			SamplingThereafter: 1000,
+			Redact:             true,
+		})
+		assert.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			logger.Info("hot message", zap.String("api_key", "wk_secret"))
+		}
+		logger.With(zap.String("wallet_uuid", "a1b2c3e4-5678-9012-3456-789012345678")).Info("other message")
+		assert.NoError(t, logger.Sync())
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 3)
+
+		var hot, other map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &hot))
+		assert.Equal(t, "[REDACTED]", hot["api_key"])
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &other))
+		assert.Equal(t, "a1b2c3e4-****", other["wallet_uuid"])
+		assert.NotContains(t, string(data), "wk_secret")
+	})
+}
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(newRedactingCore(core))
+
+	logger.Info("request served",
+		zap.String("path", "/api/v1/wallet/a1b2c3e4-5678-9012-3456-789012345678/operations"),
+		zap.String("Authorization", "Bearer abc"),
+		zap.Int64("token", 42),
+		zap.String("reference_id", "5b7c0b5e-1111-2222-3333-444455556666"))
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "/api/v1/wallet/a1b2c3e4-****/operations", fields["path"])
+	assert.Equal(t, "[REDACTED]", fields["Authorization"])
+	assert.Equal(t, "[REDACTED]", fields["token"])
+	assert.Equal(t, "5b7c0b5e-1111-2222-3333-444455556666", fields["reference_id"])
+}
+
+func TestRedactingCore_errors(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(newRedactingCore(core)).With(
+		zap.Error(fmt.Errorf("wallet %s not found", "A1B2C3E4-5678-9012-3456-789012345678")))
+
+	logger.Warn("operation failed",
+		zap.NamedError("cause", fmt.Errorf("walletUUID %s is not valid", "a1b2c3e4-5678-9012-3456-789012345678")),
+		zap.NamedError("plain", errors.New("query canceled")),
+		zap.Error(nil))
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "wallet A1B2C3E4-**** not found", fields["error"])
+	assert.Equal(t, "walletUUID a1b2c3e4-**** is not valid", fields["cause"])
+	assert.Equal(t, "query canceled", fields["plain"])
+}
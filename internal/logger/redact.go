@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redacted = "[REDACTED]"
+
+// secretKeys are fields whose value is never logged.
+var secretKeys = map[string]bool{
+	"api_key":       true,
+	"authorization": true,
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+}
+
+// walletKeys are fields that may contain wallet IDs, which are logged masked.
+var walletKeys = map[string]bool{
+	"wallet_uuid": true,
+	"path":        true,
+	"cache_key":   true,
+	"key":         true,
+}
+
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// redactingCore hides the values of secret fields and masks the wallet IDs
+// in the others and in error messages, leaving the first group of the UUID to
+// correlate entries.
+type redactingCore struct {
+	zapcore.Core
+}
+
+func newRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		replaced, ok := redactField(f)
+		if !ok {
+			continue
+		}
+		// Копируем срез только при первой замене
+		if out == nil {
+			out = make([]zapcore.Field, len(fields))
+			copy(out, fields)
+		}
+		out[i] = replaced
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+func redactField(f zapcore.Field) (zapcore.Field, bool) {
+	key := strings.ToLower(f.Key)
+	if secretKeys[key] {
+		return zap.String(f.Key, redacted), true
+	}
+	if walletKeys[key] && f.Type == zapcore.StringType && uuidPattern.MatchString(f.String) {
+		return zap.String(f.Key, maskUUIDs(f.String)), true
+	}
+	// Ошибки валидации и репозитория содержат UUID в тексте, ключ у них любой
+	if f.Type == zapcore.ErrorType {
+		if err, ok := f.Interface.(error); ok {
+			if msg := err.Error(); uuidPattern.MatchString(msg) {
+				return zap.String(f.Key, maskUUIDs(msg)), true
+			}
+		}
+	}
+	return f, false
+}
+
+// maskUUIDs replaces every UUID in s by its first group followed by a mask.
+func maskUUIDs(s string) string {
+	return uuidPattern.ReplaceAllStringFunc(s, func(id string) string {
+		return id[:8] + "-****"
+	})
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// FallbackLimiter uses the primary limiter and switches to the fallback for
+// requests the primary one fails on, so that a redis outage neither blocks
+// all traffic nor disables limiting.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	logger   *zap.Logger
+}
+
+func NewFallbackLimiter(primary, fallback Limiter, logger *zap.Logger) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+func (f *FallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := f.primary.Allow(ctx, key, limit)
+	if err == nil {
+		return res, nil
+	}
+
+	f.logger.Warn("rate limiter unavailable, using in-process fallback",
+		zap.String("key", key),
+		zap.Error(err))
+
+	return f.fallback.Allow(ctx, key, limit)
+}
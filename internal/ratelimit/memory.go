@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// How many buckets the memory limiter keeps before dropping full ones.
+const memoryCleanupThreshold = 10000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// MemoryLimiter keeps buckets in process memory. Limits are enforced per
+// instance, so it is only used when redis is not available.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		if len(m.buckets) >= memoryCleanupThreshold {
+			m.cleanup(now)
+		}
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		m.buckets[key] = b
+	}
+	b.limit = limit
+
+	var res Result
+	b.tokens, res = take(b.tokens, now.Sub(b.updated), limit)
+	b.updated = now
+
+	return res, nil
+}
+
+// cleanup drops buckets that have refilled completely, they are
+// indistinguishable from new ones.
+func (m *MemoryLimiter) cleanup(now time.Time) {
+	for key, b := range m.buckets {
+		refill := now.Sub(b.updated).Seconds() * b.limit.Rate
+		if b.tokens+refill >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
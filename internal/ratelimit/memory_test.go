@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := limiter.Allow(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+		assert.Equal(t, 3, res.Limit)
+	}
+
+	res, err := limiter.Allow(ctx, "client", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, res.ResetAfter)
+
+	t.Run("other keys have their own bucket", func(t *testing.T) {
+		res, err := limiter.Allow(ctx, "other", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+
+	t.Run("bucket refills over time", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+		res, err := limiter.Allow(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+
+		now = now.Add(time.Hour)
+		res, err = limiter.Allow(ctx, "client", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 2, res.Remaining)
+	})
+
+	t.Run("zero rate is unlimited", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			res, err := limiter.Allow(ctx, "unlimited", Limit{})
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		}
+	})
+}
+
+func TestMemoryLimiter_Concurrent(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	limit := Limit{Rate: 0.001, Burst: 50}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := limiter.Allow(context.Background(), "wallet", limit)
+			assert.NoError(t, err)
+			if res.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, allowed)
+}
+
+func TestMemoryLimiter_Cleanup(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 1}
+
+	for i := 0; i < memoryCleanupThreshold; i++ {
+		_, err := limiter.Allow(context.Background(), fmt.Sprintf("key-%d", i), limit)
+		require.NoError(t, err)
+	}
+
+	now = now.Add(time.Minute)
+	_, err := limiter.Allow(context.Background(), "new", limit)
+	require.NoError(t, err)
+	assert.Len(t, limiter.buckets, 1)
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests can be made at once, after that
+// Rate requests per second. A zero Rate means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed bool
+
+	// The bucket size, reported as RateLimit-Limit
+	Limit int
+
+	// Requests that can still be made right now
+	Remaining int
+
+	// How long to wait before the next request is allowed, zero if it is
+	RetryAfter time.Duration
+
+	// How long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Limiter counts a request against the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take refills a bucket holding tokens that was last updated elapsed ago and
+// takes one token from it. It returns the new number of tokens and the result.
+// Both limiters use it so that they behave the same.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+	}
+
+	res := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+	res.Remaining = int(math.Floor(tokens))
+	res.ResetAfter = secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate)
+
+	return tokens, res
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
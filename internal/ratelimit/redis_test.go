@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 10, Burst: 20}
+	keys := []string{"ratelimit:client:key:1"}
+
+	t.Run("allowed", func(t *testing.T) {
+		client, mock := redismock.NewClientMock()
+		mock.ExpectEvalSha(tokenBucketScript.Hash(), keys, limit.Rate, limit.Burst).
+			SetVal([]interface{}{int64(1), int64(19), int64(0), int64(100)})
+
+		res, err := NewRedisLimiter(client).Allow(ctx, "client:key:1", limit)
+		require.NoError(t, err)
+		assert.Equal(t, Result{
+			Allowed:    true,
+			Limit:      20,
+			Remaining:  19,
+			ResetAfter: 100 * time.Millisecond,
+		}, res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		client, mock := redismock.NewClientMock()
+		mock.ExpectEvalSha(tokenBucketScript.Hash(), keys, limit.Rate, limit.Burst).
+			SetVal([]interface{}{int64(0), int64(0), int64(70), int64(2000)})
+
+		res, err := NewRedisLimiter(client).Allow(ctx, "client:key:1", limit)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 70*time.Millisecond, res.RetryAfter)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		client, mock := redismock.NewClientMock()
+		mock.ExpectEvalSha(tokenBucketScript.Hash(), keys, limit.Rate, limit.Burst).
+			SetErr(errors.New("connection refused"))
+
+		_, err := NewRedisLimiter(client).Allow(ctx, "client:key:1", limit)
+		assert.Error(t, err)
+	})
+}
+
+func TestFallbackLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 0.001, Burst: 1}
+
+	client, mock := redismock.NewClientMock()
+	mock.ExpectEvalSha(tokenBucketScript.Hash(), []string{"ratelimit:wallet:1"}, limit.Rate, limit.Burst).
+		SetErr(errors.New("connection refused"))
+	mock.ExpectEvalSha(tokenBucketScript.Hash(), []string{"ratelimit:wallet:1"}, limit.Rate, limit.Burst).
+		SetErr(errors.New("connection refused"))
+
+	limiter := NewFallbackLimiter(NewRedisLimiter(client), NewMemoryLimiter(), zaptest.NewLogger(t))
+
+	res, err := limiter.Allow(ctx, "wallet:1", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = limiter.Allow(ctx, "wallet:1", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,155 @@
+package walletmemory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// balanceAt computes the balance of the wallet at the given time the way
+// walletpostgresql does: the balance after the last operation applied by then,
+// counted from the latest end-of-day snapshot taken before then. Without
+// either, it is the balance before the first operation applied later or, for
+// a wallet without operations, the current one.
+func balanceAt(w *wallet, at time.Time) int {
+	var (
+		base  *snapshot
+		after time.Time
+	)
+	for i := len(w.snapshots) - 1; i >= 0; i-- {
+		if w.snapshots[i].asOf.Before(at) {
+			base = &w.snapshots[i]
+			after = base.asOf
+			break
+		}
+	}
+
+	for i := len(w.applied) - 1; i >= 0; i-- {
+		op := w.applied[i]
+		if op.AppliedAt.After(at) {
+			continue
+		}
+		if base != nil && !op.AppliedAt.After(after) {
+			break
+		}
+		return *op.BalanceAfter
+	}
+
+	if base != nil {
+		return base.balance
+	}
+
+	for _, op := range w.applied {
+		if op.AppliedAt.After(at) {
+			return *op.BalanceAfter - op.delta()
+		}
+	}
+
+	return w.balance
+}
+
+// delta is how much the operation changed the balance of its wallet.
+func (o *operation) delta() int {
+	if o.Operation.Operation == types.OperationTypeWithdraw {
+		return -(o.Amount + o.fee)
+	}
+	return o.Amount
+}
+
+// GetBalanceAt returns the balance the wallet had at the given time.
+func (r *Repository) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return 0, types.ErrWalletNotFound
+	}
+	return balanceAt(w, at), nil
+}
+
+// SnapshotBalances stores the end-of-day balance of every wallet that existed
+// by the end of day, which is given as a UTC date. Taking the snapshot of a
+// day again overwrites it.
+func (r *Repository) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	asOf := day.AddDate(0, 0, 1)
+
+	var n int64
+	for _, w := range r.wallets {
+		if !w.createdAt.Before(asOf) {
+			continue
+		}
+
+		s := snapshot{day: day, asOf: asOf, balance: balanceAt(w, asOf)}
+		i := sort.Search(len(w.snapshots), func(i int) bool {
+			return !w.snapshots[i].day.Before(day)
+		})
+		if i < len(w.snapshots) && w.snapshots[i].day.Equal(day) {
+			w.snapshots[i] = s
+		} else {
+			w.snapshots = append(w.snapshots, snapshot{})
+			copy(w.snapshots[i+1:], w.snapshots[i:])
+			w.snapshots[i] = s
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// StreamStatement writes the statement of the wallet for operations applied
+// after from and up to to. The statement is read under the lock and written
+// after it is released, so a slow writer does not block other calls.
+func (r *Repository) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	opening, entries, err := r.statement(walletUUID, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WriteOpening(opening); err != nil {
+		return err
+	}
+
+	closing := opening
+	for i := range entries {
+		if err := w.WriteEntry(&entries[i]); err != nil {
+			return err
+		}
+		closing = entries[i].Balance
+	}
+
+	return w.WriteClosing(closing)
+}
+
+func (r *Repository) statement(walletUUID string, from, to time.Time) (int, []types.StatementEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, ok := r.wallets[walletUUID]
+	if !ok {
+		return 0, nil, types.ErrWalletNotFound
+	}
+
+	var entries []types.StatementEntry
+	for _, op := range wallet.applied {
+		if !op.AppliedAt.After(from) || op.AppliedAt.After(to) {
+			continue
+		}
+		entries = append(entries, types.StatementEntry{
+			AppliedAt:   *op.AppliedAt,
+			ReferenceID: op.ReferenceID,
+			Operation:   op.Operation.Operation,
+			Amount:      op.Amount,
+			Fee:         op.fee,
+			Balance:     *op.BalanceAfter,
+		})
+	}
+
+	return balanceAt(wallet, from), entries, nil
+}
@@ -0,0 +1,154 @@
+package walletmemory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// effectiveLimits returns the tier of the wallet, its limits with the wallet's
+// own overrides applied, and the fee rule of the tier.
+func (r *Repository) effectiveLimits(w *wallet) (string, types.LimitValues, types.FeeRule) {
+	name := types.DefaultLimitTier
+	var overrides types.LimitValues
+	if w.limits != nil {
+		name = w.limits.tier
+		overrides = w.limits.overrides
+	}
+
+	t := r.tiers[name]
+	return name, types.LimitValues{
+		MaxWithdrawal:     coalesce(overrides.MaxWithdrawal, t.limits.MaxWithdrawal),
+		DailyWithdrawal:   coalesce(overrides.DailyWithdrawal, t.limits.DailyWithdrawal),
+		MonthlyWithdrawal: coalesce(overrides.MonthlyWithdrawal, t.limits.MonthlyWithdrawal),
+		MaxBalance:        coalesce(overrides.MaxBalance, t.limits.MaxBalance),
+	}, t.fee
+}
+
+func coalesce(values ...*int) *int {
+	for _, v := range values {
+		if v != nil {
+			value := *v
+			return &value
+		}
+	}
+	return nil
+}
+
+// withdrawn sums the applied withdrawals of the wallet in the UTC day and
+// month of now.
+func withdrawn(w *wallet, now time.Time) (daily, monthly int) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for _, op := range w.applied {
+		if op.Operation.Operation != types.OperationTypeWithdraw || op.AppliedAt.Before(month) {
+			continue
+		}
+		monthly += op.Amount
+		if !op.AppliedAt.Before(day) {
+			daily += op.Amount
+		}
+	}
+	return daily, monthly
+}
+
+// checkLimits fails with *types.LimitExceededError if applying req would
+// break one of the wallet limits.
+func checkLimits(w *wallet, req *types.WalletUpdateRequest, newBalance int, limits types.LimitValues, now time.Time) error {
+	if req.Operation == types.OperationTypeDeposit {
+		if limits.MaxBalance != nil && newBalance > *limits.MaxBalance {
+			return &types.LimitExceededError{Limit: types.LimitMaxBalance, Max: *limits.MaxBalance, Value: newBalance}
+		}
+		return nil
+	}
+
+	if limits.MaxWithdrawal != nil && req.Amount > *limits.MaxWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitMaxWithdrawal, Max: *limits.MaxWithdrawal, Value: req.Amount}
+	}
+
+	daily, monthly := withdrawn(w, now)
+	if limits.DailyWithdrawal != nil && daily+req.Amount > *limits.DailyWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitDailyWithdrawal, Max: *limits.DailyWithdrawal, Value: daily + req.Amount}
+	}
+	if limits.MonthlyWithdrawal != nil && monthly+req.Amount > *limits.MonthlyWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitMonthlyWithdrawal, Max: *limits.MonthlyWithdrawal, Value: monthly + req.Amount}
+	}
+
+	return nil
+}
+
+// GetWalletLimits returns the tier, effective limits, overrides and current
+// withdrawal totals of a wallet.
+func (r *Repository) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return nil, types.ErrWalletNotFound
+	}
+
+	tier, limits, _ := r.effectiveLimits(w)
+	result := &types.WalletLimits{
+		WalletUUID: walletUUID,
+		Tier:       tier,
+		Limits:     limits,
+	}
+	if w.limits != nil {
+		result.Overrides = types.LimitValues{
+			MaxWithdrawal:     coalesce(w.limits.overrides.MaxWithdrawal),
+			DailyWithdrawal:   coalesce(w.limits.overrides.DailyWithdrawal),
+			MonthlyWithdrawal: coalesce(w.limits.overrides.MonthlyWithdrawal),
+			MaxBalance:        coalesce(w.limits.overrides.MaxBalance),
+		}
+	}
+	result.DailyWithdrawn, result.MonthlyWithdrawn = withdrawn(w, r.timestamp())
+
+	return result, nil
+}
+
+// SetWalletLimits assigns a tier to the wallet and replaces its overrides.
+func (r *Repository) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error {
+	if err := checkOverrides(update.Overrides); err != nil {
+		return fmt.Errorf("failed to set wallet limits: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return fmt.Errorf("failed to set wallet limits: %w", types.ErrWalletNotFound)
+	}
+	if _, ok := r.tiers[update.Tier]; !ok {
+		return fmt.Errorf("failed to set wallet limits: %w", types.ErrUnknownTier)
+	}
+
+	w.limits = &walletLimits{
+		tier: update.Tier,
+		overrides: types.LimitValues{
+			MaxWithdrawal:     coalesce(update.Overrides.MaxWithdrawal),
+			DailyWithdrawal:   coalesce(update.Overrides.DailyWithdrawal),
+			MonthlyWithdrawal: coalesce(update.Overrides.MonthlyWithdrawal),
+			MaxBalance:        coalesce(update.Overrides.MaxBalance),
+		},
+	}
+	return nil
+}
+
+// checkOverrides enforces the check constraints of the wallet_limits table.
+func checkOverrides(overrides types.LimitValues) error {
+	for _, v := range []*int{overrides.MaxWithdrawal, overrides.DailyWithdrawal, overrides.MonthlyWithdrawal} {
+		if v != nil && *v <= 0 {
+			return types.ErrCheckViolation
+		}
+	}
+	if overrides.MaxBalance != nil && *overrides.MaxBalance < 0 {
+		return types.ErrCheckViolation
+	}
+	return nil
+}
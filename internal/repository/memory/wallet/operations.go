@@ -0,0 +1,338 @@
+package walletmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+)
+
+const (
+	statusPending = "PENDING"
+	statusApplied = "APPLIED"
+	statusFailed  = "FAILED"
+)
+
+// txn collects the changes made by a single call, so that they can be undone
+// when it fails, like a rolled back transaction. It is used under the write
+// lock of the repository.
+type txn struct {
+	r    *Repository
+	now  time.Time
+	undo []func()
+	// fees are the FEE operations yet to be credited to the fee wallet
+	fees []*operation
+}
+
+func (r *Repository) begin() *txn {
+	return &txn{r: r, now: r.timestamp()}
+}
+
+func (t *txn) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+}
+
+// touch remembers the state of w before it is changed.
+func (t *txn) touch(w *wallet) {
+	saved := *w
+	t.undo = append(t.undo, func() { *w = saved })
+}
+
+// logOperation adds op to the operation log. IDs are never reused, like
+// values of a sequence.
+func (t *txn) logOperation(op *operation) {
+	t.r.lastID++
+	op.ID = t.r.lastID
+	t.r.operations[op.ReferenceID] = op
+	t.undo = append(t.undo, func() { delete(t.r.operations, op.ReferenceID) })
+}
+
+// UpdateBalance applies a single operation and returns the fee charged for it.
+func (r *Repository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
+	if err := ctx.Err(); err != nil {
+		return types.Fee{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.begin()
+	fee, err := t.apply(req)
+	if err == nil {
+		err = t.creditFees()
+	}
+	if err != nil {
+		t.rollback()
+		if types.IsRejected(err) {
+			r.failOperation(req.ReferenceID, err)
+		}
+		return types.Fee{}, err
+	}
+
+	return fee, nil
+}
+
+// UpdateBalanceBatch applies all requests at once: either every operation is
+// applied or none is. On failure a *types.BatchItemError tells which request
+// caused it.
+func (r *Repository) UpdateBalanceBatch(ctx context.Context, reqs []*types.WalletUpdateRequest) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.begin()
+	for i, req := range reqs {
+		if _, err := t.apply(req); err != nil {
+			t.rollback()
+			return &types.BatchItemError{Index: i, ReferenceID: req.ReferenceID, Err: err}
+		}
+	}
+
+	if err := t.creditFees(); err != nil {
+		t.rollback()
+		return err
+	}
+
+	return nil
+}
+
+// replaceOperation puts op in place of the queued operation it applies,
+// keeping the identity and the details of the queued one.
+func (t *txn) replaceOperation(queued, op *operation) {
+	op.ID = queued.ID
+	op.CreatedAt = queued.CreatedAt
+	op.Async = true
+	op.Attempts = queued.Attempts
+	op.OperationDetails = queued.OperationDetails
+	t.r.operations[op.ReferenceID] = op
+	t.undo = append(t.undo, func() { t.r.operations[op.ReferenceID] = queued })
+}
+
+// apply logs the operation and moves the wallet balance. A withdrawal is also
+// charged its fee, which is logged as a FEE operation and credited to the fee
+// wallet by creditFees. An async operation queued with the same reference ID
+// and payload is applied in place of logging a new one.
+func (t *txn) apply(req *types.WalletUpdateRequest) (types.Fee, error) {
+	queued, ok := t.r.operations[req.ReferenceID]
+	if ok && !queued.queuedAs(req) {
+		return types.Fee{}, fmt.Errorf("failed to log operation: %w", types.ErrOperationExists)
+	}
+
+	w, ok := t.r.wallets[req.WalletUUID]
+	if !ok {
+		return types.Fee{}, fmt.Errorf("failed to log operation: %w", types.ErrWalletNotFound)
+	}
+
+	if w.frozen {
+		return types.Fee{}, types.ErrWalletFrozen
+	}
+
+	if req.Operation != types.OperationTypeDeposit && req.Operation != types.OperationTypeWithdraw {
+		return types.Fee{}, types.ErrInvalidOperation
+	}
+
+	_, limits, feeRule := t.r.effectiveLimits(w)
+
+	var fee types.Fee
+	newBalance := w.balance
+	if req.Operation == types.OperationTypeDeposit {
+		newBalance += req.Amount
+	} else {
+		if w.uuid != types.FeeWalletUUID {
+			fee = feeRule.Compute(req.Amount)
+		}
+		if w.balance+w.creditLimit < req.Amount+fee.Total {
+			return types.Fee{}, types.ErrInsufficientFunds
+		}
+		newBalance -= req.Amount + fee.Total
+	}
+
+	if err := checkLimits(w, req, newBalance, limits, t.now); err != nil {
+		return types.Fee{}, err
+	}
+
+	balanceAfter := newBalance
+	appliedAt := t.now
+	op := &operation{
+		Operation: types.Operation{
+			WalletUUID:   req.WalletUUID,
+			Operation:    req.Operation,
+			Amount:       req.Amount,
+			ReferenceID:  req.ReferenceID,
+			Status:       statusApplied,
+			BalanceAfter: &balanceAfter,
+			CreatedAt:    t.now,
+			AppliedAt:    &appliedAt,
+
+			OperationDetails: copyDetails(req.OperationDetails),
+		},
+		fee: fee.Total,
+	}
+	if queued != nil {
+		t.replaceOperation(queued, op)
+	} else {
+		t.logOperation(op)
+	}
+
+	t.touch(w)
+	w.balance = newBalance
+	w.version++
+	w.updatedAt = t.now
+	w.applied = append(w.applied, op)
+
+	if fee.Total > 0 {
+		feeAppliedAt := t.now
+		feeOp := &operation{Operation: types.Operation{
+			WalletUUID:        types.FeeWalletUUID,
+			Operation:         types.OperationTypeFee,
+			Amount:            fee.Total,
+			ReferenceID:       feeReferenceID(req.ReferenceID),
+			ParentReferenceID: req.ReferenceID,
+			Status:            statusApplied,
+			CreatedAt:         t.now,
+			AppliedAt:         &feeAppliedAt,
+		}}
+		t.logOperation(feeOp)
+		t.fees = append(t.fees, feeOp)
+	}
+
+	return fee, nil
+}
+
+// creditFees adds the fees collected by the call to the fee wallet and fills
+// in the balance after each FEE operation.
+func (t *txn) creditFees() error {
+	if len(t.fees) == 0 {
+		return nil
+	}
+
+	w, ok := t.r.wallets[types.FeeWalletUUID]
+	if !ok {
+		return fmt.Errorf("failed to credit fees: fee wallet %s does not exist", types.FeeWalletUUID)
+	}
+
+	t.touch(w)
+	for _, op := range t.fees {
+		w.balance += op.Amount
+		balanceAfter := w.balance
+		op.BalanceAfter = &balanceAfter
+		w.applied = append(w.applied, op)
+	}
+	w.version++
+	w.updatedAt = t.now
+
+	return nil
+}
+
+// feeReferenceID derives the reference of the FEE operation from the one of
+// the withdrawal, the same way walletpostgresql does.
+func feeReferenceID(referenceID string) string {
+	return uuid.NewSHA1(uuid.MustParse(types.FeeWalletUUID), []byte(referenceID)).String()
+}
+
+func (r *Repository) CheckOperationExists(ctx context.Context, referenceID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.operations[referenceID]
+	return ok, nil
+}
+
+func (r *Repository) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.operations[referenceID]
+	if !ok {
+		return nil, types.ErrOperationNotFound
+	}
+	return op.toType(), nil
+}
+
+// ListOperations returns up to filter.Limit operations of the wallet that
+// match the filter, the newest first.
+func (r *Repository) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.wallets[walletUUID]; !ok {
+		return nil, types.ErrWalletNotFound
+	}
+
+	var matched []*operation
+	for _, op := range r.operations {
+		if op.WalletUUID == walletUUID && op.matches(filter) {
+			matched = append(matched, op)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ID > matched[j].ID
+	})
+	if len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+
+	ops := make([]*types.Operation, 0, len(matched))
+	for _, op := range matched {
+		ops = append(ops, op.toType())
+	}
+	return ops, nil
+}
+
+func (o *operation) matches(filter types.OperationFilter) bool {
+	if filter.BeforeID > 0 && o.ID >= filter.BeforeID {
+		return false
+	}
+	if filter.ExternalRef != "" && o.ExternalRef != filter.ExternalRef {
+		return false
+	}
+	for key, value := range filter.Metadata {
+		stored, ok := o.Metadata[key]
+		if !ok || (value != "" && stored != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReconcileBalances returns the wallets whose balance differs from the balance
+// after their last applied operation. Wallets without operations are skipped.
+func (r *Repository) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mismatches := make([]types.BalanceMismatch, 0)
+	for _, w := range r.wallets {
+		if len(w.applied) == 0 {
+			continue
+		}
+		last := w.applied[len(w.applied)-1]
+		if *last.BalanceAfter != w.balance {
+			mismatches = append(mismatches, types.BalanceMismatch{
+				WalletUUID:      w.uuid,
+				Balance:         w.balance,
+				ExpectedBalance: *last.BalanceAfter,
+				LastReferenceID: last.ReferenceID,
+			})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].WalletUUID < mismatches[j].WalletUUID
+	})
+	return mismatches, nil
+}
+
+// SweepPendingOperations marks as failed the operations that have been
+// pending for longer than olderThan. Operations are applied here as a whole,
+// so none is ever left pending and there is nothing to sweep.
+func (r *Repository) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,136 @@
+package walletmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// queuedAs reports whether o is a queued async operation with the payload of
+// req, which applying req takes over.
+func (o *operation) queuedAs(req *types.WalletUpdateRequest) bool {
+	return o.Async && o.Status == statusPending &&
+		o.WalletUUID == req.WalletUUID &&
+		o.Operation.Operation == req.Operation &&
+		o.Amount == req.Amount
+}
+
+// EnqueueOperation stores the operation as PENDING for an async worker to
+// apply. Only the wallet's existence and the reference ID are checked here.
+func (r *Repository) EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	// Ограничения таблицы wallet_operations
+	if req.Amount <= 0 || (req.Operation != types.OperationTypeDeposit && req.Operation != types.OperationTypeWithdraw) {
+		return nil, fmt.Errorf("failed to enqueue operation: %w", types.ErrCheckViolation)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.operations[req.ReferenceID]; ok {
+		return nil, fmt.Errorf("failed to enqueue operation: %w", types.ErrOperationExists)
+	}
+	if _, ok := r.wallets[req.WalletUUID]; !ok {
+		return nil, fmt.Errorf("failed to enqueue operation: %w", types.ErrWalletNotFound)
+	}
+
+	r.lastID++
+	op := &operation{Operation: types.Operation{
+		ID:          r.lastID,
+		WalletUUID:  req.WalletUUID,
+		Operation:   req.Operation,
+		Amount:      req.Amount,
+		ReferenceID: req.ReferenceID,
+		Status:      statusPending,
+		CreatedAt:   r.timestamp(),
+		Async:       true,
+
+		OperationDetails: copyDetails(req.OperationDetails),
+	}}
+	r.operations[req.ReferenceID] = op
+
+	return op.toType(), nil
+}
+
+// ClaimQueuedOperations picks up to limit queued operations, oldest first, and
+// locks them for lease. An operation that is still PENDING when the lease
+// expires is picked up again.
+func (r *Repository) ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	var due []*operation
+	for _, op := range r.operations {
+		if op.Async && op.Status == statusPending && !op.lockedUntil.After(now) {
+			due = append(due, op)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	ops := make([]*types.Operation, 0, len(due))
+	for _, op := range due {
+		op.lockedUntil = now.Add(lease)
+		op.Attempts++
+		ops = append(ops, op.toType())
+	}
+	return ops, nil
+}
+
+// FailOperation marks a PENDING operation as failed and stores the failure
+// code of cause together with its message. Operations that are not pending are
+// left alone.
+func (r *Repository) FailOperation(ctx context.Context, referenceID string, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failOperation(referenceID, cause)
+	return nil
+}
+
+func (r *Repository) failOperation(referenceID string, cause error) {
+	op, ok := r.operations[referenceID]
+	if !ok || op.Status != statusPending {
+		return
+	}
+
+	failedAt := r.timestamp()
+	op.Status = statusFailed
+	op.AppliedAt = &failedAt
+	op.FailureCode = types.FailureCode(cause)
+	op.FailureMessage = types.FailureMessage(cause)
+	op.lockedUntil = time.Time{}
+}
+
+// CountFailedOperations counts the operations failed since the given moment
+// by failure code, the most frequent first.
+func (r *Repository) CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byCode := make(map[string]int64)
+	for _, op := range r.operations {
+		if op.Status == statusFailed && !op.AppliedAt.Before(since) {
+			byCode[op.FailureCode]++
+		}
+	}
+
+	counts := make([]types.FailureCount, 0, len(byCode))
+	for code, n := range byCode {
+		counts = append(counts, types.FailureCount{Code: code, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Code < counts[j].Code
+	})
+	return counts, nil
+}
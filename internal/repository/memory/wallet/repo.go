@@ -0,0 +1,153 @@
+// Package walletmemory keeps wallets and their operation log in memory. It
+// follows the behavior of walletpostgresql and is meant for tests and local
+// development, where running Postgres is not worth it.
+package walletmemory
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// Repository is a concurrency-safe in-memory walletservice.ReadWriter. Every
+// call runs under a single lock, so operations never conflict with each other
+// and a batch is applied atomically.
+type Repository struct {
+	mu         sync.RWMutex
+	wallets    map[string]*wallet
+	operations map[string]*operation
+	lastID     int64
+	tiers      map[string]tier
+	now        func() time.Time
+}
+
+type wallet struct {
+	uuid        string
+	owner       string
+	balance     int
+	version     int
+	creditLimit int
+	frozen      bool
+	createdAt   time.Time
+	updatedAt   time.Time
+	// limits is nil until the wallet is assigned a tier or overrides
+	limits *walletLimits
+	// applied holds the applied operations in the order they were applied
+	applied   []*operation
+	snapshots []snapshot
+}
+
+type walletLimits struct {
+	tier      string
+	overrides types.LimitValues
+}
+
+type operation struct {
+	types.Operation
+	// fee is the total fee charged for a withdrawal
+	fee int
+	// lockedUntil is the end of the lease of a claimed async operation
+	lockedUntil time.Time
+}
+
+// snapshot is the balance of a wallet at the end of a day, as_of being the
+// start of the next one.
+type snapshot struct {
+	day     time.Time
+	asOf    time.Time
+	balance int
+}
+
+type tier struct {
+	limits types.LimitValues
+	fee    types.FeeRule
+}
+
+type Option func(*Repository)
+
+// WithTier adds a limit tier with its fee rule or replaces an existing one,
+// the default tier included.
+func WithTier(name string, limits types.LimitValues, fee types.FeeRule) Option {
+	return func(r *Repository) {
+		r.tiers[name] = tier{limits: limits, fee: fee}
+	}
+}
+
+// WithClock sets the source of the timestamps stored with wallets and
+// operations.
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) {
+		r.now = now
+	}
+}
+
+func NewRepository(opts ...Option) *Repository {
+	r := &Repository{
+		wallets:    make(map[string]*wallet),
+		operations: make(map[string]*operation),
+		tiers:      map[string]tier{types.DefaultLimitTier: {}},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	// Как и миграция, заводит системный кошелёк для комиссий
+	now := r.timestamp()
+	r.wallets[types.FeeWalletUUID] = &wallet{
+		uuid:      types.FeeWalletUUID,
+		createdAt: now,
+		updatedAt: now,
+	}
+
+	return r
+}
+
+// timestamp returns the current time with the precision Postgres keeps.
+func (r *Repository) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Microsecond)
+}
+
+func (w *wallet) funds() types.Funds {
+	return types.NewFunds(w.balance, w.creditLimit)
+}
+
+func (w *wallet) toType() *types.Wallet {
+	return &types.Wallet{
+		WalletUUID: w.uuid,
+		Funds:      w.funds(),
+		Owner:      w.owner,
+		Frozen:     w.frozen,
+		CreatedAt:  w.createdAt,
+		UpdatedAt:  w.updatedAt,
+	}
+}
+
+// toType returns a copy of the operation that does not share memory with the
+// repository.
+func (o *operation) toType() *types.Operation {
+	op := o.Operation
+	if o.BalanceAfter != nil {
+		balance := *o.BalanceAfter
+		op.BalanceAfter = &balance
+	}
+	if o.AppliedAt != nil {
+		appliedAt := *o.AppliedAt
+		op.AppliedAt = &appliedAt
+	}
+	op.OperationDetails = copyDetails(o.OperationDetails)
+	return &op
+}
+
+// copyDetails copies the metadata map so that callers cannot change a stored
+// operation. Empty metadata is dropped, as Postgres stores it as NULL.
+func copyDetails(d types.OperationDetails) types.OperationDetails {
+	if len(d.Metadata) == 0 {
+		d.Metadata = nil
+	} else {
+		d.Metadata = maps.Clone(d.Metadata)
+	}
+	return d
+}
@@ -0,0 +1,87 @@
+package walletmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/readwritertest"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ walletservice.ReadWriter = (*Repository)(nil)
+
+func TestRepository_Conformance(t *testing.T) {
+	readwritertest.Run(t, func(t *testing.T) walletservice.ReadWriter {
+		return NewRepository()
+	})
+}
+
+func TestRepository_Fees(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(WithTier(types.DefaultLimitTier, types.LimitValues{}, types.FeeRule{Flat: 5, PercentBps: 150}))
+
+	wallet, err := repo.CreateWallet(ctx, "", 0)
+	require.NoError(t, err)
+	_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, 2000, uuid.NewString()))
+	require.NoError(t, err)
+
+	referenceID := uuid.NewString()
+	fee, err := repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeWithdraw, 1000, referenceID))
+	require.NoError(t, err)
+	assert.Equal(t, types.Fee{Flat: 5, Percent: 15, Total: 20}, fee)
+
+	funds, err := repo.GetBalance(ctx, wallet.WalletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 980, funds.Balance)
+
+	feeFunds, err := repo.GetBalance(ctx, types.FeeWalletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 20, feeFunds.Balance)
+
+	feeOp, err := repo.GetOperation(ctx, feeReferenceID(referenceID))
+	require.NoError(t, err)
+	assert.Equal(t, types.OperationTypeFee, feeOp.Operation)
+	assert.Equal(t, referenceID, feeOp.ParentReferenceID)
+	require.NotNil(t, feeOp.BalanceAfter)
+	assert.Equal(t, 20, *feeOp.BalanceAfter)
+
+	// Комиссия учитывается в доступном остатке
+	_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeWithdraw, 970, uuid.NewString()))
+	assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+}
+
+func TestRepository_SnapshotBalances(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := NewRepository(WithClock(func() time.Time { return now }))
+
+	wallet, err := repo.CreateWallet(ctx, "", 0)
+	require.NoError(t, err)
+	_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, 100, uuid.NewString()))
+	require.NoError(t, err)
+
+	n, err := repo.SnapshotBalances(ctx, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n, "wallets created later are skipped")
+
+	n, err = repo.SnapshotBalances(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n, "the wallet and the fee wallet")
+
+	now = now.AddDate(0, 0, 1)
+	_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, 50, uuid.NewString()))
+	require.NoError(t, err)
+
+	balance, err := repo.GetBalanceAt(ctx, wallet.WalletUUID, time.Date(2026, 10, 2, 6, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 100, balance)
+
+	balance, err = repo.GetBalanceAt(ctx, wallet.WalletUUID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 150, balance)
+}
@@ -0,0 +1,145 @@
+package walletmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+)
+
+// CreateWallet creates an empty wallet. An empty owner leaves the wallet
+// without one.
+func (r *Repository) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	if creditLimit < 0 {
+		return nil, fmt.Errorf("failed to create wallet: %w", types.ErrCheckViolation)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	w := &wallet{
+		uuid:        uuid.NewString(),
+		owner:       owner,
+		creditLimit: creditLimit,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+	r.wallets[w.uuid] = w
+
+	return w.toType(), nil
+}
+
+func (r *Repository) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return nil, types.ErrWalletNotFound
+	}
+	return w.toType(), nil
+}
+
+func (r *Repository) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return types.Funds{}, types.ErrWalletNotFound
+	}
+	return w.funds(), nil
+}
+
+// GetBalances returns the balances of the given wallets keyed by wallet UUID.
+// Wallets that do not exist are absent from the result.
+func (r *Repository) GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	balances := make(map[string]types.Funds, len(walletUUIDs))
+	for _, walletUUID := range walletUUIDs {
+		if w, ok := r.wallets[walletUUID]; ok {
+			balances[walletUUID] = w.funds()
+		}
+	}
+	return balances, nil
+}
+
+// GetWalletOwners returns the owners of the given wallets keyed by wallet
+// UUID. Wallets that do not exist or have no owner are absent from the result.
+func (r *Repository) GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owners := make(map[string]string, len(walletUUIDs))
+	for _, walletUUID := range walletUUIDs {
+		if w, ok := r.wallets[walletUUID]; ok && w.owner != "" {
+			owners[walletUUID] = w.owner
+		}
+	}
+	return owners, nil
+}
+
+// SetWalletFrozen freezes or unfreezes a wallet. Operations on a frozen
+// wallet fail with types.ErrWalletFrozen.
+func (r *Repository) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return types.ErrWalletNotFound
+	}
+	w.frozen = frozen
+	w.updatedAt = r.timestamp()
+	return nil
+}
+
+// SetCreditLimit changes how far below zero the wallet balance may go. It
+// fails with types.ErrInsufficientFunds if the wallet is already deeper in
+// overdraft than the new limit allows.
+func (r *Repository) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets[walletUUID]
+	if !ok {
+		return types.ErrWalletNotFound
+	}
+	if creditLimit < 0 {
+		return fmt.Errorf("failed to set credit limit: %w", types.ErrCheckViolation)
+	}
+	if w.balance < -creditLimit {
+		return fmt.Errorf("failed to set credit limit: %w", types.ErrInsufficientFunds)
+	}
+
+	w.creditLimit = creditLimit
+	w.version++
+	w.updatedAt = r.timestamp()
+	return nil
+}
+
+// GetOverdraftWallets returns the wallets with a negative balance, deepest
+// overdraft first.
+func (r *Repository) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallets := make([]types.OverdraftWallet, 0)
+	for _, w := range r.wallets {
+		if w.balance < 0 {
+			wallets = append(wallets, types.OverdraftWallet{WalletUUID: w.uuid, Funds: w.funds()})
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		if wallets[i].Balance != wallets[j].Balance {
+			return wallets[i].Balance < wallets[j].Balance
+		}
+		return wallets[i].WalletUUID < wallets[j].WalletUUID
+	})
+	return wallets, nil
+}
@@ -0,0 +1,24 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// CreateAPIKey stores key and fills in its ID and creation time.
+func (r *Repository) CreateAPIKey(ctx context.Context, key *types.APIKey) error {
+	query := `
+        INSERT INTO api_keys (name, prefix, key_hash, scopes)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `
+	err := r.db.QueryRowContext(ctx, query, key.Name, key.Prefix, key.KeyHash, types.JoinScopes(key.Scopes)).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
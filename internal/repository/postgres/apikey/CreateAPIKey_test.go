@@ -0,0 +1,56 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+
+	newKey := func() *types.APIKey {
+		return &types.APIKey{
+			Name:    "payroll",
+			Prefix:  "a1b2c3d4e5f6",
+			KeyHash: "hash",
+			Scopes:  []string{types.ScopeWalletRead, types.ScopeWalletWrite},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		createdAt := time.Now()
+		mock.ExpectQuery(`INSERT INTO api_keys \(name, prefix, key_hash, scopes\)\s+VALUES \(\$1, \$2, \$3, \$4\)\s+RETURNING id, created_at`).
+			WithArgs("payroll", "a1b2c3d4e5f6", "hash", "wallet:read wallet:write").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, createdAt))
+
+		key := newKey()
+		err := repo.CreateAPIKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), key.ID)
+		assert.Equal(t, createdAt, key.CreatedAt)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO api_keys`).
+			WillReturnError(assert.AnError)
+
+		err := repo.CreateAPIKey(ctx, newKey())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create api key")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
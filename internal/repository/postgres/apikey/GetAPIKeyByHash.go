@@ -0,0 +1,34 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.APIKey, error) {
+	var (
+		key    types.APIKey
+		scopes string
+	)
+
+	query := `
+        SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at
+        FROM api_keys WHERE key_hash = $1
+    `
+	err := r.db.QueryRowContext(ctx, query, keyHash).Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+		&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, types.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	key.Scopes = types.SplitScopes(scopes)
+
+	return &key, nil
+}
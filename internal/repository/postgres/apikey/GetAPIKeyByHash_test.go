@@ -0,0 +1,67 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	columns := []string{"id", "name", "prefix", "key_hash", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+	t.Run("success", func(t *testing.T) {
+		createdAt := time.Now()
+		mock.ExpectQuery(`SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at\s+FROM api_keys WHERE key_hash = \$1`).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, "payroll", "a1b2c3d4e5f6", "hash", "wallet:read wallet:write", createdAt, nil, nil))
+
+		key, err := repo.GetAPIKeyByHash(ctx, "hash")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), key.ID)
+		assert.Equal(t, "payroll", key.Name)
+		assert.Equal(t, []string{types.ScopeWalletRead, types.ScopeWalletWrite}, key.Scopes)
+		assert.Nil(t, key.LastUsedAt)
+		assert.False(t, key.Revoked())
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at\s+FROM api_keys WHERE key_hash = \$1`).
+			WithArgs("hash").
+			WillReturnError(sql.ErrNoRows)
+
+		key, err := repo.GetAPIKeyByHash(ctx, "hash")
+		require.Error(t, err)
+		assert.Nil(t, key)
+		assert.Equal(t, types.ErrAPIKeyNotFound, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at\s+FROM api_keys WHERE key_hash = \$1`).
+			WithArgs("hash").
+			WillReturnError(assert.AnError)
+
+		key, err := repo.GetAPIKeyByHash(ctx, "hash")
+		require.Error(t, err)
+		assert.Nil(t, key)
+		assert.Contains(t, err.Error(), "failed to get api key")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
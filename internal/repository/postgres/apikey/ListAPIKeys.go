@@ -0,0 +1,42 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+func (r *Repository) ListAPIKeys(ctx context.Context) ([]*types.APIKey, error) {
+	query := `
+        SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at
+        FROM api_keys ORDER BY id
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*types.APIKey
+	for rows.Next() {
+		var (
+			key    types.APIKey
+			scopes string
+		)
+		if err := rows.Scan(
+			&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+			&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to list api keys: %w", err)
+		}
+		key.Scopes = types.SplitScopes(scopes)
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,52 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ListAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	columns := []string{"id", "name", "prefix", "key_hash", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+	t.Run("success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(`SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at\s+FROM api_keys ORDER BY id`).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "payroll", "a1b2c3d4e5f6", "hash1", "wallet:write", now, now, nil).
+				AddRow(2, "ops", "b2c3d4e5f6a1", "hash2", "admin", now, nil, now))
+
+		keys, err := repo.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "payroll", keys[0].Name)
+		assert.NotNil(t, keys[0].LastUsedAt)
+		assert.False(t, keys[0].Revoked())
+		assert.Equal(t, []string{"admin"}, keys[1].Scopes)
+		assert.True(t, keys[1].Revoked())
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at\s+FROM api_keys ORDER BY id`).
+			WillReturnError(assert.AnError)
+
+		keys, err := repo.ListAPIKeys(ctx)
+		require.Error(t, err)
+		assert.Nil(t, keys)
+		assert.Contains(t, err.Error(), "failed to list api keys")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
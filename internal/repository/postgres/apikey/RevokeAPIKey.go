@@ -0,0 +1,24 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// RevokeAPIKey marks the key as revoked. Revoking a revoked key keeps the
+// original revocation time.
+func (r *Repository) RevokeAPIKey(ctx context.Context, id int64) error {
+	query := "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1"
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at = COALESCE\(revoked_at, NOW\(\)\) WHERE id = \$1`).
+			WithArgs(int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.RevokeAPIKey(ctx, 7))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+			WithArgs(int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RevokeAPIKey(ctx, 7)
+		assert.Equal(t, types.ErrAPIKeyNotFound, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+			WithArgs(int64(7)).
+			WillReturnError(assert.AnError)
+
+		err := repo.RevokeAPIKey(ctx, 7)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to revoke api key")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,20 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"fmt"
+)
+
+// TouchAPIKey records that the key was used. To avoid a write on every request
+// last_used_at is only moved forward once a minute.
+func (r *Repository) TouchAPIKey(ctx context.Context, id int64) error {
+	query := `
+        UPDATE api_keys SET last_used_at = NOW()
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+    `
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package apikeypostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_TouchAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET last_used_at = NOW\(\)\s+WHERE id = \$1 AND \(last_used_at IS NULL OR last_used_at < NOW\(\) - INTERVAL '1 minute'\)`).
+			WithArgs(int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.TouchAPIKey(ctx, 7))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET last_used_at`).
+			WithArgs(int64(7)).
+			WillReturnError(assert.AnError)
+
+		err := repo.TouchAPIKey(ctx, 7)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to touch api key")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
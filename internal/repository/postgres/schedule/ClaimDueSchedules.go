@@ -0,0 +1,48 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// ClaimDueSchedules picks up to limit active schedules that are due and locks
+// them for lease, so that other executors skip them while they run. A
+// schedule whose run is not recorded before the lease expires is picked up
+// again.
+func (r *Repository) ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]*types.Schedule, error) {
+	query := `
+        UPDATE scheduled_operations SET locked_until = NOW() + $2 * INTERVAL '1 millisecond'
+        WHERE id IN (
+            SELECT id FROM scheduled_operations
+            WHERE status = 'ACTIVE' AND next_run_at <= NOW()
+                AND (locked_until IS NULL OR locked_until <= NOW())
+            ORDER BY next_run_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + scheduleColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*types.Schedule, 0)
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim schedules: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim schedules: %w", err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,40 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ClaimDueSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `UPDATE scheduled_operations SET locked_until = NOW\(\) \+ \$2 \* INTERVAL '1 millisecond'\s+WHERE id IN \(.+FOR UPDATE SKIP LOCKED\s+\)\s+RETURNING`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(100, int64(60000)).
+			WillReturnRows(scheduleRows(recurringScheduleRow()))
+
+		schedules, err := repo.ClaimDueSchedules(ctx, 100, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, testScheduleID, schedules[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.ClaimDueSchedules(ctx, 100, time.Minute)
+		assert.ErrorContains(t, err, "failed to claim schedules")
+	})
+}
@@ -0,0 +1,33 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreateSchedule stores s and fills in its ID and creation time. It fails with
+// types.ErrWalletNotFound if the wallet does not exist.
+func (r *Repository) CreateSchedule(ctx context.Context, s *types.Schedule) error {
+	query := `
+        INSERT INTO scheduled_operations
+            (wallet_uuid, operation_type, amount, run_at, recurrence, status, next_run_at, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `
+	err := r.db.QueryRowContext(ctx, query,
+		s.WalletUUID, s.Operation, s.Amount, s.RunAt, nullString(s.Recurrence), s.Status, s.NextRunAt, nullString(s.CreatedBy),
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return types.ErrWalletNotFound
+		}
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CreateSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `INSERT INTO scheduled_operations\s+\(wallet_uuid, operation_type, amount, run_at, recurrence, status, next_run_at, created_by\)`
+
+	newSchedule := func() *types.Schedule {
+		next := testTime
+		return &types.Schedule{
+			WalletUUID: testWalletUUID,
+			Operation:  types.OperationTypeWithdraw,
+			Amount:     500,
+			Recurrence: "0 0 1 * *",
+			Status:     types.ScheduleStatusActive,
+			NextRunAt:  &next,
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		s := newSchedule()
+		mock.ExpectQuery(query).
+			WithArgs(testWalletUUID, "WITHDRAW", 500, nil, sql.NullString{String: "0 0 1 * *", Valid: true}, "ACTIVE", s.NextRunAt, sql.NullString{}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(testScheduleID, testTime))
+
+		require.NoError(t, repo.CreateSchedule(ctx, s))
+		assert.Equal(t, testScheduleID, s.ID)
+		assert.Equal(t, testTime, s.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "scheduled_operations_wallet_uuid_fkey"})
+
+		assert.Equal(t, types.ErrWalletNotFound, repo.CreateSchedule(ctx, newSchedule()))
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		assert.ErrorContains(t, repo.CreateSchedule(ctx, newSchedule()), "failed to create schedule")
+	})
+}
@@ -0,0 +1,24 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+func (r *Repository) GetSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM scheduled_operations WHERE id = $1`
+
+	s, err := scanSchedule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,46 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `SELECT\s+id, wallet_uuid, .+ FROM scheduled_operations WHERE id = \$1`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(testScheduleID).
+			WillReturnRows(scheduleRows(recurringScheduleRow()))
+
+		s, err := repo.GetSchedule(ctx, testScheduleID)
+		require.NoError(t, err)
+		assert.Equal(t, testScheduleID, s.ID)
+		assert.Equal(t, "0 0 1 * *", s.Recurrence)
+		assert.Nil(t, s.RunAt)
+		assert.Equal(t, testTime, *s.NextRunAt)
+		assert.Equal(t, "user-1", s.CreatedBy)
+		assert.Empty(t, s.LastError)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(testScheduleID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetSchedule(ctx, testScheduleID)
+		assert.Equal(t, types.ErrScheduleNotFound, err)
+	})
+}
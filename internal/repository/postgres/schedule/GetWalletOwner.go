@@ -0,0 +1,25 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// GetWalletOwner returns the owner of a wallet, empty if it has none.
+func (r *Repository) GetWalletOwner(ctx context.Context, walletUUID string) (string, error) {
+	var ownerID sql.NullString
+
+	query := "SELECT owner_id FROM wallet WHERE wallet_uuid = $1"
+	if err := r.db.QueryRowContext(ctx, query, walletUUID).Scan(&ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", types.ErrWalletNotFound
+		}
+		return "", fmt.Errorf("failed to get wallet owner: %w", err)
+	}
+
+	return ownerID.String, nil
+}
@@ -0,0 +1,47 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetWalletOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `SELECT owner_id FROM wallet WHERE wallet_uuid = \$1`
+
+	t.Run("owned", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(testWalletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("user-1"))
+
+		owner, err := repo.GetWalletOwner(ctx, testWalletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", owner)
+	})
+
+	t.Run("no owner", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(testWalletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow(nil))
+
+		owner, err := repo.GetWalletOwner(ctx, testWalletUUID)
+		require.NoError(t, err)
+		assert.Empty(t, owner)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(testWalletUUID).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetWalletOwner(ctx, testWalletUUID)
+		assert.Equal(t, types.ErrWalletNotFound, err)
+	})
+}
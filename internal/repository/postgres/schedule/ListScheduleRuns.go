@@ -0,0 +1,44 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// ListScheduleRuns returns the recorded runs of a schedule, latest first.
+func (r *Repository) ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]types.ScheduleRun, error) {
+	query := `
+        SELECT schedule_id, occurrence, reference_id, status, error, created_at
+        FROM scheduled_operation_runs
+        WHERE schedule_id = $1
+        ORDER BY id DESC
+        LIMIT $2
+    `
+	rows, err := r.db.QueryContext(ctx, query, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]types.ScheduleRun, 0)
+	for rows.Next() {
+		var (
+			run    types.ScheduleRun
+			runErr sql.NullString
+		)
+		if err := rows.Scan(&run.ScheduleID, &run.Occurrence, &run.ReferenceID, &run.Status, &runErr, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+		}
+		run.Error = runErr.String
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+	}
+
+	return runs, nil
+}
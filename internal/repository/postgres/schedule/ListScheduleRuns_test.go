@@ -0,0 +1,34 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ListScheduleRuns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	query := `SELECT schedule_id, occurrence, reference_id, status, error, created_at\s+FROM scheduled_operation_runs\s+WHERE schedule_id = \$1\s+ORDER BY id DESC\s+LIMIT \$2`
+
+	mock.ExpectQuery(query).
+		WithArgs(testScheduleID, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "occurrence", "reference_id", "status", "error", "created_at"}).
+			AddRow(testScheduleID, testTime, "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", "FAILED", "insufficient funds", testTime).
+			AddRow(testScheduleID, testTime, "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", "APPLIED", nil, testTime))
+
+	runs, err := repo.ListScheduleRuns(context.Background(), testScheduleID, 50)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ScheduleRun{
+		{ScheduleID: testScheduleID, Occurrence: testTime, ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", Status: "FAILED", Error: "insufficient funds", CreatedAt: testTime},
+		{ScheduleID: testScheduleID, Occurrence: testTime, ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", Status: "APPLIED", CreatedAt: testTime},
+	}, runs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,36 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// ListSchedules returns the schedules of a wallet, newest first.
+func (r *Repository) ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM scheduled_operations
+        WHERE wallet_uuid = $1
+        ORDER BY created_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, walletUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*types.Schedule, 0)
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schedules: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	return schedules, nil
+}
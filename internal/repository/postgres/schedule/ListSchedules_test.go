@@ -0,0 +1,55 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ListSchedules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `SELECT\s+id, wallet_uuid, .+ FROM scheduled_operations\s+WHERE wallet_uuid = \$1\s+ORDER BY created_at DESC, id`
+
+	t.Run("success", func(t *testing.T) {
+		oneOff := recurringScheduleRow()
+		oneOff[0] = "8e2d3b4c-5f6a-4b7c-9d8e-0f1a2b3c4d5e"
+		oneOff[4], oneOff[5] = testTime, nil
+
+		mock.ExpectQuery(query).
+			WithArgs(testWalletUUID).
+			WillReturnRows(scheduleRows(recurringScheduleRow(), oneOff))
+
+		schedules, err := repo.ListSchedules(ctx, testWalletUUID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		assert.Equal(t, "0 0 1 * *", schedules[0].Recurrence)
+		assert.Equal(t, testTime, *schedules[1].RunAt)
+		assert.Empty(t, schedules[1].Recurrence)
+	})
+
+	t.Run("no schedules", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(testWalletUUID).
+			WillReturnRows(scheduleRows())
+
+		schedules, err := repo.ListSchedules(ctx, testWalletUUID)
+		require.NoError(t, err)
+		assert.NotNil(t, schedules)
+		assert.Empty(t, schedules)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.ListSchedules(ctx, testWalletUUID)
+		assert.ErrorContains(t, err, "failed to list schedules")
+	})
+}
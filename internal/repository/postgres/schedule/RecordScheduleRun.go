@@ -0,0 +1,51 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// RecordScheduleRun stores the outcome of a run, if there is one, together
+// with the new state of the schedule and releases its lock. The schedule is
+// not picked up again before retryAt, if set. A schedule that was paused or
+// cancelled while it ran keeps its status, and one whose recurrence was
+// changed or that was cancelled keeps its next run.
+func (r *Repository) RecordScheduleRun(ctx context.Context, s *types.Schedule, run *types.ScheduleRun, retryAt *time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if run != nil {
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO scheduled_operation_runs (schedule_id, occurrence, reference_id, status, error)
+            VALUES ($1, $2, $3, $4, $5)
+        `, run.ScheduleID, run.Occurrence, run.ReferenceID, run.Status, nullString(run.Error))
+		if err != nil {
+			return fmt.Errorf("failed to record schedule run: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE scheduled_operations
+        SET status = CASE WHEN status = 'ACTIVE' THEN $1 ELSE status END,
+            next_run_at = CASE WHEN status = 'CANCELLED' OR recurrence IS DISTINCT FROM $8
+                THEN next_run_at ELSE $2 END,
+            failures = $3, last_run_at = $4, last_error = $5,
+            locked_until = $6, updated_at = NOW()
+        WHERE id = $7
+    `, s.Status, s.NextRunAt, s.Failures, s.LastRunAt, nullString(s.LastError), retryAt, s.ID, nullString(s.Recurrence))
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
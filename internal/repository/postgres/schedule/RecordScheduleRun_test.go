@@ -0,0 +1,80 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_RecordScheduleRun(t *testing.T) {
+	const referenceID = "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+
+	insertRun := `INSERT INTO scheduled_operation_runs \(schedule_id, occurrence, reference_id, status, error\)`
+	updateSchedule := `UPDATE scheduled_operations\s+SET status = CASE WHEN status = 'ACTIVE' THEN \$1 ELSE status END`
+
+	t.Run("applied run", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		next := testTime.AddDate(0, 1, 0)
+		s := &types.Schedule{ID: testScheduleID, Status: types.ScheduleStatusActive, NextRunAt: &next, LastRunAt: &testTime}
+		run := &types.ScheduleRun{ScheduleID: testScheduleID, Occurrence: testTime, ReferenceID: referenceID, Status: types.ScheduleRunApplied}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(insertRun).
+			WithArgs(testScheduleID, testTime, referenceID, "APPLIED", sql.NullString{}).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(updateSchedule).
+			WithArgs("ACTIVE", &next, 0, &testTime, sql.NullString{}, nil, testScheduleID, sql.NullString{}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.RecordScheduleRun(context.Background(), s, run, nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retry without a run", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		retryAt := testTime.Add(time.Hour)
+		s := &types.Schedule{ID: testScheduleID, Status: types.ScheduleStatusActive, NextRunAt: &testTime}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(updateSchedule).
+			WithArgs("ACTIVE", &testTime, 0, nil, sql.NullString{}, &retryAt, testScheduleID, sql.NullString{}).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.RecordScheduleRun(context.Background(), s, nil, &retryAt))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		s := &types.Schedule{ID: testScheduleID, Status: types.ScheduleStatusActive}
+		run := &types.ScheduleRun{ScheduleID: testScheduleID, Occurrence: testTime, ReferenceID: referenceID, Status: types.ScheduleRunFailed, Error: "insufficient funds"}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(insertRun).WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err = repo.RecordScheduleRun(context.Background(), s, run, nil)
+		assert.ErrorContains(t, err, "failed to record schedule run")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
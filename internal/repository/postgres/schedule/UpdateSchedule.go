@@ -0,0 +1,56 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// UpdateSchedule saves the changes made to a schedule through the API. Only
+// the changed columns are written and the lock is left alone: a schedule
+// being run stays claimed by its executor, and a retry backoff is kept.
+func (r *Repository) UpdateSchedule(ctx context.Context, id string, changes *types.ScheduleChanges) error {
+	assignments := []string{"updated_at = NOW()"}
+	args := []any{}
+	set := func(assignment string, arg any) {
+		args = append(args, arg)
+		assignments = append(assignments, fmt.Sprintf(assignment, len(args)))
+	}
+
+	if changes.Amount != nil {
+		set("amount = $%d", *changes.Amount)
+	}
+	if changes.Recurrence != nil {
+		set("recurrence = $%d", nullString(*changes.Recurrence))
+	}
+	if changes.Status != nil {
+		set("status = $%d", *changes.Status)
+	}
+	if changes.ClearNextRunAt {
+		assignments = append(assignments, "next_run_at = NULL")
+	} else if changes.NextRunAt != nil {
+		set("next_run_at = $%d", *changes.NextRunAt)
+	}
+	if changes.ResetFailures {
+		assignments = append(assignments, "failures = 0")
+	}
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+        UPDATE scheduled_operations
+        SET %s
+        WHERE id = $%d
+    `, strings.Join(assignments, ", "), len(args))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.ErrScheduleNotFound
+	}
+
+	return nil
+}
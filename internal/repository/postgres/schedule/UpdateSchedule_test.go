@@ -0,0 +1,62 @@
+package schedulepostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_UpdateSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+
+	t.Run("changes only given columns", func(t *testing.T) {
+		amount := 700
+		recurrence := "0 0 1 * *"
+		mock.ExpectExec(`UPDATE scheduled_operations\s+SET updated_at = NOW\(\), amount = \$1, recurrence = \$2, next_run_at = \$3\s+WHERE id = \$4`).
+			WithArgs(700, sql.NullString{String: "0 0 1 * *", Valid: true}, testTime, testScheduleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.UpdateSchedule(ctx, testScheduleID, &types.ScheduleChanges{
+			Amount:     &amount,
+			Recurrence: &recurrence,
+			NextRunAt:  &testTime,
+		}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("resume keeps the lock", func(t *testing.T) {
+		active := types.ScheduleStatusActive
+		mock.ExpectExec(`UPDATE scheduled_operations\s+SET updated_at = NOW\(\), status = \$1, failures = 0\s+WHERE id = \$2`).
+			WithArgs("ACTIVE", testScheduleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.UpdateSchedule(ctx, testScheduleID, &types.ScheduleChanges{Status: &active, ResetFailures: true}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cancel clears next run", func(t *testing.T) {
+		cancelled := types.ScheduleStatusCancelled
+		mock.ExpectExec(`UPDATE scheduled_operations\s+SET updated_at = NOW\(\), status = \$1, next_run_at = NULL\s+WHERE id = \$2`).
+			WithArgs("CANCELLED", testScheduleID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.UpdateSchedule(ctx, testScheduleID, &types.ScheduleChanges{Status: &cancelled, ClearNextRunAt: true}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE scheduled_operations`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, types.ErrScheduleNotFound, repo.UpdateSchedule(ctx, testScheduleID, &types.ScheduleChanges{}))
+	})
+}
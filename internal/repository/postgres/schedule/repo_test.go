@@ -0,0 +1,49 @@
+package schedulepostgresql
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepository(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRepository(db)
+	assert.NotNil(t, repo)
+	assert.Equal(t, db, repo.db)
+}
+
+var scheduleColumnNames = []string{
+	"id", "wallet_uuid", "operation_type", "amount", "run_at", "recurrence", "status",
+	"next_run_at", "failures", "last_run_at", "last_error", "created_by", "created_at",
+}
+
+const (
+	testScheduleID = "7d1c2a3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	testWalletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+)
+
+var testTime = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+// recurringScheduleRow is an active monthly schedule due at testTime.
+func recurringScheduleRow() []driver.Value {
+	return []driver.Value{
+		testScheduleID, testWalletUUID, "WITHDRAW", 500, nil, "0 0 1 * *", "ACTIVE",
+		testTime, 0, nil, nil, "user-1", testTime.AddDate(0, -1, 0),
+	}
+}
+
+func scheduleRows(rows ...[]driver.Value) *sqlmock.Rows {
+	result := sqlmock.NewRows(scheduleColumnNames)
+	for _, row := range rows {
+		result.AddRow(row...)
+	}
+	return result
+}
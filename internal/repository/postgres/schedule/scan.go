@@ -0,0 +1,39 @@
+package schedulepostgresql
+
+import (
+	"database/sql"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+const scheduleColumns = `
+        id, wallet_uuid, operation_type, amount, run_at, recurrence, status,
+        next_run_at, failures, last_run_at, last_error, created_by, created_at
+    `
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedule(row rowScanner) (*types.Schedule, error) {
+	var (
+		s                                types.Schedule
+		recurrence, lastError, createdBy sql.NullString
+	)
+	err := row.Scan(
+		&s.ID, &s.WalletUUID, &s.Operation, &s.Amount, &s.RunAt, &recurrence, &s.Status,
+		&s.NextRunAt, &s.Failures, &s.LastRunAt, &lastError, &createdBy, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.Recurrence = recurrence.String
+	s.LastError = lastError.String
+	s.CreatedBy = createdBy.String
+	return &s, nil
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -11,7 +11,7 @@ func (r *Repository) CheckOperationExists(ctx context.Context, referenceID strin
 
 	err := r.db.QueryRowContext(ctx, query, referenceID).Scan(&exists)
 	if err != nil {
-		return false, fmt.Errorf("failed to check operation existence: %w", err)
+		return false, fmt.Errorf("failed to check operation existence: %w", classifyError(err))
 	}
 
 	return exists, nil
@@ -0,0 +1,42 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// CountFailedOperations counts the operations failed since the given moment
+// by failure code, the most frequent first. Synchronous operations that fail
+// are rolled back rather than stored, see FailOperation.
+func (r *Repository) CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error) {
+	query := `
+        SELECT COALESCE(failure_code, 'UNKNOWN') AS code, COUNT(*)
+        FROM wallet_operations
+        WHERE status = 'FAILED' AND applied_at >= $1
+        GROUP BY code
+        ORDER BY COUNT(*) DESC, code
+    `
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count failed operations: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	counts := make([]types.FailureCount, 0)
+	for rows.Next() {
+		var c types.FailureCount
+		if err := rows.Scan(&c.Code, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to count failed operations: %w", classifyError(err))
+		}
+		counts = append(counts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count failed operations: %w", classifyError(err))
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,57 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CountFailedOperations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	since := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	query := `SELECT COALESCE\(failure_code, 'UNKNOWN'\) AS code, COUNT\(\*\)\s+FROM wallet_operations\s+WHERE status = 'FAILED' AND applied_at >= \$1\s+GROUP BY code`
+
+	t.Run("counts", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(since).
+			WillReturnRows(sqlmock.NewRows([]string{"code", "count"}).
+				AddRow(types.FailureInsufficientFunds, 12).
+				AddRow(types.FailureTimeout, 3))
+
+		counts, err := repo.CountFailedOperations(ctx, since)
+		require.NoError(t, err)
+		assert.Equal(t, []types.FailureCount{
+			{Code: types.FailureInsufficientFunds, Count: 12},
+			{Code: types.FailureTimeout, Count: 3},
+		}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing failed", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(since).
+			WillReturnRows(sqlmock.NewRows([]string{"code", "count"}))
+
+		counts, err := repo.CountFailedOperations(ctx, since)
+		require.NoError(t, err)
+		assert.NotNil(t, counts)
+		assert.Empty(t, counts)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.CountFailedOperations(ctx, since)
+		assert.ErrorContains(t, err, "failed to count failed operations")
+	})
+}
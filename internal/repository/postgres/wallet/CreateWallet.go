@@ -0,0 +1,23 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// CreateWallet creates an empty wallet. An empty owner leaves the wallet
+// without one.
+func (r *Repository) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	query := `
+        INSERT INTO wallet (owner_id, credit_limit)
+        VALUES (NULLIF($1, ''), $2)
+        RETURNING ` + walletColumns
+
+	wallet, err := scanWallet(r.db.QueryRowContext(ctx, query, owner, creditLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet: %w", classifyError(err))
+	}
+	return wallet, nil
+}
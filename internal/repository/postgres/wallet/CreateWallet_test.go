@@ -0,0 +1,43 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_CreateWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := `INSERT INTO wallet \(owner_id, credit_limit\)\s+VALUES \(NULLIF\(\$1, ''\), \$2\)\s+RETURNING wallet_uuid`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs("user-1", 100).
+			WillReturnRows(sqlmock.NewRows(walletColumnNames).AddRow(walletUUID, 0, 100, "user-1", false, created, created))
+
+		wallet, err := repo.CreateWallet(ctx, "user-1", 100)
+		require.NoError(t, err)
+		assert.Equal(t, walletUUID, wallet.WalletUUID)
+		assert.Equal(t, 100, wallet.Available)
+		assert.Equal(t, "user-1", wallet.Owner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.CreateWallet(ctx, "", 0)
+		assert.ErrorContains(t, err, "failed to create wallet")
+	})
+}
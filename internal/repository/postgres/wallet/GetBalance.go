@@ -5,21 +5,25 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
 	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
 )
 
-func (r *Repository) GetBalance(ctx context.Context, walletUUID string) (int, error) {
-	var balance int
+func (r *Repository) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
+	var balance, creditLimit int
 
-	query := "SELECT balance FROM wallet WHERE wallet_uuid = $1"
-	err := r.db.QueryRowContext(ctx, query, walletUUID).Scan(&balance)
+	query := "SELECT balance, credit_limit FROM wallet WHERE wallet_uuid = $1"
+	err := r.db.QueryRowContext(ctx, query, walletUUID).Scan(&balance, &creditLimit)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return 0, types.ErrWalletNotFound
+			return types.Funds{}, types.ErrWalletNotFound
 		}
-		return 0, fmt.Errorf("failed to get balance: %w", err)
+		return types.Funds{}, fmt.Errorf("failed to get balance: %w", classifyError(err))
 	}
 
-	return balance, nil
+	logger.FromContext(ctx, zap.NewNop()).Debug("balance loaded",
+		zap.String("wallet_uuid", walletUUID))
+	return types.NewFunds(balance, creditLimit), nil
 }
@@ -0,0 +1,61 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// balanceAtQuery computes the balance of every wallet at $1: the balance after
+// the last operation applied by then, counted from the latest end-of-day
+// snapshot taken before then. Without either, it is the balance before the first
+// operation applied later or, for a wallet without operations, the current one.
+// Operations are ordered by applied_at, which is taken under the wallet lock,
+// see applyOperation.
+const balanceAtQuery = `
+        SELECT w.wallet_uuid, COALESCE(
+            (SELECT o.balance_after FROM wallet_operations o
+                WHERE o.wallet_id = w.wallet_uuid AND o.status = 'APPLIED'
+                    AND o.applied_at <= $1 AND o.applied_at > COALESCE(s.as_of, '-infinity')
+                ORDER BY o.applied_at DESC, o.id DESC
+                LIMIT 1),
+            s.balance,
+            (SELECT o.balance_after - CASE o.operation_type
+                    WHEN 'WITHDRAW' THEN -(o.amount + COALESCE(
+                        (SELECT f.amount FROM wallet_operations f WHERE f.parent_reference_id = o.reference_id), 0))
+                    ELSE o.amount
+                END
+                FROM wallet_operations o
+                WHERE o.wallet_id = w.wallet_uuid AND o.status = 'APPLIED' AND o.applied_at > $1
+                ORDER BY o.applied_at, o.id
+                LIMIT 1),
+            w.balance) AS balance
+        FROM wallet w
+        LEFT JOIN LATERAL (
+            SELECT balance, as_of FROM wallet_balance_snapshots
+            WHERE wallet_uuid = w.wallet_uuid AND as_of < $1
+            ORDER BY day DESC
+            LIMIT 1
+        ) s ON TRUE
+    `
+
+// GetBalanceAt returns the balance the wallet had at the given time.
+func (r *Repository) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error) {
+	var (
+		uuid    string
+		balance int
+	)
+	err := r.db.QueryRowContext(ctx, balanceAtQuery+`WHERE w.wallet_uuid = $2`, at, walletUUID).
+		Scan(&uuid, &balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, types.ErrWalletNotFound
+		}
+		return 0, fmt.Errorf("failed to get balance at %s: %w", at.Format(time.RFC3339), classifyError(err))
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,53 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetBalanceAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	at := time.Date(2026, 9, 30, 23, 59, 0, 0, time.UTC)
+	query := `SELECT w.wallet_uuid, COALESCE\(.*FROM wallet w\s+LEFT JOIN LATERAL \(.*wallet_balance_snapshots.*\) s ON TRUE\s+WHERE w.wallet_uuid = \$2`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(at, walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance"}).AddRow(walletUUID, 750))
+
+		balance, err := repo.GetBalanceAt(ctx, walletUUID, at)
+		require.NoError(t, err)
+		assert.Equal(t, 750, balance)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(at, walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance"}))
+
+		_, err := repo.GetBalanceAt(ctx, walletUUID, at)
+		assert.Equal(t, types.ErrWalletNotFound, err)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(at, walletUUID).
+			WillReturnError(assert.AnError)
+
+		_, err := repo.GetBalanceAt(ctx, walletUUID, at)
+		assert.ErrorContains(t, err, "failed to get balance at 2026-09-30T23:59:00Z")
+	})
+}
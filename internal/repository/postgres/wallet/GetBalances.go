@@ -0,0 +1,38 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// GetBalances returns the balances of the given wallets keyed by wallet UUID.
+// Wallets that do not exist are absent from the result.
+func (r *Repository) GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error) {
+	balances := make(map[string]types.Funds, len(walletUUIDs))
+
+	query := "SELECT wallet_uuid, balance, credit_limit FROM wallet WHERE wallet_uuid = ANY($1)"
+	rows, err := r.db.QueryContext(ctx, query, walletUUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			walletUUID           string
+			balance, creditLimit int
+		)
+		if err := rows.Scan(&walletUUID, &balance, &creditLimit); err != nil {
+			return nil, fmt.Errorf("failed to get balances: %w", classifyError(err))
+		}
+		balances[walletUUID] = types.NewFunds(balance, creditLimit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", classifyError(err))
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,66 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetBalances(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUIDs := []string{
+		"a1b2c3e4-5678-9012-3456-789012345678",
+		"b2c3d4e5-6789-0123-4567-890123456789",
+	}
+
+	t.Run("success - missing wallets are absent", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"wallet_uuid", "balance", "credit_limit"}).
+			AddRow("a1b2c3e4-5678-9012-3456-789012345678", 500, 100)
+		mock.ExpectQuery(`SELECT wallet_uuid, balance, credit_limit FROM wallet WHERE wallet_uuid = ANY\(\$1\)`).
+			WithArgs(walletUUIDs).
+			WillReturnRows(rows)
+
+		balances, err := repo.GetBalances(ctx, walletUUIDs)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]types.Funds{"a1b2c3e4-5678-9012-3456-789012345678": types.NewFunds(500, 100)}, balances)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT wallet_uuid, balance, credit_limit FROM wallet WHERE wallet_uuid = ANY\(\$1\)`).
+			WithArgs(walletUUIDs).
+			WillReturnError(assert.AnError)
+
+		balances, err := repo.GetBalances(ctx, walletUUIDs)
+		require.Error(t, err)
+		assert.Nil(t, balances)
+		assert.Contains(t, err.Error(), "failed to get balances")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"wallet_uuid", "balance", "credit_limit"}).
+			AddRow("a1b2c3e4-5678-9012-3456-789012345678", "not_a_number", 0)
+		mock.ExpectQuery(`SELECT wallet_uuid, balance, credit_limit FROM wallet WHERE wallet_uuid = ANY\(\$1\)`).
+			WithArgs(walletUUIDs).
+			WillReturnRows(rows)
+
+		balances, err := repo.GetBalances(ctx, walletUUIDs)
+		require.Error(t, err)
+		assert.Nil(t, balances)
+		assert.Contains(t, err.Error(), "failed to get balances")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
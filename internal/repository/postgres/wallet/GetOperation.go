@@ -0,0 +1,47 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+func (r *Repository) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	query := `
+        SELECT id, wallet_id, operation_type, amount, reference_id, COALESCE(parent_reference_id::text, ''),
+            status, balance_after, created_at, applied_at, async, attempts,
+            COALESCE(failure_code, ''), COALESCE(failure_message, ''), ` + operationDetailsColumns + `
+        FROM wallet_operations
+        WHERE reference_id = $1
+    `
+	var (
+		op           types.Operation
+		balanceAfter sql.NullInt64
+		appliedAt    sql.NullTime
+		metadata     string
+	)
+	err := r.db.QueryRowContext(ctx, query, referenceID).Scan(
+		&op.ID, &op.WalletUUID, &op.Operation, &op.Amount, &op.ReferenceID, &op.ParentReferenceID,
+		&op.Status, &balanceAfter, &op.CreatedAt, &appliedAt, &op.Async, &op.Attempts,
+		&op.FailureCode, &op.FailureMessage, &op.Description, &op.ExternalRef, &metadata)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, types.ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get operation: %w", classifyError(err))
+	}
+
+	if err := scanDetails(&op.OperationDetails, metadata); err != nil {
+		return nil, err
+	}
+	if balanceAfter.Valid {
+		balance := int(balanceAfter.Int64)
+		op.BalanceAfter = &balance
+	}
+	if appliedAt.Valid {
+		op.AppliedAt = &appliedAt.Time
+	}
+	return &op, nil
+}
@@ -0,0 +1,103 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetOperation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	referenceID := "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "wallet_id", "operation_type", "amount", "reference_id", "parent_reference_id",
+		"status", "balance_after", "created_at", "applied_at", "async", "attempts", "failure_code", "failure_message",
+		"description", "external_ref", "metadata"}
+	query := `SELECT id, wallet_id, operation_type, amount, reference_id, .+FROM wallet_operations\s+WHERE reference_id = \$1`
+
+	t.Run("applied", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(referenceID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, walletUUID, "DEPOSIT", 100, referenceID, "", "APPLIED", 600, created, created, false, 0, "", "", "", "", ""))
+
+		op, err := repo.GetOperation(ctx, referenceID)
+		require.NoError(t, err)
+		balance := 600
+		assert.Equal(t, &types.Operation{
+			ID:           7,
+			WalletUUID:   walletUUID,
+			Operation:    "DEPOSIT",
+			Amount:       100,
+			ReferenceID:  referenceID,
+			Status:       "APPLIED",
+			BalanceAfter: &balance,
+			CreatedAt:    created,
+			AppliedAt:    &created,
+		}, op)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(referenceID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, walletUUID, "DEPOSIT", 100, referenceID, "", "PENDING", nil, created, nil, true, 2, "", "", "", "", ""))
+
+		op, err := repo.GetOperation(ctx, referenceID)
+		require.NoError(t, err)
+		assert.Nil(t, op.BalanceAfter)
+		assert.Nil(t, op.AppliedAt)
+		assert.True(t, op.Async)
+		assert.Equal(t, 2, op.Attempts)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(referenceID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, walletUUID, "WITHDRAW", 100, referenceID, "", "FAILED", nil, created, created, true, 1, "INSUFFICIENT_FUNDS", "insufficient funds", "", "", ""))
+
+		op, err := repo.GetOperation(ctx, referenceID)
+		require.NoError(t, err)
+		assert.Equal(t, "FAILED", op.Status)
+		assert.Equal(t, types.FailureInsufficientFunds, op.FailureCode)
+		assert.Equal(t, "insufficient funds", op.FailureMessage)
+	})
+
+	t.Run("with details", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(referenceID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, walletUUID, "DEPOSIT", 100, referenceID, "", "APPLIED", 600, created, created, false, 0, "", "",
+					"invoice payment", "INV-1", `{"order": "42"}`))
+
+		op, err := repo.GetOperation(ctx, referenceID)
+		require.NoError(t, err)
+		assert.Equal(t, types.OperationDetails{
+			Description: "invoice payment",
+			ExternalRef: "INV-1",
+			Metadata:    map[string]string{"order": "42"},
+		}, op.OperationDetails)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(referenceID).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetOperation(ctx, referenceID)
+		assert.Equal(t, types.ErrOperationNotFound, err)
+	})
+}
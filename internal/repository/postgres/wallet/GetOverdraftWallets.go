@@ -0,0 +1,44 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// GetOverdraftWallets returns the wallets with a negative balance, deepest
+// overdraft first.
+func (r *Repository) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	query := `
+        SELECT wallet_uuid, balance, credit_limit FROM wallet
+        WHERE balance < 0
+        ORDER BY balance, wallet_uuid
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdraft wallets: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	wallets := make([]types.OverdraftWallet, 0)
+	for rows.Next() {
+		var (
+			walletUUID           string
+			balance, creditLimit int
+		)
+		if err := rows.Scan(&walletUUID, &balance, &creditLimit); err != nil {
+			return nil, fmt.Errorf("failed to get overdraft wallets: %w", classifyError(err))
+		}
+		wallets = append(wallets, types.OverdraftWallet{
+			WalletUUID: walletUUID,
+			Funds:      types.NewFunds(balance, creditLimit),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get overdraft wallets: %w", classifyError(err))
+	}
+
+	return wallets, nil
+}
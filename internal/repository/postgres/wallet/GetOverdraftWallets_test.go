@@ -0,0 +1,53 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetOverdraftWallets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `SELECT wallet_uuid, balance, credit_limit FROM wallet\s+WHERE balance < 0\s+ORDER BY balance, wallet_uuid`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance", "credit_limit"}).
+				AddRow("a1b2c3e4-5678-9012-3456-789012345678", -500, 1000).
+				AddRow("b2c3d4e5-6789-0123-4567-890123456789", -10, 100))
+
+		wallets, err := repo.GetOverdraftWallets(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []types.OverdraftWallet{
+			{WalletUUID: "a1b2c3e4-5678-9012-3456-789012345678", Funds: types.NewFunds(-500, 1000)},
+			{WalletUUID: "b2c3d4e5-6789-0123-4567-890123456789", Funds: types.NewFunds(-10, 100)},
+		}, wallets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no wallets in overdraft", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance", "credit_limit"}))
+
+		wallets, err := repo.GetOverdraftWallets(ctx)
+		require.NoError(t, err)
+		assert.NotNil(t, wallets)
+		assert.Empty(t, wallets)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.GetOverdraftWallets(ctx)
+		assert.ErrorContains(t, err, "failed to get overdraft wallets")
+	})
+}
@@ -0,0 +1,37 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+const walletColumns = `wallet_uuid, balance, credit_limit, COALESCE(owner_id, ''), frozen, created_at, updated_at`
+
+func scanWallet(row *sql.Row) (*types.Wallet, error) {
+	var (
+		wallet               types.Wallet
+		balance, creditLimit int
+	)
+	err := row.Scan(&wallet.WalletUUID, &balance, &creditLimit, &wallet.Owner, &wallet.Frozen, &wallet.CreatedAt, &wallet.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	wallet.Funds = types.NewFunds(balance, creditLimit)
+	return &wallet, nil
+}
+
+func (r *Repository) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	query := `SELECT ` + walletColumns + ` FROM wallet WHERE wallet_uuid = $1`
+
+	wallet, err := scanWallet(r.db.QueryRowContext(ctx, query, walletUUID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, types.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet: %w", classifyError(err))
+	}
+	return wallet, nil
+}
@@ -0,0 +1,47 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// GetWalletLimits returns the tier, effective limits, overrides and current
+// withdrawal totals of a wallet.
+func (r *Repository) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	limits := &types.WalletLimits{WalletUUID: walletUUID}
+
+	query := `
+        SELECT COALESCE(l.tier, 'standard'),
+            COALESCE(l.max_withdrawal, t.max_withdrawal),
+            COALESCE(l.daily_withdrawal, t.daily_withdrawal),
+            COALESCE(l.monthly_withdrawal, t.monthly_withdrawal),
+            COALESCE(l.max_balance, t.max_balance),
+            l.max_withdrawal, l.daily_withdrawal, l.monthly_withdrawal, l.max_balance
+        FROM wallet w
+        LEFT JOIN wallet_limits l ON l.wallet_uuid = w.wallet_uuid
+        LEFT JOIN wallet_limit_tiers t ON t.tier = COALESCE(l.tier, 'standard')
+        WHERE w.wallet_uuid = $1
+    `
+	err := r.db.QueryRowContext(ctx, query, walletUUID).Scan(
+		&limits.Tier,
+		&limits.Limits.MaxWithdrawal, &limits.Limits.DailyWithdrawal,
+		&limits.Limits.MonthlyWithdrawal, &limits.Limits.MaxBalance,
+		&limits.Overrides.MaxWithdrawal, &limits.Overrides.DailyWithdrawal,
+		&limits.Overrides.MonthlyWithdrawal, &limits.Overrides.MaxBalance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, types.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet limits: %w", classifyError(err))
+	}
+
+	err = r.db.QueryRowContext(ctx, withdrawnQuery, walletUUID).Scan(&limits.DailyWithdrawn, &limits.MonthlyWithdrawn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum withdrawals: %w", classifyError(err))
+	}
+
+	return limits, nil
+}
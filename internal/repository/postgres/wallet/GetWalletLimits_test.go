@@ -0,0 +1,60 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetWalletLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	limitsPattern := `SELECT COALESCE\(l.tier, 'standard'\),.+FROM wallet w.+WHERE w.wallet_uuid = \$1`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(limitsPattern).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"tier", "max_withdrawal", "daily_withdrawal", "monthly_withdrawal", "max_balance",
+				"o_max_withdrawal", "o_daily_withdrawal", "o_monthly_withdrawal", "o_max_balance",
+			}).AddRow("gold", 500, 2000, nil, nil, nil, 2000, nil, nil))
+		mock.ExpectQuery(`FROM wallet_operations`).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"daily", "monthly"}).AddRow(100, 700))
+
+		limits, err := repo.GetWalletLimits(ctx, walletUUID)
+		require.NoError(t, err)
+
+		maxWithdrawal, daily := 500, 2000
+		assert.Equal(t, &types.WalletLimits{
+			WalletUUID:       walletUUID,
+			Tier:             "gold",
+			Limits:           types.LimitValues{MaxWithdrawal: &maxWithdrawal, DailyWithdrawal: &daily},
+			Overrides:        types.LimitValues{DailyWithdrawal: &daily},
+			DailyWithdrawn:   100,
+			MonthlyWithdrawn: 700,
+		}, limits)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(limitsPattern).
+			WithArgs(walletUUID).
+			WillReturnError(sql.ErrNoRows)
+
+		limits, err := repo.GetWalletLimits(ctx, walletUUID)
+		assert.Equal(t, types.ErrWalletNotFound, err)
+		assert.Nil(t, limits)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
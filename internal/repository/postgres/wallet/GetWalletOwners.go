@@ -0,0 +1,33 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetWalletOwners returns the owners of the given wallets keyed by wallet
+// UUID. Wallets that do not exist or have no owner are absent from the result.
+func (r *Repository) GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error) {
+	owners := make(map[string]string, len(walletUUIDs))
+
+	query := "SELECT wallet_uuid, owner_id FROM wallet WHERE wallet_uuid = ANY($1) AND owner_id IS NOT NULL"
+	rows, err := r.db.QueryContext(ctx, query, walletUUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet owners: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var walletUUID, ownerID string
+		if err := rows.Scan(&walletUUID, &ownerID); err != nil {
+			return nil, fmt.Errorf("failed to get wallet owners: %w", classifyError(err))
+		}
+		owners[walletUUID] = ownerID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get wallet owners: %w", classifyError(err))
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,51 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GetWalletOwners(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUIDs := []string{
+		"a1b2c3e4-5678-9012-3456-789012345678",
+		"b2c3d4e5-6789-0123-4567-890123456789",
+	}
+	query := `SELECT wallet_uuid, owner_id FROM wallet WHERE wallet_uuid = ANY\(\$1\) AND owner_id IS NOT NULL`
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"wallet_uuid", "owner_id"}).
+			AddRow("a1b2c3e4-5678-9012-3456-789012345678", "user-1")
+		mock.ExpectQuery(query).
+			WithArgs(walletUUIDs).
+			WillReturnRows(rows)
+
+		owners, err := repo.GetWalletOwners(ctx, walletUUIDs)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a1b2c3e4-5678-9012-3456-789012345678": "user-1"}, owners)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(walletUUIDs).
+			WillReturnError(assert.AnError)
+
+		owners, err := repo.GetWalletOwners(ctx, walletUUIDs)
+		require.Error(t, err)
+		assert.Nil(t, owners)
+		assert.Contains(t, err.Error(), "failed to get wallet owners")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,60 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var walletColumnNames = []string{"wallet_uuid", "balance", "credit_limit", "owner_id", "frozen", "created_at", "updated_at"}
+
+func TestRepository_GetWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := `SELECT wallet_uuid, balance, credit_limit, COALESCE\(owner_id, ''\), frozen, created_at, updated_at FROM wallet WHERE wallet_uuid = \$1`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows(walletColumnNames).AddRow(walletUUID, -100, 500, "user-1", true, created, created))
+
+		wallet, err := repo.GetWallet(ctx, walletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Wallet{
+			WalletUUID: walletUUID,
+			Funds:      types.NewFunds(-100, 500),
+			Owner:      "user-1",
+			Frozen:     true,
+			CreatedAt:  created,
+			UpdatedAt:  created,
+		}, wallet)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows(walletColumnNames))
+
+		_, err := repo.GetWallet(ctx, walletUUID)
+		assert.Equal(t, types.ErrWalletNotFound, err)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.GetWallet(ctx, walletUUID)
+		assert.ErrorContains(t, err, "failed to get wallet")
+	})
+}
@@ -0,0 +1,114 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// ListOperations returns up to filter.Limit operations of the wallet that
+// match the filter, the newest first. The metadata filter is served by the GIN
+// index on metadata: pairs with a value are matched by containment, bare keys
+// by key existence.
+func (r *Repository) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error) {
+	conditions := []string{"wallet_id = $1"}
+	args := []any{walletUUID}
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ExternalRef != "" {
+		addCondition("external_ref = $%d", filter.ExternalRef)
+	}
+	pairs := make(map[string]string)
+	keys := make([]string, 0)
+	for key, value := range filter.Metadata {
+		if value == "" {
+			keys = append(keys, key)
+		} else {
+			pairs[key] = value
+		}
+	}
+	if len(pairs) > 0 {
+		contained, err := json.Marshal(pairs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata filter: %w", err)
+		}
+		addCondition("metadata @> $%d::jsonb", string(contained))
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		addCondition("metadata ?& $%d", keys)
+	}
+	if filter.BeforeID > 0 {
+		addCondition("id < $%d", filter.BeforeID)
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+        SELECT id, wallet_id, operation_type, amount, reference_id, COALESCE(parent_reference_id::text, ''),
+            status, balance_after, created_at, applied_at, async, attempts,
+            COALESCE(failure_code, ''), COALESCE(failure_message, ''), %s
+        FROM wallet_operations
+        WHERE %s
+        ORDER BY id DESC
+        LIMIT $%d
+    `, operationDetailsColumns, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list operations: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	ops := make([]*types.Operation, 0)
+	for rows.Next() {
+		var (
+			op           types.Operation
+			balanceAfter sql.NullInt64
+			appliedAt    sql.NullTime
+			metadata     string
+		)
+		if err := rows.Scan(&op.ID, &op.WalletUUID, &op.Operation, &op.Amount, &op.ReferenceID, &op.ParentReferenceID,
+			&op.Status, &balanceAfter, &op.CreatedAt, &appliedAt, &op.Async, &op.Attempts,
+			&op.FailureCode, &op.FailureMessage, &op.Description, &op.ExternalRef, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to list operations: %w", classifyError(err))
+		}
+		if err := scanDetails(&op.OperationDetails, metadata); err != nil {
+			return nil, err
+		}
+		if balanceAfter.Valid {
+			balance := int(balanceAfter.Int64)
+			op.BalanceAfter = &balance
+		}
+		if appliedAt.Valid {
+			op.AppliedAt = &appliedAt.Time
+		}
+		ops = append(ops, &op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list operations: %w", classifyError(err))
+	}
+
+	// Пустая история не отличается от несуществующего кошелька
+	if len(ops) == 0 {
+		var exists bool
+		err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM wallet WHERE wallet_uuid = $1)`, walletUUID).
+			Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list operations: %w", classifyError(err))
+		}
+		if !exists {
+			return nil, types.ErrWalletNotFound
+		}
+	}
+
+	return ops, nil
+}
@@ -0,0 +1,101 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ListOperations(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "wallet_id", "operation_type", "amount", "reference_id", "parent_reference_id",
+		"status", "balance_after", "created_at", "applied_at", "async", "attempts", "failure_code", "failure_message",
+		"description", "external_ref", "metadata"}
+	existsQuery := `SELECT EXISTS\(SELECT 1 FROM wallet WHERE wallet_uuid = \$1\)`
+
+	t.Run("no filter", func(t *testing.T) {
+		mock.ExpectQuery(`FROM wallet_operations\s+WHERE wallet_id = \$1\s+ORDER BY id DESC\s+LIMIT \$2`).
+			WithArgs(walletUUID, 50).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(8, walletUUID, "WITHDRAW", 30, "ref-8", "", "APPLIED", 70, created, created, false, 0, "", "",
+					"", "", "").
+				AddRow(7, walletUUID, "DEPOSIT", 100, "ref-7", "", "APPLIED", 100, created, created, false, 0, "", "",
+					"top up", "PSP-1", `{"channel": "web"}`))
+
+		ops, err := repo.ListOperations(ctx, walletUUID, types.OperationFilter{Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		assert.Equal(t, int64(8), ops[0].ID)
+		assert.Equal(t, 70, *ops[0].BalanceAfter)
+		assert.Equal(t, types.OperationDetails{
+			Description: "top up",
+			ExternalRef: "PSP-1",
+			Metadata:    map[string]string{"channel": "web"},
+		}, ops[1].OperationDetails)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("all filters", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE wallet_id = \$1 AND external_ref = \$2 AND metadata @> \$3::jsonb AND metadata \?& \$4 AND id < \$5\s+ORDER BY id DESC\s+LIMIT \$6`).
+			WithArgs(walletUUID, "PSP-1", `{"channel":"web","order":"42"}`, []string{"campaign", "promo"}, int64(100), 10).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, walletUUID, "DEPOSIT", 100, "ref-7", "", "APPLIED", 100, created, created, false, 0, "", "",
+					"", "PSP-1", `{"channel": "web", "order": "42", "promo": "", "campaign": "x"}`))
+
+		ops, err := repo.ListOperations(ctx, walletUUID, types.OperationFilter{
+			ExternalRef: "PSP-1",
+			Metadata:    map[string]string{"channel": "web", "order": "42", "promo": "", "campaign": ""},
+			BeforeID:    100,
+			Limit:       10,
+		})
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		mock.ExpectQuery(`FROM wallet_operations`).
+			WithArgs(walletUUID, 50).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectQuery(existsQuery).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		ops, err := repo.ListOperations(ctx, walletUUID, types.OperationFilter{Limit: 50})
+		require.NoError(t, err)
+		assert.NotNil(t, ops)
+		assert.Empty(t, ops)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(`FROM wallet_operations`).
+			WithArgs(walletUUID, 50).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectQuery(existsQuery).
+			WithArgs(walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		_, err := repo.ListOperations(ctx, walletUUID, types.OperationFilter{Limit: 50})
+		assert.ErrorIs(t, err, types.ErrWalletNotFound)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(`FROM wallet_operations`).WillReturnError(assert.AnError)
+
+		_, err := repo.ListOperations(ctx, walletUUID, types.OperationFilter{Limit: 50})
+		assert.ErrorContains(t, err, "failed to list operations")
+	})
+}
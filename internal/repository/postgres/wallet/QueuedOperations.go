@@ -0,0 +1,125 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EnqueueOperation stores the operation as PENDING for an async worker to
+// apply. Only the wallet's existence and the reference ID are checked here,
+// everything else is checked when the operation is applied.
+func (r *Repository) EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	op := &types.Operation{
+		WalletUUID:  req.WalletUUID,
+		Operation:   req.Operation,
+		Amount:      req.Amount,
+		ReferenceID: req.ReferenceID,
+		Status:      "PENDING",
+		Async:       true,
+
+		OperationDetails: req.OperationDetails,
+	}
+
+	description, externalRef, metadata, err := detailsArgs(req.OperationDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+        INSERT INTO wallet_operations
+            (wallet_id, operation_type, amount, reference_id, status, async, created_at,
+             description, external_ref, metadata)
+        SELECT wallet_uuid, $2, $3, $4, 'PENDING', TRUE, NOW(), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, '')::jsonb
+        FROM wallet
+        WHERE wallet_uuid = $1
+        RETURNING id, created_at
+    `, req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, description, externalRef, metadata).
+		Scan(&op.ID, &op.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to enqueue operation: %w", types.ErrWalletNotFound)
+		}
+		return nil, fmt.Errorf("failed to enqueue operation: %w", classifyError(err))
+	}
+
+	return op, nil
+}
+
+// ClaimQueuedOperations picks up to limit queued operations, oldest first, and
+// locks them for lease, so that other workers skip them while they are being
+// applied. An operation that is still PENDING when the lease expires is picked
+// up again; attempts counts how many times it was claimed.
+func (r *Repository) ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        UPDATE wallet_operations
+        SET locked_until = NOW() + $2 * INTERVAL '1 millisecond', attempts = attempts + 1
+        WHERE id IN (
+            SELECT id FROM wallet_operations
+            WHERE async AND status = 'PENDING'
+                AND (locked_until IS NULL OR locked_until <= NOW())
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id, wallet_id, operation_type, amount, reference_id, status, attempts, created_at
+    `, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim queued operations: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	ops := make([]*types.Operation, 0)
+	for rows.Next() {
+		op := &types.Operation{Async: true}
+		if err := rows.Scan(&op.ID, &op.WalletUUID, &op.Operation, &op.Amount, &op.ReferenceID,
+			&op.Status, &op.Attempts, &op.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to claim queued operations: %w", classifyError(err))
+		}
+		ops = append(ops, op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim queued operations: %w", classifyError(err))
+	}
+
+	// Порядок RETURNING не гарантирован
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].ID < ops[j].ID
+	})
+	return ops, nil
+}
+
+// FailOperation marks a PENDING operation as failed and stores the failure
+// code and message of cause, see types.FailureMessage. Only the log gets cause
+// itself. Operations that are not pending are left alone; a synchronous one
+// never is by then, as it is rolled back together with its transaction.
+func (r *Repository) FailOperation(ctx context.Context, referenceID string, cause error) error {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE wallet_operations
+        SET status = 'FAILED', applied_at = NOW(), failure_code = $2, failure_message = $3, locked_until = NULL
+        WHERE reference_id = $1 AND status = 'PENDING'
+    `, referenceID, types.FailureCode(cause), types.FailureMessage(cause))
+	if err != nil {
+		return fmt.Errorf("failed to mark operation as failed: %w", classifyError(err))
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+	// Отказы уже залогированы сервисом, остальные причины видны только здесь
+	level := zapcore.WarnLevel
+	if types.IsRejected(cause) {
+		level = zapcore.DebugLevel
+	}
+	logger.FromContext(ctx, zap.NewNop()).Log(level, "operation marked as failed",
+		zap.String("reference_id", referenceID),
+		zap.Error(cause))
+	return nil
+}
@@ -0,0 +1,172 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRepository_EnqueueOperation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	req := types.NewWalletUpdateRequest("a1b2c3e4-5678-9012-3456-789012345678", "DEPOSIT", 100, "ref-123")
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := `INSERT INTO wallet_operations\s+\(wallet_id, operation_type, amount, reference_id, status, async, created_at,\s+description, external_ref, metadata\)\s+SELECT wallet_uuid, \$2, \$3, \$4, 'PENDING', TRUE, NOW\(\), NULLIF\(\$5, ''\), NULLIF\(\$6, ''\), NULLIF\(\$7, ''\)::jsonb\s+FROM wallet\s+WHERE wallet_uuid = \$1`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(42, created))
+
+		op, err := repo.EnqueueOperation(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Operation{
+			ID:          42,
+			WalletUUID:  req.WalletUUID,
+			Operation:   req.Operation,
+			Amount:      req.Amount,
+			ReferenceID: req.ReferenceID,
+			Status:      "PENDING",
+			CreatedAt:   created,
+			Async:       true,
+		}, op)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stores details", func(t *testing.T) {
+		req := types.NewWalletUpdateRequest("a1b2c3e4-5678-9012-3456-789012345678", "DEPOSIT", 100, "ref-124")
+		req.Description = "invoice payment"
+		req.ExternalRef = "INV-1"
+		req.Metadata = map[string]string{"order": "42", "channel": "web"}
+
+		mock.ExpectQuery(query).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID,
+				"invoice payment", "INV-1", `{"channel":"web","order":"42"}`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(43, created))
+
+		op, err := repo.EnqueueOperation(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.OperationDetails, op.OperationDetails)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.EnqueueOperation(ctx, req)
+		assert.ErrorIs(t, err, types.ErrWalletNotFound)
+	})
+
+	t.Run("duplicate reference_id", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "wallet_operations_reference_id_key"})
+
+		_, err := repo.EnqueueOperation(ctx, req)
+		assert.ErrorIs(t, err, types.ErrOperationExists)
+	})
+}
+
+func TestRepository_ClaimQueuedOperations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	created := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := `UPDATE wallet_operations\s+SET locked_until = NOW\(\) \+ \$2 \* INTERVAL '1 millisecond', attempts = attempts \+ 1\s+WHERE id IN \(.+WHERE async AND status = 'PENDING'.+FOR UPDATE SKIP LOCKED`
+	columns := []string{"id", "wallet_id", "operation_type", "amount", "reference_id", "status", "attempts", "created_at"}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(10, int64(30000)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(9, "a1b2c3e4-5678-9012-3456-789012345678", "WITHDRAW", 50, "ref-9", "PENDING", 2, created).
+				AddRow(8, "a1b2c3e4-5678-9012-3456-789012345678", "DEPOSIT", 100, "ref-8", "PENDING", 1, created))
+
+		ops, err := repo.ClaimQueuedOperations(ctx, 10, 30*time.Second)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+		assert.Equal(t, "ref-8", ops[0].ReferenceID)
+		assert.Equal(t, "ref-9", ops[1].ReferenceID)
+		assert.Equal(t, 2, ops[1].Attempts)
+		assert.True(t, ops[1].Async)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.ClaimQueuedOperations(ctx, 10, time.Second)
+		assert.ErrorContains(t, err, "failed to claim queued operations")
+	})
+}
+
+func TestRepository_FailOperation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3, locked_until = NULL\s+WHERE reference_id = \$1 AND status = 'PENDING'`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs("ref-1", types.FailureTimeout, "query canceled").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.FailOperation(ctx, "ref-1", fmt.Errorf("failed to commit: %w", types.ErrQueryCanceled)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("driver error is not stored", func(t *testing.T) {
+		pgErr := &pgconn.PgError{
+			Code:           "23514",
+			Message:        `new row for relation "wallet" violates check constraint "wallet_max_balance"`,
+			ConstraintName: "wallet_max_balance",
+		}
+		mock.ExpectExec(query).
+			WithArgs("ref-1", types.FailureInvalidOperation, types.ErrCheckViolation.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.FailOperation(ctx, "ref-1", fmt.Errorf("failed to update balance: %w", classifyError(pgErr))))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("operation that is not pending is not logged", func(t *testing.T) {
+		core, logs := observer.New(zap.DebugLevel)
+		mock.ExpectExec(query).
+			WithArgs("ref-1", types.FailureInsufficientFunds, types.ErrInsufficientFunds.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		require.NoError(t, repo.FailOperation(logger.ContextWith(ctx, zap.New(core)), "ref-1", types.ErrInsufficientFunds))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(assert.AnError)
+
+		err := repo.FailOperation(ctx, "ref-1", types.ErrWalletFrozen)
+		assert.ErrorContains(t, err, "failed to mark operation as failed")
+	})
+}
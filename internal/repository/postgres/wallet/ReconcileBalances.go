@@ -0,0 +1,47 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// ReconcileBalances returns the wallets whose balance differs from the balance
+// after their last applied operation. Wallets without operations are skipped.
+// The last operation is the latest by applied_at, which follows the order in
+// which the balance changed, see applyOperation.
+func (r *Repository) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	query := `
+        SELECT w.wallet_uuid, w.balance, o.balance_after, o.reference_id
+        FROM wallet w
+        JOIN LATERAL (
+            SELECT balance_after, reference_id FROM wallet_operations
+            WHERE wallet_id = w.wallet_uuid AND status = 'APPLIED'
+            ORDER BY applied_at DESC, id DESC
+            LIMIT 1
+        ) o ON TRUE
+        WHERE o.balance_after IS DISTINCT FROM w.balance
+        ORDER BY w.wallet_uuid
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile balances: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	mismatches := make([]types.BalanceMismatch, 0)
+	for rows.Next() {
+		var m types.BalanceMismatch
+		if err := rows.Scan(&m.WalletUUID, &m.Balance, &m.ExpectedBalance, &m.LastReferenceID); err != nil {
+			return nil, fmt.Errorf("failed to reconcile balances: %w", classifyError(err))
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to reconcile balances: %w", classifyError(err))
+	}
+
+	return mismatches, nil
+}
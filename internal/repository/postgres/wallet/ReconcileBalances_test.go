@@ -0,0 +1,54 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ReconcileBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `SELECT w.wallet_uuid, w.balance, o.balance_after, o.reference_id\s+FROM wallet w\s+JOIN LATERAL .+WHERE o.balance_after IS DISTINCT FROM w.balance`
+
+	t.Run("mismatches", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance", "balance_after", "reference_id"}).
+				AddRow("a1b2c3e4-5678-9012-3456-789012345678", 500, 400, "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"))
+
+		mismatches, err := repo.ReconcileBalances(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []types.BalanceMismatch{{
+			WalletUUID:      "a1b2c3e4-5678-9012-3456-789012345678",
+			Balance:         500,
+			ExpectedBalance: 400,
+			LastReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b",
+		}}, mismatches)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("consistent", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance", "balance_after", "reference_id"}))
+
+		mismatches, err := repo.ReconcileBalances(ctx)
+		require.NoError(t, err)
+		assert.NotNil(t, mismatches)
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(assert.AnError)
+
+		_, err := repo.ReconcileBalances(ctx)
+		assert.ErrorContains(t, err, "failed to reconcile balances")
+	})
+}
@@ -0,0 +1,28 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// SetCreditLimit changes how far below zero the wallet balance may go. It
+// fails with types.ErrInsufficientFunds if the wallet is already deeper in
+// overdraft than the new limit allows.
+func (r *Repository) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error {
+	query := `
+        UPDATE wallet SET credit_limit = $1, version = version + 1, updated_at = NOW()
+        WHERE wallet_uuid = $2
+    `
+	result, err := r.db.ExecContext(ctx, query, creditLimit, walletUUID)
+	if err != nil {
+		return fmt.Errorf("failed to set credit limit: %w", classifyError(err))
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.ErrWalletNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_SetCreditLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	query := `UPDATE wallet SET credit_limit = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(500, walletUUID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.SetCreditLimit(ctx, walletUUID, 500))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(500, walletUUID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, types.ErrWalletNotFound, repo.SetCreditLimit(ctx, walletUUID, 500))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("balance below new limit", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(0, walletUUID).
+			WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "wallet_balance_check"})
+
+		err := repo.SetCreditLimit(ctx, walletUUID, 0)
+		assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,25 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// SetWalletFrozen freezes or unfreezes a wallet. Operations on a frozen
+// wallet fail with types.ErrWalletFrozen.
+func (r *Repository) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE wallet SET frozen = $1, updated_at = NOW()
+        WHERE wallet_uuid = $2
+    `, frozen, walletUUID)
+	if err != nil {
+		return fmt.Errorf("failed to freeze wallet: %w", classifyError(err))
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.ErrWalletNotFound
+	}
+	return nil
+}
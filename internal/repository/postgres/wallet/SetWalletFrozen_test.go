@@ -0,0 +1,39 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_SetWalletFrozen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	query := `UPDATE wallet SET frozen = \$1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(true, walletUUID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.SetWalletFrozen(ctx, walletUUID, true))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(false, walletUUID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, types.ErrWalletNotFound, repo.SetWalletFrozen(ctx, walletUUID, false))
+	})
+}
@@ -0,0 +1,33 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// SetWalletLimits assigns a tier to the wallet and replaces its overrides.
+func (r *Repository) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error {
+	query := `
+        INSERT INTO wallet_limits
+            (wallet_uuid, tier, max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        ON CONFLICT (wallet_uuid) DO UPDATE SET
+            tier = EXCLUDED.tier,
+            max_withdrawal = EXCLUDED.max_withdrawal,
+            daily_withdrawal = EXCLUDED.daily_withdrawal,
+            monthly_withdrawal = EXCLUDED.monthly_withdrawal,
+            max_balance = EXCLUDED.max_balance,
+            updated_at = NOW()
+    `
+	_, err := r.db.ExecContext(ctx, query,
+		walletUUID, update.Tier,
+		update.Overrides.MaxWithdrawal, update.Overrides.DailyWithdrawal,
+		update.Overrides.MonthlyWithdrawal, update.Overrides.MaxBalance)
+	if err != nil {
+		return fmt.Errorf("failed to set wallet limits: %w", classifyError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_SetWalletLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+	maxBalance := 10000
+	update := &types.WalletLimitsUpdate{
+		Tier:      "gold",
+		Overrides: types.LimitValues{MaxBalance: &maxBalance},
+	}
+	query := `INSERT INTO wallet_limits.+ON CONFLICT \(wallet_uuid\) DO UPDATE SET`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(walletUUID, "gold", nil, nil, nil, 10000).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		require.NoError(t, repo.SetWalletLimits(ctx, walletUUID, update))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown tier", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(walletUUID, "gold", nil, nil, nil, 10000).
+			WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "wallet_limits_tier_fkey"})
+
+		err := repo.SetWalletLimits(ctx, walletUUID, update)
+		assert.ErrorIs(t, err, types.ErrUnknownTier)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
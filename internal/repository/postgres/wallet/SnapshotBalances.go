@@ -0,0 +1,30 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SnapshotBalances stores the end-of-day balance of every wallet that existed
+// by the end of day, which is given as a UTC date. Taking the snapshot of a
+// day again overwrites it.
+func (r *Repository) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	asOf := day.AddDate(0, 0, 1)
+
+	result, err := r.db.ExecContext(ctx, `
+        INSERT INTO wallet_balance_snapshots (wallet_uuid, day, as_of, balance)
+        SELECT b.wallet_uuid, $2, $1, b.balance FROM (`+balanceAtQuery+`
+            WHERE w.created_at < $1
+        ) b
+        ON CONFLICT (wallet_uuid, day) DO UPDATE
+        SET as_of = EXCLUDED.as_of, balance = EXCLUDED.balance, created_at = NOW()
+    `, asOf, day.Format(time.DateOnly))
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot balances of %s: %w", day.Format(time.DateOnly), classifyError(err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
@@ -0,0 +1,39 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_SnapshotBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `INSERT INTO wallet_balance_snapshots \(wallet_uuid, day, as_of, balance\)\s+SELECT b.wallet_uuid, \$2, \$1, b.balance FROM \(.*WHERE w.created_at < \$1\s+\) b\s+ON CONFLICT \(wallet_uuid, day\) DO UPDATE`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), "2026-09-30").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		n, err := repo.SnapshotBalances(ctx, time.Date(2026, 9, 30, 15, 4, 5, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(assert.AnError)
+
+		_, err := repo.SnapshotBalances(ctx, time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC))
+		assert.ErrorContains(t, err, "failed to snapshot balances of 2026-09-30")
+	})
+}
@@ -0,0 +1,96 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// statementFetchSize is how many operations are read from the cursor at once.
+const statementFetchSize = 500
+
+// StreamStatement writes the statement of the wallet for operations applied
+// after from and up to to. The operations are read through a server-side
+// cursor in a single snapshot, so the opening balance, the operations and the
+// closing balance always agree and the statement is never held in memory.
+func (r *Repository) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	// Закрывает и курсор
+	defer tx.Rollback()
+
+	var (
+		uuid    string
+		balance int
+	)
+	err = tx.QueryRowContext(ctx, balanceAtQuery+`WHERE w.wallet_uuid = $2`, from, walletUUID).
+		Scan(&uuid, &balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.ErrWalletNotFound
+		}
+		return fmt.Errorf("failed to get opening balance: %w", classifyError(err))
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DECLARE statement_cursor NO SCROLL CURSOR FOR
+        SELECT o.applied_at, o.reference_id, o.operation_type, o.amount, COALESCE(f.amount, 0), o.balance_after
+        FROM wallet_operations o
+        LEFT JOIN wallet_operations f ON f.parent_reference_id = o.reference_id
+        WHERE o.wallet_id = $1 AND o.status = 'APPLIED' AND o.applied_at > $2 AND o.applied_at <= $3
+        ORDER BY o.applied_at, o.id
+    `, walletUUID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to open statement cursor: %w", classifyError(err))
+	}
+
+	if err := w.WriteOpening(balance); err != nil {
+		return err
+	}
+
+	for {
+		n, err := fetchStatement(ctx, tx, w, &balance)
+		if err != nil {
+			return err
+		}
+		if n < statementFetchSize {
+			break
+		}
+	}
+
+	return w.WriteClosing(balance)
+}
+
+// fetchStatement writes the next batch of operations from the cursor, keeps
+// balance at the one after the last of them and returns how many were read.
+func fetchStatement(ctx context.Context, tx *sql.Tx, w types.StatementWriter, balance *int) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`FETCH FORWARD %d FROM statement_cursor`, statementFetchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read statement: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var entry types.StatementEntry
+		if err := rows.Scan(&entry.AppliedAt, &entry.ReferenceID, &entry.Operation, &entry.Amount, &entry.Fee, &entry.Balance); err != nil {
+			return n, fmt.Errorf("failed to read statement: %w", classifyError(err))
+		}
+		if err := w.WriteEntry(&entry); err != nil {
+			return n, err
+		}
+		*balance = entry.Balance
+		n++
+	}
+
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("failed to read statement: %w", classifyError(err))
+	}
+
+	return n, nil
+}
@@ -0,0 +1,140 @@
+package walletpostgresql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStatementWriter keeps what it is given in memory.
+type recordingStatementWriter struct {
+	opening, closing int
+	entries          []types.StatementEntry
+	closed           bool
+	err              error
+}
+
+func (w *recordingStatementWriter) WriteOpening(balance int) error {
+	w.opening = balance
+	return nil
+}
+
+func (w *recordingStatementWriter) WriteEntry(entry *types.StatementEntry) error {
+	w.entries = append(w.entries, *entry)
+	return w.err
+}
+
+func (w *recordingStatementWriter) WriteClosing(balance int) error {
+	w.closing = balance
+	w.closed = true
+	return nil
+}
+
+func TestRepository_StreamStatement(t *testing.T) {
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	entryColumns := []string{"applied_at", "reference_id", "operation_type", "amount", "fee", "balance_after"}
+
+	expectOpening := func(mock sqlmock.Sqlmock, balance int) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT w.wallet_uuid, COALESCE\(.*WHERE w.wallet_uuid = \$2`).
+			WithArgs(from, walletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance"}).AddRow(walletUUID, balance))
+		mock.ExpectExec(`DECLARE statement_cursor NO SCROLL CURSOR FOR\s+SELECT o.applied_at`).
+			WithArgs(walletUUID, from, to).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("reads the cursor until it is exhausted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		expectOpening(mock, 1000)
+
+		full := sqlmock.NewRows(entryColumns)
+		for i := 0; i < statementFetchSize; i++ {
+			full.AddRow(from.Add(time.Duration(i+1)*time.Minute), "ref", "DEPOSIT", 1, 0, 1001+i)
+		}
+		mock.ExpectQuery(`FETCH FORWARD 500 FROM statement_cursor`).WillReturnRows(full)
+		mock.ExpectQuery(`FETCH FORWARD 500 FROM statement_cursor`).
+			WillReturnRows(sqlmock.NewRows(entryColumns).
+				AddRow(to, "last", "WITHDRAW", 100, 20, 1380))
+		mock.ExpectRollback()
+
+		w := &recordingStatementWriter{}
+		err = repo.StreamStatement(context.Background(), walletUUID, from, to, w)
+		require.NoError(t, err)
+		assert.Equal(t, 1000, w.opening)
+		assert.Len(t, w.entries, statementFetchSize+1)
+		assert.Equal(t, types.StatementEntry{
+			AppliedAt: to, ReferenceID: "last", Operation: "WITHDRAW", Amount: 100, Fee: 20, Balance: 1380,
+		}, w.entries[statementFetchSize])
+		assert.Equal(t, 1380, w.closing)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no operations", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		expectOpening(mock, 1000)
+		mock.ExpectQuery(`FETCH FORWARD 500 FROM statement_cursor`).WillReturnRows(sqlmock.NewRows(entryColumns))
+		mock.ExpectRollback()
+
+		w := &recordingStatementWriter{}
+		require.NoError(t, repo.StreamStatement(context.Background(), walletUUID, from, to, w))
+		assert.Equal(t, 1000, w.opening)
+		assert.Empty(t, w.entries)
+		assert.Equal(t, 1000, w.closing)
+		assert.True(t, w.closed)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT w.wallet_uuid, COALESCE`).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid", "balance"}))
+		mock.ExpectRollback()
+
+		err = repo.StreamStatement(context.Background(), walletUUID, from, to, &recordingStatementWriter{})
+		assert.Equal(t, types.ErrWalletNotFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("writer error stops the statement", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		expectOpening(mock, 1000)
+		mock.ExpectQuery(`FETCH FORWARD 500 FROM statement_cursor`).
+			WillReturnRows(sqlmock.NewRows(entryColumns).
+				AddRow(to, "ref-1", "DEPOSIT", 100, 0, 1100).
+				AddRow(to, "ref-2", "DEPOSIT", 100, 0, 1200))
+		mock.ExpectRollback()
+
+		writeErr := errors.New("broken pipe")
+		w := &recordingStatementWriter{err: writeErr}
+		err = repo.StreamStatement(context.Background(), walletUUID, from, to, w)
+		assert.Equal(t, writeErr, err)
+		assert.Len(t, w.entries, 1)
+		assert.False(t, w.closed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,27 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// SweepPendingOperations marks as failed the operations that have been
+// pending for longer than olderThan, e.g. after a crash, and returns how many
+// there were. Queued async operations are pending by design and left to the
+// workers.
+func (r *Repository) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE wallet_operations
+        SET status = 'FAILED', applied_at = NOW(), failure_code = $2, failure_message = $3
+        WHERE status = 'PENDING' AND NOT async AND created_at < NOW() - $1 * INTERVAL '1 millisecond'
+    `, olderThan.Milliseconds(), types.FailureAbandoned, fmt.Sprintf("pending for longer than %s", olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep pending operations: %w", classifyError(err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
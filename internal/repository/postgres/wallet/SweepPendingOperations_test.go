@@ -0,0 +1,40 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_SweepPendingOperations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{db: db}
+	ctx := context.Background()
+	query := `UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3\s+WHERE status = 'PENDING' AND NOT async AND created_at < NOW\(\) - \$1 \* INTERVAL '1 millisecond'`
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(int64(600000), types.FailureAbandoned, "pending for longer than 10m0s").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		n, err := repo.SweepPendingOperations(ctx, 10*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(assert.AnError)
+
+		_, err := repo.SweepPendingOperations(ctx, time.Minute)
+		assert.ErrorContains(t, err, "failed to sweep pending operations")
+	})
+}
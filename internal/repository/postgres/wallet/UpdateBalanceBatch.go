@@ -0,0 +1,73 @@
+package walletpostgresql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// UpdateBalanceBatch applies all requests in a single transaction: either every
+// operation is applied or none is. On failure a *types.BatchItemError tells
+// which request caused it.
+func (r *Repository) UpdateBalanceBatch(ctx context.Context, reqs []*types.WalletUpdateRequest) error {
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	// Locking every wallet up front and in the same order keeps two batches
+	// touching the same wallets from deadlocking each other.
+	walletUUIDs := uniqueWalletUUIDs(reqs)
+	rows, err := tx.QueryContext(ctx, `
+        SELECT wallet_uuid FROM wallet
+        WHERE wallet_uuid = ANY($1)
+        ORDER BY wallet_uuid
+        FOR UPDATE
+    `, walletUUIDs)
+	if err != nil {
+		return fmt.Errorf("failed to lock wallets: %w", classifyError(err))
+	}
+	if err = rows.Close(); err != nil {
+		return fmt.Errorf("failed to lock wallets: %w", classifyError(err))
+	}
+
+	var fees []feeCharge
+	for i, req := range reqs {
+		fee, err := applyOperation(ctx, tx, req)
+		if err != nil {
+			return &types.BatchItemError{Index: i, ReferenceID: req.ReferenceID, Err: err}
+		}
+		fees = feeCharges(fees, req, fee)
+	}
+
+	if err = creditFees(ctx, tx, fees); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+func uniqueWalletUUIDs(reqs []*types.WalletUpdateRequest) []string {
+	seen := make(map[string]struct{}, len(reqs))
+	walletUUIDs := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		if _, ok := seen[req.WalletUUID]; ok {
+			continue
+		}
+		seen[req.WalletUUID] = struct{}{}
+		walletUUIDs = append(walletUUIDs, req.WalletUUID)
+	}
+	sort.Strings(walletUUIDs)
+	return walletUUIDs
+}
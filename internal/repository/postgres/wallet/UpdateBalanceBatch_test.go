@@ -0,0 +1,128 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// anyValueConverter lets slices through like the pgx driver does.
+type anyValueConverter struct{}
+
+func (anyValueConverter) ConvertValue(v any) (driver.Value, error) {
+	switch v.(type) {
+	case []string, []int:
+		return v, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+func TestRepository_UpdateBalanceBatch(t *testing.T) {
+	walletA := "a1b2c3e4-5678-9012-3456-789012345678"
+	walletB := "b2c3d4e5-6789-0123-4567-890123456789"
+
+	newBatch := func() []*types.WalletUpdateRequest {
+		return []*types.WalletUpdateRequest{
+			types.NewWalletUpdateRequest(walletB, types.OperationTypeDeposit, 100, "ref-1"),
+			types.NewWalletUpdateRequest(walletA, types.OperationTypeWithdraw, 50, "ref-2"),
+		}
+	}
+
+	expectLock := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT wallet_uuid FROM wallet\s+WHERE wallet_uuid = ANY\(\$1\)\s+ORDER BY wallet_uuid\s+FOR UPDATE`).
+			WithArgs([]string{walletA, walletB}).
+			WillReturnRows(sqlmock.NewRows([]string{"wallet_uuid"}).AddRow(walletA).AddRow(walletB))
+	}
+
+	expectApply := func(mock sqlmock.Sqlmock, req *types.WalletUpdateRequest, balance, newBalance int) {
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(walletRows(balance, 1))
+
+		if newBalance < 0 {
+			return
+		}
+
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
+			WithArgs(newBalance, req.WalletUUID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED', applied_at = clock_timestamp\(\), balance_after = \$2\s+WHERE reference_id = \$1`).
+			WithArgs(req.ReferenceID, newBalance).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		reqs := newBatch()
+
+		mock.ExpectBegin()
+		expectLock(mock)
+		expectApply(mock, reqs[0], 500, 600)
+		expectApply(mock, reqs[1], 500, 450)
+		mock.ExpectCommit()
+
+		err = repo.UpdateBalanceBatch(context.Background(), reqs)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failing item rolls back the whole batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		reqs := newBatch()
+
+		mock.ExpectBegin()
+		expectLock(mock)
+		expectApply(mock, reqs[0], 500, 600)
+		expectApply(mock, reqs[1], 10, -1)
+		mock.ExpectRollback()
+
+		err = repo.UpdateBalanceBatch(context.Background(), reqs)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+
+		var itemErr *types.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.Equal(t, "ref-2", itemErr.ReferenceID)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lock error", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT wallet_uuid FROM wallet`).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err = repo.UpdateBalanceBatch(context.Background(), newBatch())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to lock wallets")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
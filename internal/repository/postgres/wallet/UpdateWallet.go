@@ -6,95 +6,191 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
 	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
 )
 
-func (r *Repository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) error {
+// UpdateBalance applies a single operation and returns the fee charged for it.
+func (r *Repository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (fee types.Fee, err error) {
+	log := logger.FromContext(ctx, zap.NewNop()).With(zap.String("reference_id", req.ReferenceID))
+
 	var cancel context.CancelFunc
 	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return types.Fee{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
 	}
 	defer func() {
-		if err != nil {
-			_ = r.markOperationFailed(ctx, req.ReferenceID)
-		}
 		tx.Rollback()
+		// Синхронная операция откатывается вместе с транзакцией, помечать
+		// приходится только поставленную в очередь. Её повторит воркер, если
+		// ошибка не окончательная
+		if types.IsRejected(err) {
+			if markErr := r.markOperationFailed(ctx, req.ReferenceID, err); markErr != nil {
+				log.Warn("failed to mark operation as failed", zap.Error(markErr))
+			}
+		}
 	}()
 
-	_, err = tx.ExecContext(ctx, `
+	if fee, err = applyOperation(ctx, tx, req); err != nil {
+		return types.Fee{}, err
+	}
+
+	if err = creditFees(ctx, tx, feeCharges(nil, req, fee)); err != nil {
+		return types.Fee{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return types.Fee{}, fmt.Errorf("failed to commit: %w", classifyError(err))
+	}
+
+	log.Debug("operation applied",
+		zap.String("wallet_uuid", req.WalletUUID),
+		zap.Int("fee", fee.Total))
+	return fee, nil
+}
+
+// walletForUpdateQuery loads the wallet together with its effective limits,
+// the ones of its tier with the wallet's own overrides applied, and the fee
+// rule of its tier.
+const walletForUpdateQuery = `
+        SELECT w.balance, w.version, w.credit_limit,
+            COALESCE(l.max_withdrawal, t.max_withdrawal),
+            COALESCE(l.daily_withdrawal, t.daily_withdrawal),
+            COALESCE(l.monthly_withdrawal, t.monthly_withdrawal),
+            COALESCE(l.max_balance, t.max_balance),
+            COALESCE(f.flat_fee, 0), COALESCE(f.percent_bps, 0), f.min_fee, f.max_fee,
+            w.frozen
+        FROM wallet w
+        LEFT JOIN wallet_limits l ON l.wallet_uuid = w.wallet_uuid
+        LEFT JOIN wallet_limit_tiers t ON t.tier = COALESCE(l.tier, 'standard')
+        LEFT JOIN wallet_fee_rules f ON f.tier = t.tier
+        WHERE w.wallet_uuid = $1
+    `
+
+// applyOperation logs the operation and moves the wallet balance inside tx.
+// A withdrawal is also charged its fee, which is logged as a FEE operation;
+// crediting the fee wallet is left to the caller, see creditFees. The caller
+// owns the transaction and decides whether to commit it.
+//
+// An async operation queued with the same reference ID and payload is applied
+// in place of logging a new one; any other existing operation with the
+// reference ID fails the call with types.ErrOperationExists.
+func applyOperation(ctx context.Context, tx *sql.Tx, req *types.WalletUpdateRequest) (types.Fee, error) {
+	description, externalRef, metadata, err := detailsArgs(req.OperationDetails)
+	if err != nil {
+		return types.Fee{}, err
+	}
+
+	// Поставленная в очередь операция сохраняет свои описание и метаданные
+	result, err := tx.ExecContext(ctx, `
         INSERT INTO wallet_operations 
-            (wallet_id, operation_type, amount, reference_id, status, created_at)
-        VALUES ($1, $2, $3, $4, 'PENDING', NOW())
-    `, req.WalletUUID, req.Operation, req.Amount, req.ReferenceID)
+            (wallet_id, operation_type, amount, reference_id, status, created_at,
+             description, external_ref, metadata)
+        VALUES ($1, $2, $3, $4, 'PENDING', NOW(), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, '')::jsonb)
+        ON CONFLICT (reference_id) DO UPDATE SET locked_until = NULL
+        WHERE wallet_operations.async AND wallet_operations.status = 'PENDING'
+            AND wallet_operations.wallet_id = EXCLUDED.wallet_id
+            AND wallet_operations.operation_type = EXCLUDED.operation_type
+            AND wallet_operations.amount = EXCLUDED.amount
+    `, req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, description, externalRef, metadata)
 
 	if err != nil {
-		return fmt.Errorf("failed to log operation: %w", err)
+		return types.Fee{}, fmt.Errorf("failed to log operation: %w", classifyError(err))
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return types.Fee{}, fmt.Errorf("failed to log operation: %w", types.ErrOperationExists)
 	}
 
-	var currentBalance, currentVersion int
-	err = tx.QueryRowContext(ctx, `
-        SELECT balance, version FROM wallet WHERE wallet_uuid = $1
-    `, req.WalletUUID).Scan(&currentBalance, &currentVersion)
+	var (
+		currentBalance, currentVersion, creditLimit int
+		limits                                      types.LimitValues
+		feeRule                                     types.FeeRule
+		frozen                                      bool
+	)
+	err = tx.QueryRowContext(ctx, walletForUpdateQuery, req.WalletUUID).Scan(
+		&currentBalance, &currentVersion, &creditLimit,
+		&limits.MaxWithdrawal, &limits.DailyWithdrawal, &limits.MonthlyWithdrawal, &limits.MaxBalance,
+		&feeRule.Flat, &feeRule.PercentBps, &feeRule.MinFee, &feeRule.MaxFee,
+		&frozen)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return types.ErrWalletNotFound
+			return types.Fee{}, types.ErrWalletNotFound
 		}
-		return fmt.Errorf("failed to load wallet: %w", err)
+		return types.Fee{}, fmt.Errorf("failed to load wallet: %w", classifyError(err))
 	}
 
+	if frozen {
+		return types.Fee{}, types.ErrWalletFrozen
+	}
+
+	var fee types.Fee
 	newBalance := currentBalance
 	if req.Operation != "DEPOSIT" && req.Operation != "WITHDRAW" {
-		return types.ErrInvalidOperation
+		return types.Fee{}, types.ErrInvalidOperation
 	}
 	if req.Operation == "DEPOSIT" {
 		newBalance += req.Amount
 	}
 	if req.Operation == "WITHDRAW" {
-		if currentBalance < req.Amount {
-			return types.ErrInsufficientFunds
+		if req.WalletUUID != types.FeeWalletUUID {
+			fee = feeRule.Compute(req.Amount)
 		}
-		newBalance -= req.Amount
+		if currentBalance+creditLimit < req.Amount+fee.Total {
+			return types.Fee{}, types.ErrInsufficientFunds
+		}
+		newBalance -= req.Amount + fee.Total
 	}
 
-	result, err := tx.ExecContext(ctx, `
+	if err = checkLimits(ctx, tx, req, newBalance, limits); err != nil {
+		return types.Fee{}, err
+	}
+
+	result, err = tx.ExecContext(ctx, `
         UPDATE wallet SET balance = $1, version = version + 1, updated_at = NOW()
         WHERE wallet_uuid = $2 AND version = $3
     `, newBalance, req.WalletUUID, currentVersion)
 
 	if err != nil {
-		return fmt.Errorf("failed to update wallet: %w", err)
+		return types.Fee{}, fmt.Errorf("failed to update wallet: %w", classifyError(err))
 	}
 
 	if rows, _ := result.RowsAffected(); rows == 0 {
-		return types.ErrConcurrentUpdate
+		return types.Fee{}, types.ErrConcurrentUpdate
 	}
 
+	// NOW() — время начала транзакции, а не момент изменения баланса: две
+	// транзакции могут закоммититься в обратном порядке. clock_timestamp()
+	// берётся под блокировкой кошелька, поэтому applied_at идёт в том же
+	// порядке, что и balance_after
 	_, err = tx.ExecContext(ctx, `
         UPDATE wallet_operations 
-        SET status = 'APPLIED', applied_at = NOW() 
+        SET status = 'APPLIED', applied_at = clock_timestamp(), balance_after = $2
         WHERE reference_id = $1
-    `, req.ReferenceID)
+    `, req.ReferenceID, newBalance)
 
 	if err != nil {
-		return fmt.Errorf("failed to mark as applied: %w", err)
+		return types.Fee{}, fmt.Errorf("failed to mark as applied: %w", classifyError(err))
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit: %w", err)
+	if fee.Total > 0 {
+		if err = logFee(ctx, tx, req.ReferenceID, fee.Total); err != nil {
+			return types.Fee{}, err
+		}
 	}
 
-	return nil
+	return fee, nil
 }
 
-func (r *Repository) markOperationFailed(ctx context.Context, referenceID string) error {
-	_, err := r.db.ExecContext(ctx, `
-        UPDATE wallet_operations 
-        SET status = 'FAILED', applied_at = NOW() 
-        WHERE reference_id = $1 AND status = 'PENDING'
-    `, referenceID)
-	return err
+// markOperationFailed runs after the operation failed, often because ctx was
+// cancelled, so it does not inherit the cancellation and has a timeout of its own.
+func (r *Repository) markOperationFailed(ctx context.Context, referenceID string, cause error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+	defer cancel()
+
+	return r.FailOperation(ctx, referenceID, cause)
 }
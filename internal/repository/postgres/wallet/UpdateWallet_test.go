@@ -7,6 +7,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,25 +35,64 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(500, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1`).
+		rows := walletRows(500, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet SET balance =  $1, version = version  + 1, updated_at = NOW ( ) WHERE wallet_uuid =  $2 AND version =  $3`).
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
 			WithArgs(600, req.WalletUUID, 1).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'APPLIED', applied_at = NOW ( ) WHERE reference_id =  $1`).
-			WithArgs(req.ReferenceID).
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED', applied_at = clock_timestamp\(\), balance_after = \$2\s+WHERE reference_id = \$1`).
+			WithArgs(req.ReferenceID, 600).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
 
-		err = repo.UpdateBalance(ctx, req)
+		_, err = repo.UpdateBalance(ctx, req)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stores details", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+		req.Description = "top up"
+		req.ExternalRef = "PSP-77"
+		req.Metadata = map[string]string{"channel": "web"}
+
+		mock.ExpectBegin()
+
+		mock.ExpectExec(`INSERT INTO wallet_operations\s+\(wallet_id, operation_type, amount, reference_id, status, created_at,\s+description, external_ref, metadata\)`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "top up", "PSP-77", `{"channel":"web"}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(walletRows(500, 1))
+
+		mock.ExpectExec(`UPDATE wallet SET balance`).
+			WithArgs(600, req.WalletUUID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED'`).
+			WithArgs(req.ReferenceID, 600).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectCommit()
+
+		_, err = repo.UpdateBalance(ctx, req)
 		require.NoError(t, err)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -74,25 +114,25 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(100, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		rows := walletRows(100, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet SET balance =  $1, version = version  + 1, updated_at = NOW ( ) WHERE wallet_uuid =  $2 AND version =  $3`).
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
 			WithArgs(50, req.WalletUUID, 1).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'APPLIED', applied_at = NOW ( ) WHERE reference_id =  $1`).
-			WithArgs(req.ReferenceID).
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED', applied_at = clock_timestamp\(\), balance_after = \$2\s+WHERE reference_id = \$1`).
+			WithArgs(req.ReferenceID, 50).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectCommit()
 
-		err = repo.UpdateBalance(ctx, req)
+		_, err = repo.UpdateBalance(ctx, req)
 		require.NoError(t, err)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -111,20 +151,20 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnError(sql.ErrNoRows)
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'FAILED', applied_at = NOW ( ) WHERE reference_id =  $1 AND status = 'PENDING'`).
-			WithArgs(req.ReferenceID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-
 		mock.ExpectRollback()
 
-		err = repo.UpdateBalance(ctx, req)
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3, locked_until = NULL\s+WHERE reference_id = \$1 AND status = 'PENDING'`).
+			WithArgs(req.ReferenceID, types.FailureWalletNotFound, types.ErrWalletNotFound.Error()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err = repo.UpdateBalance(ctx, req)
 		require.Error(t, err)
 		assert.Equal(t, types.ErrWalletNotFound, err)
 
@@ -147,24 +187,125 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(100, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		rows := walletRows(100, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'FAILED', applied_at = NOW ( ) WHERE reference_id =  $1 AND status = 'PENDING'`).
-			WithArgs(req.ReferenceID).
+		mock.ExpectRollback()
+
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3, locked_until = NULL\s+WHERE reference_id = \$1 AND status = 'PENDING'`).
+			WithArgs(req.ReferenceID, types.FailureInsufficientFunds, types.ErrInsufficientFunds.Error()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		_, err = repo.UpdateBalance(ctx, req)
+		require.Error(t, err)
+		assert.Equal(t, types.ErrInsufficientFunds, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("withdraw into overdraft", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+		req.Operation = "WITHDRAW"
+		req.Amount = 300
+		req.ReferenceID = "ref-overdraft"
+
+		mock.ExpectBegin()
+
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(creditWalletRows(100, 1, 200))
+
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
+			WithArgs(-200, req.WalletUUID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED', applied_at = clock_timestamp\(\), balance_after = \$2\s+WHERE reference_id = \$1`).
+			WithArgs(req.ReferenceID, -200).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectCommit()
+
+		_, err = repo.UpdateBalance(ctx, req)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("withdraw beyond credit limit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+		req.Operation = "WITHDRAW"
+		req.Amount = 301
+		req.ReferenceID = "ref-overdraft"
+
+		mock.ExpectBegin()
+
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(creditWalletRows(100, 1, 200))
+
 		mock.ExpectRollback()
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3`).
+			WithArgs(req.ReferenceID, types.FailureInsufficientFunds, types.ErrInsufficientFunds.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
 
-		err = repo.UpdateBalance(ctx, req)
-		require.Error(t, err)
+		_, err = repo.UpdateBalance(ctx, req)
 		assert.Equal(t, types.ErrInsufficientFunds, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("frozen wallet", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+
+		mock.ExpectBegin()
 
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(sqlmock.NewRows(walletForUpdateColumns).AddRow(500, 1, 0, nil, nil, nil, nil, 0, 0, nil, nil, true))
+
+		mock.ExpectRollback()
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3`).
+			WithArgs(req.ReferenceID, types.FailureWalletFrozen, types.ErrWalletFrozen.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, err = repo.UpdateBalance(ctx, req)
+		assert.Equal(t, types.ErrWalletFrozen, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -181,25 +322,21 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(500, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		rows := walletRows(500, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet SET balance =  $1, version = version  + 1, updated_at = NOW ( ) WHERE wallet_uuid =  $2 AND version =  $3`).
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
 			WithArgs(600, req.WalletUUID, 1).
 			WillReturnResult(sqlmock.NewResult(1, 0)) // 0 rows affected
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'FAILED', applied_at = NOW ( ) WHERE reference_id =  $1 AND status = 'PENDING'`).
-			WithArgs(req.ReferenceID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-
 		mock.ExpectRollback()
 
-		err = repo.UpdateBalance(ctx, req)
+		_, err = repo.UpdateBalance(ctx, req)
 		require.Error(t, err)
 		assert.Equal(t, types.ErrConcurrentUpdate, err)
 
@@ -221,21 +358,21 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(500, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		rows := walletRows(500, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'FAILED', applied_at = NOW ( ) WHERE reference_id =  $1 AND status = 'PENDING'`).
-			WithArgs(req.ReferenceID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-
 		mock.ExpectRollback()
 
-		err = repo.UpdateBalance(ctx, req)
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3, locked_until = NULL\s+WHERE reference_id = \$1 AND status = 'PENDING'`).
+			WithArgs(req.ReferenceID, types.FailureInvalidOperation, types.ErrInvalidOperation.Error()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err = repo.UpdateBalance(ctx, req)
 		require.Error(t, err)
 		assert.Equal(t, types.ErrInvalidOperation, err)
 
@@ -255,12 +392,12 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnError(assert.AnError)
 
 		mock.ExpectRollback()
 
-		err = repo.UpdateBalance(ctx, req)
+		_, err = repo.UpdateBalance(ctx, req)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to log operation")
 
@@ -280,28 +417,133 @@ func TestRepository_UpdateBalance(t *testing.T) {
 		mock.ExpectBegin()
 
 		mock.ExpectExec(`INSERT INTO wallet_operations`).
-			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		rows := sqlmock.NewRows([]string{"balance", "version"}).AddRow(500, 1)
-		mock.ExpectQuery(`SELECT balance, version FROM wallet WHERE wallet_uuid =  $1 FOR UPDATE`).
+		rows := walletRows(500, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
 			WithArgs(req.WalletUUID).
 			WillReturnRows(rows)
 
-		mock.ExpectExec(`UPDATE wallet SET balance =  $1, version = version  + 1, updated_at = NOW ( ) WHERE wallet_uuid =  $2 AND version =  $3`).
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
 			WithArgs(600, req.WalletUUID, 1).
 			WillReturnError(assert.AnError)
 
-		mock.ExpectExec(`UPDATE wallet_operations SET status = 'FAILED', applied_at = NOW ( ) WHERE reference_id =  $1 AND status = 'PENDING'`).
-			WithArgs(req.ReferenceID).
+		mock.ExpectRollback()
+
+		_, err = repo.UpdateBalance(ctx, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update wallet")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicate reference_id on insert", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+
+		mock.ExpectBegin()
+
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "wallet_operations_reference_id_key"})
+
+		mock.ExpectRollback()
+
+		_, err = repo.UpdateBalance(ctx, req)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, types.ErrOperationExists)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reference_id taken by another operation", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+
+		mock.ExpectBegin()
+
+		// ON CONFLICT не трогает строку, если это не та же операция в очереди
+		mock.ExpectExec(`INSERT INTO wallet_operations.+ON CONFLICT \(reference_id\) DO UPDATE SET locked_until = NULL\s+WHERE wallet_operations.async AND wallet_operations.status = 'PENDING'`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectRollback()
+
+		_, err = repo.UpdateBalance(ctx, req)
+		assert.ErrorIs(t, err, types.ErrOperationExists)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("deadlock on update wallet", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+		ctx := context.Background()
+
+		req := newValidRequest()
+
+		mock.ExpectBegin()
+
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		rows := walletRows(500, 1)
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(req.WalletUUID).
+			WillReturnRows(rows)
+
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1, version = version \+ 1, updated_at = NOW\(\)\s+WHERE wallet_uuid = \$2 AND version = \$3`).
+			WithArgs(600, req.WalletUUID, 1).
+			WillReturnError(&pgconn.PgError{Code: "40P01"})
+
 		mock.ExpectRollback()
 
-		err = repo.UpdateBalance(ctx, req)
+		_, err = repo.UpdateBalance(ctx, req)
 		require.Error(t, err)
+		assert.ErrorIs(t, err, types.ErrDeadlock)
 		assert.Contains(t, err.Error(), "failed to update wallet")
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+const walletForUpdatePattern = `SELECT w.balance, w.version, w.credit_limit,.+FROM wallet w.+WHERE w.wallet_uuid = \$1`
+
+var walletForUpdateColumns = []string{
+	"balance", "version", "credit_limit",
+	"max_withdrawal", "daily_withdrawal", "monthly_withdrawal", "max_balance",
+	"flat_fee", "percent_bps", "min_fee", "max_fee",
+	"frozen",
+}
+
+// walletRows is a wallet without credit and limits.
+func walletRows(balance, version int) *sqlmock.Rows {
+	return creditWalletRows(balance, version, 0)
+}
+
+func creditWalletRows(balance, version, creditLimit int) *sqlmock.Rows {
+	return sqlmock.NewRows(walletForUpdateColumns).AddRow(balance, version, creditLimit, nil, nil, nil, nil, 0, 0, nil, nil, false)
+}
+
+// feeWalletRows is a wallet without credit and limits whose tier charges a
+// flat fee plus percentBps basis points.
+func feeWalletRows(balance, flat, percentBps int) *sqlmock.Rows {
+	return sqlmock.NewRows(walletForUpdateColumns).AddRow(balance, 1, 0, nil, nil, nil, nil, flat, percentBps, nil, nil, false)
+}
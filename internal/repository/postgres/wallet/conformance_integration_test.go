@@ -0,0 +1,22 @@
+//go:build integration
+
+package walletpostgresql
+
+import (
+	"testing"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/ledgertest"
+	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/readwritertest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	repo := NewRepository(testDB)
+	readwritertest.Run(t, func(t *testing.T) walletservice.ReadWriter {
+		return repo
+	})
+}
+
+func TestService_LedgerInvariants(t *testing.T) {
+	ledgertest.Run(t, NewRepository(testDB))
+}
@@ -0,0 +1,36 @@
+package walletpostgresql
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// operationDetailsColumns selects the details of an operation, see
+// scanDetails.
+const operationDetailsColumns = `COALESCE(description, ''), COALESCE(external_ref, ''), COALESCE(metadata::text, '')`
+
+// detailsArgs returns the description, external reference and metadata as
+// query arguments; empty values are stored as NULL through NULLIF.
+func detailsArgs(d types.OperationDetails) (string, string, string, error) {
+	if len(d.Metadata) == 0 {
+		return d.Description, d.ExternalRef, "", nil
+	}
+	metadata, err := json.Marshal(d.Metadata)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return d.Description, d.ExternalRef, string(metadata), nil
+}
+
+// scanDetails decodes the metadata read by operationDetailsColumns.
+func scanDetails(d *types.OperationDetails, metadata string) error {
+	if metadata == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(metadata), &d.Metadata); err != nil {
+		return fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return nil
+}
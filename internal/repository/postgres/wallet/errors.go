@@ -0,0 +1,72 @@
+package walletpostgresql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes the repository knows how to classify.
+const (
+	codeDeadlockDetected     = "40P01"
+	codeSerializationFailure = "40001"
+	codeUniqueViolation      = "23505"
+	codeCheckViolation       = "23514"
+	codeForeignKeyViolation  = "23503"
+	codeQueryCanceled        = "57014"
+)
+
+// Constraint names generated by postgres for the schema in migrations.
+const (
+	constraintReferenceIDUnique  = "wallet_operations_reference_id_key"
+	constraintBalanceNonNegative = "wallet_balance_check"
+	constraintOperationsWallet   = "wallet_operations_wallet_id_fkey"
+	constraintLimitsWallet       = "wallet_limits_wallet_uuid_fkey"
+	constraintLimitsTier         = "wallet_limits_tier_fkey"
+)
+
+// classifyError maps a postgres error to one of the typed errors in
+// internal/types based on its SQLSTATE code. The original error stays in the
+// chain. Errors that are not *pgconn.PgError or have an unknown code are
+// returned unchanged.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case codeDeadlockDetected:
+		return fmt.Errorf("%w: %w", types.ErrDeadlock, err)
+	case codeSerializationFailure:
+		return fmt.Errorf("%w: %w", types.ErrSerializationFailure, err)
+	case codeUniqueViolation:
+		if pgErr.ConstraintName == constraintReferenceIDUnique {
+			return fmt.Errorf("%w: %w", types.ErrOperationExists, err)
+		}
+		return fmt.Errorf("%w: %w", types.ErrUniqueViolation, err)
+	case codeCheckViolation:
+		if pgErr.ConstraintName == constraintBalanceNonNegative {
+			return fmt.Errorf("%w: %w", types.ErrInsufficientFunds, err)
+		}
+		return fmt.Errorf("%w: %w", types.ErrCheckViolation, err)
+	case codeForeignKeyViolation:
+		switch pgErr.ConstraintName {
+		case constraintOperationsWallet, constraintLimitsWallet:
+			return fmt.Errorf("%w: %w", types.ErrWalletNotFound, err)
+		case constraintLimitsTier:
+			return fmt.Errorf("%w: %w", types.ErrUnknownTier, err)
+		}
+		return fmt.Errorf("%w: %w", types.ErrForeignKeyViolation, err)
+	case codeQueryCanceled:
+		return fmt.Errorf("%w: %w", types.ErrQueryCanceled, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,105 @@
+package walletpostgresql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "deadlock",
+			err:      &pgconn.PgError{Code: "40P01"},
+			expected: types.ErrDeadlock,
+		},
+		{
+			name:     "serialization failure",
+			err:      &pgconn.PgError{Code: "40001"},
+			expected: types.ErrSerializationFailure,
+		},
+		{
+			name:     "duplicate reference_id",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: "wallet_operations_reference_id_key"},
+			expected: types.ErrOperationExists,
+		},
+		{
+			name:     "other unique violation",
+			err:      &pgconn.PgError{Code: "23505", ConstraintName: "some_other_key"},
+			expected: types.ErrUniqueViolation,
+		},
+		{
+			name:     "negative balance",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "wallet_balance_check"},
+			expected: types.ErrInsufficientFunds,
+		},
+		{
+			name:     "other check violation",
+			err:      &pgconn.PgError{Code: "23514", ConstraintName: "wallet_operations_amount_check"},
+			expected: types.ErrCheckViolation,
+		},
+		{
+			name:     "limits of missing wallet",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "wallet_limits_wallet_uuid_fkey"},
+			expected: types.ErrWalletNotFound,
+		},
+		{
+			name:     "unknown limit tier",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "wallet_limits_tier_fkey"},
+			expected: types.ErrUnknownTier,
+		},
+		{
+			name:     "operation for missing wallet",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "wallet_operations_wallet_id_fkey"},
+			expected: types.ErrWalletNotFound,
+		},
+		{
+			name:     "other foreign key violation",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "some_other_fkey"},
+			expected: types.ErrForeignKeyViolation,
+		},
+		{
+			name:     "query canceled",
+			err:      &pgconn.PgError{Code: "57014"},
+			expected: types.ErrQueryCanceled,
+		},
+		{
+			name:     "wrapped pg error",
+			err:      fmt.Errorf("exec: %w", &pgconn.PgError{Code: "40P01"}),
+			expected: types.ErrDeadlock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.expected)
+
+			var pgErr *pgconn.PgError
+			assert.True(t, errors.As(err, &pgErr), "original pg error must stay in the chain")
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, classifyError(nil))
+	})
+
+	t.Run("unknown code is returned unchanged", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "42P01"}
+		assert.Same(t, pgErr, classifyError(pgErr))
+	})
+
+	t.Run("non pg error is returned unchanged", func(t *testing.T) {
+		assert.Equal(t, assert.AnError, classifyError(assert.AnError))
+	})
+}
@@ -0,0 +1,102 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+)
+
+// feeReferenceID derives the reference of the FEE operation from the one of
+// the withdrawal, so that a retried withdrawal cannot be charged twice.
+func feeReferenceID(referenceID string) string {
+	return uuid.NewSHA1(uuid.MustParse(types.FeeWalletUUID), []byte(referenceID)).String()
+}
+
+// logFee records the fee charged for the operation referenceID as an applied
+// FEE operation of the fee wallet.
+func logFee(ctx context.Context, tx *sql.Tx, referenceID string, amount int) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO wallet_operations
+            (wallet_id, operation_type, amount, reference_id, parent_reference_id, status, created_at, applied_at)
+        VALUES ($1, 'FEE', $2, $3, $4, 'APPLIED', NOW(), NOW())
+    `, types.FeeWalletUUID, amount, feeReferenceID(referenceID), referenceID)
+
+	if err != nil {
+		return fmt.Errorf("failed to log fee: %w", classifyError(err))
+	}
+	return nil
+}
+
+// feeCharge is a fee logged in the current transaction that is yet to be
+// credited to the fee wallet.
+type feeCharge struct {
+	referenceID string
+	amount      int
+}
+
+// feeCharges appends the fee charged for req, if any, to charges.
+func feeCharges(charges []feeCharge, req *types.WalletUpdateRequest, fee types.Fee) []feeCharge {
+	if fee.Total == 0 {
+		return charges
+	}
+	return append(charges, feeCharge{referenceID: feeReferenceID(req.ReferenceID), amount: fee.Total})
+}
+
+// creditFees adds the fees collected in tx to the fee wallet and fills in the
+// balance after and the time of each FEE operation. It is called once per
+// transaction, after every customer wallet is locked, so that the fee wallet
+// row is always locked last and held as briefly as possible.
+func creditFees(ctx context.Context, tx *sql.Tx, charges []feeCharge) error {
+	if len(charges) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, charge := range charges {
+		total += charge.amount
+	}
+
+	// Время берётся под блокировкой кошелька комиссий, как в applyOperation.
+	// Одно на все комиссии транзакции: между собой их упорядочивает id
+	var (
+		balance   int
+		appliedAt time.Time
+	)
+	err := tx.QueryRowContext(ctx, `
+        UPDATE wallet SET balance = balance + $1, version = version + 1, updated_at = NOW()
+        WHERE wallet_uuid = $2
+        RETURNING balance, clock_timestamp()
+    `, total, types.FeeWalletUUID).Scan(&balance, &appliedAt)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("failed to credit fees: fee wallet %s does not exist", types.FeeWalletUUID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to credit fees: %w", classifyError(err))
+	}
+
+	referenceIDs := make([]string, len(charges))
+	balances := make([]int, len(charges))
+	balance -= total
+	for i, charge := range charges {
+		balance += charge.amount
+		referenceIDs[i] = charge.referenceID
+		balances[i] = balance
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE wallet_operations o SET balance_after = c.balance_after, applied_at = $3
+        FROM unnest($1::uuid[], $2::integer[]) AS c(reference_id, balance_after)
+        WHERE o.reference_id = c.reference_id
+    `, referenceIDs, balances, appliedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to record fee wallet balance: %w", classifyError(err))
+	}
+
+	return nil
+}
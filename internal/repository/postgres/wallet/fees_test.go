@@ -0,0 +1,117 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_UpdateBalance_Fees(t *testing.T) {
+	const (
+		walletUUID  = "a1b2c3e4-5678-9012-3456-789012345678"
+		referenceID = "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+	)
+
+	expectWithdraw := func(mock sqlmock.Sqlmock, amount int, wallet *sqlmock.Rows) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(walletUUID, types.OperationTypeWithdraw, amount, referenceID, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(walletForUpdatePattern).
+			WithArgs(walletUUID).
+			WillReturnRows(wallet)
+	}
+
+	t.Run("fee is charged and credited to the fee wallet", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+
+		expectWithdraw(mock, 1000, feeWalletRows(2000, 5, 150))
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1`).
+			WithArgs(980, walletUUID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED'`).
+			WithArgs(referenceID, 980).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO wallet_operations\s+\(wallet_id, operation_type, amount, reference_id, parent_reference_id, status, created_at, applied_at\)\s+VALUES \(\$1, 'FEE'`).
+			WithArgs(types.FeeWalletUUID, 20, feeReferenceID(referenceID), referenceID).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		appliedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`UPDATE wallet SET balance = balance \+ \$1.*RETURNING balance, clock_timestamp\(\)`).
+			WithArgs(20, types.FeeWalletUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"balance", "clock_timestamp"}).AddRow(520, appliedAt))
+		mock.ExpectExec(`UPDATE wallet_operations o SET balance_after = c.balance_after, applied_at = \$3`).
+			WithArgs([]string{feeReferenceID(referenceID)}, []int{520}, appliedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		fee, err := repo.UpdateBalance(context.Background(),
+			types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 1000, referenceID))
+		require.NoError(t, err)
+		assert.Equal(t, types.Fee{Flat: 5, Percent: 15, Total: 20}, fee)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fee counts against available funds", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+
+		expectWithdraw(mock, 1000, feeWalletRows(1010, 20, 0))
+		mock.ExpectRollback()
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3`).
+			WithArgs(referenceID, types.FailureInsufficientFunds, types.ErrInsufficientFunds.Error()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, err = repo.UpdateBalance(context.Background(),
+			types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 1000, referenceID))
+		assert.Equal(t, types.ErrInsufficientFunds, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing fee wallet", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		repo := &Repository{db: db}
+
+		expectWithdraw(mock, 100, feeWalletRows(1000, 10, 0))
+		mock.ExpectExec(`UPDATE wallet SET balance = \$1`).
+			WithArgs(890, walletUUID, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED'`).
+			WithArgs(referenceID, 890).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectQuery(`UPDATE wallet SET balance = balance \+ \$1`).
+			WithArgs(10, types.FeeWalletUUID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err = repo.UpdateBalance(context.Background(),
+			types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 100, referenceID))
+		assert.ErrorContains(t, err, "fee wallet")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFeeReferenceID(t *testing.T) {
+	referenceID := "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+
+	assert.Equal(t, feeReferenceID(referenceID), feeReferenceID(referenceID))
+	assert.NotEqual(t, referenceID, feeReferenceID(referenceID))
+	assert.NotEqual(t, feeReferenceID(referenceID), feeReferenceID("6c8d1c6f-3b65-4e5f-8b74-2a1f5b2e3d4c"))
+}
@@ -0,0 +1,274 @@
+//go:build integration
+
+package walletpostgresql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWallet(t *testing.T, repo *Repository, balance int) string {
+	t.Helper()
+	ctx := context.Background()
+
+	wallet, err := repo.CreateWallet(ctx, "", 0)
+	require.NoError(t, err)
+	if balance > 0 {
+		_, err = repo.UpdateBalance(ctx,
+			types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, balance, uuid.NewString()))
+		require.NoError(t, err)
+	}
+	return wallet.WalletUUID
+}
+
+func isConflict(err error) bool {
+	return errors.Is(err, types.ErrConcurrentUpdate) ||
+		errors.Is(err, types.ErrDeadlock) ||
+		errors.Is(err, types.ErrSerializationFailure)
+}
+
+func TestIntegration_UpdateBalance(t *testing.T) {
+	repo := NewRepository(testDB)
+	ctx := context.Background()
+
+	t.Run("deposit and withdraw", func(t *testing.T) {
+		walletUUID := newTestWallet(t, repo, 0)
+		deposit := uuid.NewString()
+
+		_, err := repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 300, deposit))
+		require.NoError(t, err)
+		_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 120, uuid.NewString()))
+		require.NoError(t, err)
+
+		funds, err := repo.GetBalance(ctx, walletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewFunds(180, 0), funds)
+
+		exists, err := repo.CheckOperationExists(ctx, deposit)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("duplicate reference is classified", func(t *testing.T) {
+		walletUUID := newTestWallet(t, repo, 0)
+		referenceID := uuid.NewString()
+
+		_, err := repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 10, referenceID))
+		require.NoError(t, err)
+		_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 10, referenceID))
+		assert.ErrorIs(t, err, types.ErrOperationExists)
+
+		funds, err := repo.GetBalance(ctx, walletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, 10, funds.Balance)
+	})
+
+	t.Run("missing wallet is classified", func(t *testing.T) {
+		referenceID := uuid.NewString()
+		_, err := repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(uuid.NewString(), types.OperationTypeDeposit, 10, referenceID))
+		assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+		exists, err := repo.CheckOperationExists(ctx, referenceID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("insufficient funds leaves nothing behind", func(t *testing.T) {
+		walletUUID := newTestWallet(t, repo, 50)
+		referenceID := uuid.NewString()
+
+		_, err := repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 51, referenceID))
+		assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+
+		exists, err := repo.CheckOperationExists(ctx, referenceID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		walletUUID := newTestWallet(t, repo, 50)
+		referenceID := uuid.NewString()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := repo.UpdateBalance(cancelled, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 10, referenceID))
+		require.ErrorIs(t, err, context.Canceled)
+
+		funds, err := repo.GetBalance(ctx, walletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, 50, funds.Balance)
+
+		exists, err := repo.CheckOperationExists(ctx, referenceID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		// Ссылка свободна, операцию можно повторить
+		_, err = repo.UpdateBalance(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 10, referenceID))
+		require.NoError(t, err)
+	})
+}
+
+// TestIntegration_ConcurrentWriters hits one wallet from many connections.
+// Conflicting updates are retried like the service does, so in the end every
+// operation must be applied exactly once.
+func TestIntegration_ConcurrentWriters(t *testing.T) {
+	const (
+		writers   = 16
+		perWriter = 25
+	)
+
+	repo := NewRepository(testDB)
+	ctx := context.Background()
+	walletUUID := newTestWallet(t, repo, 1000)
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		references []string
+		conflicts  int
+	)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				operation := types.OperationTypeDeposit
+				if (w+i)%2 == 1 {
+					operation = types.OperationTypeWithdraw
+				}
+				req := types.NewWalletUpdateRequest(walletUUID, operation, 3, uuid.NewString())
+
+				for {
+					_, err := repo.UpdateBalance(ctx, req)
+					if err == nil {
+						break
+					}
+					if !isConflict(err) {
+						t.Errorf("unexpected error: %v", err)
+						return
+					}
+					mu.Lock()
+					conflicts++
+					mu.Unlock()
+				}
+
+				mu.Lock()
+				references = append(references, req.ReferenceID)
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	t.Logf("%d conflicts retried", conflicts)
+
+	require.Len(t, references, writers*perWriter)
+
+	// Пополнений и списаний поровну, баланс должен вернуться к исходному
+	funds, err := repo.GetBalance(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, funds.Balance)
+
+	for _, referenceID := range references {
+		exists, err := repo.CheckOperationExists(ctx, referenceID)
+		require.NoError(t, err)
+		assert.True(t, exists, "operation %s is lost", referenceID)
+	}
+
+	mismatches, err := repo.ReconcileBalances(ctx)
+	require.NoError(t, err)
+	for _, m := range mismatches {
+		assert.NotEqual(t, walletUUID, m.WalletUUID, "balance_after disagrees with the balance")
+	}
+}
+
+// TestIntegration_ConcurrentBatches runs batches that touch the same wallets
+// in opposite order. Wallets are locked in a fixed order, so they must never
+// deadlock.
+func TestIntegration_ConcurrentBatches(t *testing.T) {
+	const rounds = 20
+
+	repo := NewRepository(testDB)
+	ctx := context.Background()
+	walletA := newTestWallet(t, repo, 1000)
+	walletB := newTestWallet(t, repo, 1000)
+
+	var wg sync.WaitGroup
+	transfer := func(from, to string) {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			err := repo.UpdateBalanceBatch(ctx, []*types.WalletUpdateRequest{
+				types.NewWalletUpdateRequest(from, types.OperationTypeWithdraw, 5, uuid.NewString()),
+				types.NewWalletUpdateRequest(to, types.OperationTypeDeposit, 5, uuid.NewString()),
+			})
+			if err != nil {
+				t.Errorf("transfer %d failed: %v", i, err)
+			}
+		}
+	}
+
+	wg.Add(2)
+	go transfer(walletA, walletB)
+	go transfer(walletB, walletA)
+	wg.Wait()
+
+	balances, err := repo.GetBalances(ctx, []string{walletA, walletB})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, balances[walletA].Balance)
+	assert.Equal(t, 1000, balances[walletB].Balance)
+}
+
+// TestIntegration_CommitOrder commits an operation whose transaction started
+// first after one that started later. Balance history must follow the order
+// in which the balance changed, not the one in which transactions started.
+func TestIntegration_CommitOrder(t *testing.T) {
+	repo := NewRepository(testDB)
+	ctx := context.Background()
+	walletUUID := newTestWallet(t, repo, 1000)
+	from := time.Now()
+
+	tx, err := testDB.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	// NOW() в этой транзакции теперь раньше, чем у следующей операции
+	_, err = tx.ExecContext(ctx, `SELECT NOW()`)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	later := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 100, uuid.NewString())
+	_, err = repo.UpdateBalance(ctx, later)
+	require.NoError(t, err)
+
+	earlier := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 50, uuid.NewString())
+	_, err = applyOperation(ctx, tx, earlier)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	balance, err := repo.GetBalanceAt(ctx, walletUUID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 950, balance)
+
+	w := &recordingStatementWriter{}
+	require.NoError(t, repo.StreamStatement(ctx, walletUUID, from, time.Now(), w))
+	assert.Equal(t, 1000, w.opening)
+	if assert.Len(t, w.entries, 2) {
+		assert.Equal(t, later.ReferenceID, w.entries[0].ReferenceID)
+		assert.Equal(t, 900, w.entries[0].Balance)
+		assert.Equal(t, earlier.ReferenceID, w.entries[1].ReferenceID)
+		assert.Equal(t, 950, w.entries[1].Balance)
+	}
+	assert.Equal(t, 950, w.closing)
+
+	mismatches, err := repo.ReconcileBalances(ctx)
+	require.NoError(t, err)
+	for _, m := range mismatches {
+		assert.NotEqual(t, walletUUID, m.WalletUUID, "balance_after disagrees with the balance")
+	}
+}
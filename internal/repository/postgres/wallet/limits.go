@@ -0,0 +1,56 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+// withdrawnQuery sums the applied withdrawals of a wallet in the current UTC
+// day and month. It runs after the wallet row is read inside the same
+// transaction, so a withdrawal committed in between changes the wallet
+// version and the update fails with ErrConcurrentUpdate instead of slipping
+// past the limit.
+const withdrawnQuery = `
+        SELECT
+            COALESCE(SUM(amount) FILTER (WHERE applied_at >= date_trunc('day', NOW(), 'UTC')), 0),
+            COALESCE(SUM(amount), 0)
+        FROM wallet_operations
+        WHERE wallet_id = $1 AND operation_type = 'WITHDRAW' AND status = 'APPLIED'
+            AND applied_at >= date_trunc('month', NOW(), 'UTC')
+    `
+
+// checkLimits fails with *types.LimitExceededError if applying req would
+// break one of the wallet limits.
+func checkLimits(ctx context.Context, tx *sql.Tx, req *types.WalletUpdateRequest, newBalance int, limits types.LimitValues) error {
+	if req.Operation == types.OperationTypeDeposit {
+		if limits.MaxBalance != nil && newBalance > *limits.MaxBalance {
+			return &types.LimitExceededError{Limit: types.LimitMaxBalance, Max: *limits.MaxBalance, Value: newBalance}
+		}
+		return nil
+	}
+
+	if limits.MaxWithdrawal != nil && req.Amount > *limits.MaxWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitMaxWithdrawal, Max: *limits.MaxWithdrawal, Value: req.Amount}
+	}
+
+	if limits.DailyWithdrawal == nil && limits.MonthlyWithdrawal == nil {
+		return nil
+	}
+
+	var daily, monthly int
+	if err := tx.QueryRowContext(ctx, withdrawnQuery, req.WalletUUID).Scan(&daily, &monthly); err != nil {
+		return fmt.Errorf("failed to sum withdrawals: %w", classifyError(err))
+	}
+
+	if limits.DailyWithdrawal != nil && daily+req.Amount > *limits.DailyWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitDailyWithdrawal, Max: *limits.DailyWithdrawal, Value: daily + req.Amount}
+	}
+	if limits.MonthlyWithdrawal != nil && monthly+req.Amount > *limits.MonthlyWithdrawal {
+		return &types.LimitExceededError{Limit: types.LimitMonthlyWithdrawal, Max: *limits.MonthlyWithdrawal, Value: monthly + req.Amount}
+	}
+
+	return nil
+}
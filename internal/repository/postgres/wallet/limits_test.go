@@ -0,0 +1,128 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_UpdateBalance_Limits(t *testing.T) {
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+	const withdrawnPattern = `SELECT\s+COALESCE\(SUM\(amount\) FILTER .+FROM wallet_operations\s+WHERE wallet_id = \$1 AND operation_type = 'WITHDRAW'`
+
+	// limits: max_withdrawal, daily_withdrawal, monthly_withdrawal, max_balance
+	limitedWallet := func(balance int, limits ...driver.Value) *sqlmock.Rows {
+		values := append([]driver.Value{balance, 1, 0}, limits...)
+		return sqlmock.NewRows(walletForUpdateColumns).AddRow(append(values, 0, 0, nil, nil, false)...)
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		amount    int
+		wallet    *sqlmock.Rows
+		withdrawn *sqlmock.Rows
+		limit     string
+	}{
+		{
+			name:      "single withdrawal above max",
+			operation: types.OperationTypeWithdraw,
+			amount:    600,
+			wallet:    limitedWallet(1000, 500, nil, nil, nil),
+			limit:     types.LimitMaxWithdrawal,
+		},
+		{
+			name:      "daily total exceeded",
+			operation: types.OperationTypeWithdraw,
+			amount:    300,
+			wallet:    limitedWallet(1000, nil, 1000, 5000, nil),
+			withdrawn: sqlmock.NewRows([]string{"daily", "monthly"}).AddRow(800, 800),
+			limit:     types.LimitDailyWithdrawal,
+		},
+		{
+			name:      "monthly total exceeded",
+			operation: types.OperationTypeWithdraw,
+			amount:    300,
+			wallet:    limitedWallet(1000, nil, 1000, 5000, nil),
+			withdrawn: sqlmock.NewRows([]string{"daily", "monthly"}).AddRow(0, 4800),
+			limit:     types.LimitMonthlyWithdrawal,
+		},
+		{
+			name:      "deposit above max balance",
+			operation: types.OperationTypeDeposit,
+			amount:    300,
+			wallet:    limitedWallet(900, nil, nil, nil, 1000),
+			limit:     types.LimitMaxBalance,
+		},
+		{
+			name:      "withdrawal within limits",
+			operation: types.OperationTypeWithdraw,
+			amount:    100,
+			wallet:    limitedWallet(1000, 500, 1000, 5000, 1000),
+			withdrawn: sqlmock.NewRows([]string{"daily", "monthly"}).AddRow(800, 4800),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := &Repository{db: db}
+			req := &types.WalletUpdateRequest{
+				WalletUUID:  walletUUID,
+				Operation:   tt.operation,
+				Amount:      tt.amount,
+				ReferenceID: "ref-limits",
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectExec(`INSERT INTO wallet_operations`).
+				WithArgs(req.WalletUUID, req.Operation, req.Amount, req.ReferenceID, "", "", "").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectQuery(walletForUpdatePattern).
+				WithArgs(req.WalletUUID).
+				WillReturnRows(tt.wallet)
+			if tt.withdrawn != nil {
+				mock.ExpectQuery(withdrawnPattern).
+					WithArgs(req.WalletUUID).
+					WillReturnRows(tt.withdrawn)
+			}
+
+			if tt.limit == "" {
+				mock.ExpectExec(`UPDATE wallet SET balance`).
+					WithArgs(900, req.WalletUUID, 1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'APPLIED'`).
+					WithArgs(req.ReferenceID, 900).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+
+				_, err = repo.UpdateBalance(context.Background(), req)
+				require.NoError(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			mock.ExpectRollback()
+			mock.ExpectExec(`UPDATE wallet_operations\s+SET status = 'FAILED', applied_at = NOW\(\), failure_code = \$2, failure_message = \$3`).
+				WithArgs(req.ReferenceID, types.FailureLimitExceeded, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			_, err = repo.UpdateBalance(context.Background(), req)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, types.ErrLimitExceeded)
+
+			var limitErr *types.LimitExceededError
+			require.ErrorAs(t, err, &limitErr)
+			assert.Equal(t, tt.limit, limitErr.Limit)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package walletpostgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/artyomkorchagin/wallet-task/migrations"
+)
+
+// Migrator applies the migrations embedded in the binary. Every run holds a
+// Postgres advisory lock, so replicas starting together apply them once.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// MigrationStatus is whether a migration has been applied.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Source    string     `json:"source"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// MigrationResult describes a migration applied or rolled back by a run.
+type MigrationResult struct {
+	Version   int64         `json:"version"`
+	Source    string        `json:"source"`
+	Direction string        `json:"direction"`
+	Duration  time.Duration `json:"duration"`
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS,
+		goose.WithSessionLocker(locker))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+// RunMigrations applies all pending migrations.
+func RunMigrations(ctx context.Context, db *sql.DB) error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) ([]MigrationResult, error) {
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return newMigrationResults(results...), nil
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) ([]MigrationResult, error) {
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back migration: %w", err)
+	}
+	return newMigrationResults(result), nil
+}
+
+// Redo rolls back the most recently applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) ([]MigrationResult, error) {
+	version, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB version: %w", err)
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("no migration to redo")
+	}
+
+	down, err := m.provider.ApplyVersion(ctx, version, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back migration %d: %w", version, err)
+	}
+
+	up, err := m.provider.ApplyVersion(ctx, version, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply migration %d: %w", version, err)
+	}
+
+	return newMigrationResults(down, up), nil
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 rolls back every migration.
+func (m *Migrator) To(ctx context.Context, version int64) ([]MigrationResult, error) {
+	current, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DB version: %w", err)
+	}
+
+	var results []*goose.MigrationResult
+	switch {
+	case version > current:
+		results, err = m.provider.UpTo(ctx, version)
+	case version < current:
+		results, err = m.provider.DownTo(ctx, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	return newMigrationResults(results...), nil
+}
+
+// Status reports every known migration in order.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{
+			Version: migration.Source.Version,
+			Source:  path.Base(migration.Source.Path),
+			Applied: migration.State == goose.StateApplied,
+		}
+		if status.Applied {
+			appliedAt := migration.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func newMigrationResults(results ...*goose.MigrationResult) []MigrationResult {
+	out := make([]MigrationResult, 0, len(results))
+	for _, result := range results {
+		out = append(out, MigrationResult{
+			Version:   result.Source.Version,
+			Source:    path.Base(result.Source.Path),
+			Direction: result.Direction,
+			Duration:  result.Duration,
+		})
+	}
+	return out
+}
+
+// Seed loads the development test data. It is never part of the migrations
+// and is safe to run more than once.
+func Seed(ctx context.Context, db *sql.DB) error {
+	files, err := fs.Glob(migrations.Seed, "seed/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list seed files: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, file := range files {
+		query, err := fs.ReadFile(migrations.Seed, file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", path.Base(file), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seed: %w", err)
+	}
+
+	return nil
+}
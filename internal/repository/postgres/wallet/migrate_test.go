@@ -0,0 +1,63 @@
+package walletpostgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/artyomkorchagin/wallet-task/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMigrator(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	sources := m.provider.ListSources()
+	require.NotEmpty(t, sources)
+	for i, source := range sources {
+		body, err := migrations.FS.ReadFile(source.Path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "a1b2c3e4-5678-9012-3456-789012345678", "seed data must not be a migration")
+		if i > 0 {
+			assert.Greater(t, source.Version, sources[i-1].Version)
+		}
+	}
+
+	// Базы, применившие старую миграцию с тестовыми данными, должны
+	// откатываться через её версию
+	versions := make([]int64, 0, len(sources))
+	for _, source := range sources {
+		versions = append(versions, source.Version)
+	}
+	assert.Contains(t, versions, int64(20250907230329))
+}
+
+func TestSeed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO wallet .+ON CONFLICT \(wallet_uuid\) DO NOTHING`).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+
+	require.NoError(t, Seed(context.Background(), db))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	t.Run("error rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO wallet`).WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err := Seed(context.Background(), db)
+		assert.ErrorContains(t, err, "test_data.sql")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
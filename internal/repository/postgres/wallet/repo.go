@@ -2,9 +2,6 @@ package walletpostgresql
 
 import (
 	"database/sql"
-	"fmt"
-
-	"github.com/pressly/goose"
 )
 
 type Repository struct {
@@ -16,15 +13,3 @@ func NewRepository(db *sql.DB) *Repository {
 		db: db,
 	}
 }
-
-func RunMigrations(db *sql.DB) error {
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
-	}
-
-	if err := goose.Up(db, "migrations"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,124 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 10 * time.Millisecond
+	DefaultMaxDelay    = 200 * time.Millisecond
+	DefaultJitter      = 0.5
+)
+
+var ErrAttemptsExhausted = errors.New("retry attempts exhausted")
+
+// Policy describes how an operation is retried: how many times, how long to
+// wait between attempts and which errors are worth retrying at all.
+// Zero values of MaxAttempts, BaseDelay and MaxDelay fall back to defaults.
+type Policy struct {
+	// Total number of attempts, including the first one
+	MaxAttempts int
+
+	// Delay before the second attempt, doubled for every next one
+	BaseDelay time.Duration
+
+	// Upper bound for a single delay
+	MaxDelay time.Duration
+
+	// Fraction of the delay (0..1) that is randomized to spread out
+	// callers that failed at the same moment
+	Jitter float64
+
+	// Reports whether an error is worth another attempt.
+	// When nil, every error is retried.
+	Retryable func(error) bool
+
+	// Called before sleeping between attempts, e.g. for logging
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultPolicy returns a policy with default limits that retries every error.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+		Jitter:      DefaultJitter,
+	}
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, the
+// attempts run out or ctx is done. It returns the number of attempts made.
+// When the attempts run out, the error wraps both ErrAttemptsExhausted and
+// the last error returned by fn.
+func (p Policy) Do(ctx context.Context, fn func(ctx context.Context) error) (int, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return attempt, nil
+		}
+
+		if p.Retryable != nil && !p.Retryable(err) {
+			return attempt, err
+		}
+
+		if attempt >= maxAttempts {
+			return attempt, fmt.Errorf("%w after %d attempts: %w", ErrAttemptsExhausted, attempt, err)
+		}
+
+		delay := p.Delay(attempt)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// Delay returns how long to wait after the given failed attempt:
+// exponential backoff from BaseDelay capped by MaxDelay, with Jitter applied.
+func (p Policy) Delay(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	jitter := p.Jitter
+	if jitter <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	randomized := time.Duration(float64(delay) * jitter)
+	return delay - randomized + time.Duration(rand.Int63n(int64(randomized)+1))
+}
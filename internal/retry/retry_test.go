@@ -0,0 +1,120 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTemporary = errors.New("temporary")
+
+func TestPolicy_Do(t *testing.T) {
+	policy := retry.Policy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Retryable:   func(err error) bool { return errors.Is(err, errTemporary) },
+	}
+
+	t.Run("success on first attempt", func(t *testing.T) {
+		calls := 0
+		attempts, err := policy.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("success after retries", func(t *testing.T) {
+		calls := 0
+		attempts, err := policy.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTemporary
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("non retryable error stops immediately", func(t *testing.T) {
+		attempts, err := policy.Do(context.Background(), func(ctx context.Context) error {
+			return assert.AnError
+		})
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, assert.AnError, err)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		attempts, err := policy.Do(context.Background(), func(ctx context.Context) error {
+			return errTemporary
+		})
+		assert.Equal(t, 3, attempts)
+		assert.ErrorIs(t, err, retry.ErrAttemptsExhausted)
+		assert.ErrorIs(t, err, errTemporary)
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		slow := policy
+		slow.BaseDelay = time.Hour
+		slow.MaxDelay = time.Hour
+
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts, err := slow.Do(ctx, func(ctx context.Context) error {
+			cancel()
+			return errTemporary
+		})
+		assert.Equal(t, 1, attempts)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, errTemporary)
+	})
+
+	t.Run("on retry hook", func(t *testing.T) {
+		var seen []int
+		hooked := policy
+		hooked.OnRetry = func(attempt int, delay time.Duration, err error) {
+			seen = append(seen, attempt)
+		}
+
+		_, _ = hooked.Do(context.Background(), func(ctx context.Context) error {
+			return errTemporary
+		})
+		assert.Equal(t, []int{1, 2}, seen)
+	})
+
+	t.Run("zero policy uses defaults", func(t *testing.T) {
+		attempts, err := retry.Policy{BaseDelay: time.Millisecond}.Do(context.Background(), func(ctx context.Context) error {
+			return errTemporary
+		})
+		assert.Equal(t, retry.DefaultMaxAttempts, attempts)
+		assert.ErrorIs(t, err, retry.ErrAttemptsExhausted)
+	})
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	policy := retry.Policy{
+		BaseDelay: 10 * time.Millisecond,
+		MaxDelay:  50 * time.Millisecond,
+	}
+
+	assert.Equal(t, 10*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, 20*time.Millisecond, policy.Delay(2))
+	assert.Equal(t, 40*time.Millisecond, policy.Delay(3))
+	assert.Equal(t, 50*time.Millisecond, policy.Delay(4))
+	assert.Equal(t, 50*time.Millisecond, policy.Delay(100))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(2)
+		assert.GreaterOrEqual(t, delay, 10*time.Millisecond)
+		assert.LessOrEqual(t, delay, 20*time.Millisecond)
+	}
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyHeader     = "X-API-Key"
+	apiKeyContextKey = "api_key"
+	ownerContextKey  = "owner_id"
+)
+
+// userScopes are granted to end users authenticated with a bearer token.
+// Which wallets they can touch is limited by ownership in the service.
+var userScopes = []string{types.ScopeWalletRead, types.ScopeWalletWrite}
+
+func (h *Handler) authEnabled() bool {
+	return h.apikeys != nil || h.tokens != nil
+}
+
+// authenticate rejects requests without valid credentials. A bearer token is
+// checked when JWT auth is enabled, an API key otherwise. The result is stored
+// in the context for requireScope and the services.
+func (h *Handler) authenticate(c *gin.Context) {
+	if token, ok := bearerToken(c); ok && h.tokens != nil {
+		h.authenticateUser(c, token)
+		return
+	}
+
+	if h.apikeys == nil {
+		h.abortWithError(c, types.ErrUnauthorized(types.ErrTokenMissing))
+		return
+	}
+
+	key, err := h.apikeys.Authenticate(c, c.GetHeader(apiKeyHeader))
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.Set(apiKeyContextKey, key)
+	h.addLogFields(c, zap.Int64("api_key_id", key.ID), zap.String("api_key_name", key.Name))
+	c.Next()
+}
+
+func (h *Handler) authenticateUser(c *gin.Context, token string) {
+	ownerID, err := h.tokens.Verify(token)
+	if err != nil {
+		h.requestLogger(c).Warn("bearer token rejected", zap.Error(err))
+		h.abortWithError(c, types.ErrUnauthorized(types.ErrTokenInvalid))
+		return
+	}
+
+	c.Set(ownerContextKey, ownerID)
+	c.Request = c.Request.WithContext(types.ContextWithOwner(c.Request.Context(), ownerID))
+	h.addLogFields(c, zap.String("owner_id", ownerID))
+	c.Next()
+}
+
+// requireScope rejects requests whose credentials do not grant scope.
+// It lets everything through when the handler is built without auth.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.authEnabled() {
+			c.Next()
+			return
+		}
+
+		if _, ok := c.Get(ownerContextKey); ok {
+			for _, s := range userScopes {
+				if s == scope {
+					c.Next()
+					return
+				}
+			}
+			h.abortWithError(c, types.ErrForbidden(fmt.Errorf("%w: %s", types.ErrInsufficientScope, scope)))
+			return
+		}
+
+		key, ok := apiKeyFrom(c)
+		if !ok {
+			h.abortWithError(c, types.ErrUnauthorized(types.ErrAPIKeyMissing))
+			return
+		}
+
+		if !key.HasScope(scope) {
+			h.requestLogger(c).Warn("api key lacks scope",
+				zap.Int64("api_key_id", key.ID),
+				zap.String("scope", scope))
+			h.abortWithError(c, types.ErrForbidden(fmt.Errorf("%w: %s", types.ErrInsufficientScope, scope)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func apiKeyFrom(c *gin.Context) (*types.APIKey, bool) {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := value.(*types.APIKey)
+	return key, ok
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_authenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+
+	setup := func(t *testing.T) (*MockWalletService, *MockAPIKeyService, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		apikeySvc := new(MockAPIKeyService)
+		handler := NewHandler(walletSvc, zaptest.NewLogger(t), WithAPIKeys(apikeySvc))
+		return walletSvc, apikeySvc, handler.InitRouter()
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		walletSvc, apikeySvc, r := setup(t)
+		apikeySvc.On("Authenticate", mock.Anything, "").
+			Return(nil, types.ErrUnauthorized(types.ErrAPIKeyMissing))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil))
+
+		assert.Equal(t, 401, w.Code)
+		assertProblem(t, w, types.CodeAPIKeyMissing, "api key is required")
+		walletSvc.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		walletSvc, apikeySvc, r := setup(t)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_bad").
+			Return(nil, types.ErrUnauthorized(types.ErrAPIKeyInvalid))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("X-API-Key", "wk_bad")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 401, w.Code)
+		walletSvc.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("read key can read", func(t *testing.T) {
+		walletSvc, apikeySvc, r := setup(t)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_read").
+			Return(&types.APIKey{ID: 1, Scopes: []string{types.ScopeWalletRead}}, nil)
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("X-API-Key", "wk_read")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"balance": 100, "creditLimit": 0, "available": 100}`, w.Body.String())
+	})
+
+	t.Run("read key cannot write", func(t *testing.T) {
+		walletSvc, apikeySvc, r := setup(t)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_read").
+			Return(&types.APIKey{ID: 1, Scopes: []string{types.ScopeWalletRead}}, nil)
+
+		w := httptest.NewRecorder()
+		body := `{"valletId":"` + walletUUID + `","operationType":"DEPOSIT","amount":100,"referenceId":"ref-1"}`
+		req := httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_read")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 403, w.Code)
+		assertProblem(t, w, types.CodeInsufficientScope, "api key does not grant the required scope: wallet:write")
+		walletSvc.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin key can write", func(t *testing.T) {
+		walletSvc, apikeySvc, r := setup(t)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_admin").
+			Return(&types.APIKey{ID: 2, Scopes: []string{types.ScopeAdmin}}, nil)
+		walletSvc.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.Fee{}, nil)
+
+		w := httptest.NewRecorder()
+		body := `{"valletId":"` + walletUUID + `","operationType":"DEPOSIT","amount":100,"referenceId":"ref-1"}`
+		req := httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_admin")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+
+	t.Run("status is public", func(t *testing.T) {
+		_, apikeySvc, r := setup(t)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+
+		assert.Equal(t, 200, w.Code)
+		apikeySvc.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_authenticateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+
+	setup := func(t *testing.T, opts ...Option) (*MockWalletService, *MockTokenVerifier, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		tokens := new(MockTokenVerifier)
+		handler := NewHandler(walletSvc, zaptest.NewLogger(t), append([]Option{WithJWT(tokens)}, opts...)...)
+		return walletSvc, tokens, handler.InitRouter()
+	}
+
+	ownedBy := func(ownerID string) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			owner, ok := types.OwnerFromContext(ctx)
+			return ok && owner == ownerID
+		})
+	}
+
+	t.Run("valid token passes owner to service", func(t *testing.T) {
+		walletSvc, tokens, r := setup(t)
+		tokens.On("Verify", "good-token").Return("user-1", nil)
+		walletSvc.On("GetBalance", ownedBy("user-1"), walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("Authorization", "Bearer good-token")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+
+	t.Run("foreign wallet is not found", func(t *testing.T) {
+		walletSvc, tokens, r := setup(t)
+		tokens.On("Verify", "good-token").Return("user-1", nil)
+		walletSvc.On("UpdateBalance", ownedBy("user-1"), mock.Anything).
+			Return(types.Fee{}, types.ErrNotFound(types.ErrWalletNotFound))
+
+		w := httptest.NewRecorder()
+		body := `{"valletId":"` + walletUUID + `","operationType":"WITHDRAW","amount":100}`
+		req := httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer good-token")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 404, w.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		walletSvc, tokens, r := setup(t)
+		tokens.On("Verify", "bad-token").Return("", errors.New("token is expired"))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("Authorization", "Bearer bad-token")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 401, w.Code)
+		assertProblem(t, w, types.CodeTokenInvalid, "bearer token is invalid")
+		walletSvc.AssertNotCalled(t, "GetBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		_, tokens, r := setup(t)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil))
+
+		assert.Equal(t, 401, w.Code)
+		assertProblem(t, w, types.CodeTokenMissing, "bearer token is required")
+		tokens.AssertNotCalled(t, "Verify", mock.Anything)
+	})
+
+	t.Run("api key still works next to jwt", func(t *testing.T) {
+		apikeySvc := new(MockAPIKeyService)
+		walletSvc, _, r := setup(t, WithAPIKeys(apikeySvc))
+		apikeySvc.On("Authenticate", mock.Anything, "wk_read").
+			Return(&types.APIKey{ID: 1, Scopes: []string{types.ScopeWalletRead}}, nil)
+		walletSvc.On("GetBalance", mock.MatchedBy(func(ctx context.Context) bool {
+			_, ok := types.OwnerFromContext(ctx)
+			return !ok
+		}), walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("X-API-Key", "wk_read")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+}
@@ -1,10 +1,16 @@
 package router
 
 import (
+	"errors"
 	"net/http"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
@@ -14,13 +20,98 @@ func (h *Handler) wrap(fn handlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := fn(c)
 		if err != nil {
-			if httpErr, ok := err.(types.HTTPError); ok {
-				h.logger.Error("error", zap.Error(httpErr))
-				c.JSON(httpErr.Code, gin.H{"error": httpErr.Err.Error()})
-			} else {
-				h.logger.Error("error", zap.Error(err))
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			}
+			h.writeError(c, err)
 		}
 	}
 }
+
+// abortWithError writes err like wrap does and stops the handler chain.
+func (h *Handler) abortWithError(c *gin.Context, err error) {
+	h.writeError(c, err)
+	c.Abort()
+}
+
+// writeError responds with the problem describing err. The error itself is
+// only logged: server errors reach the client without their text.
+func (h *Handler) writeError(c *gin.Context, err error) {
+	problem := types.NewProblem(err)
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+	problem.RequestID = requestID(c)
+
+	var validationErrs validator.ValidationErrors
+	if problem.Status < http.StatusInternalServerError && errors.As(err, &validationErrs) {
+		problem.SetFieldErrors(fieldErrors(validationErrs))
+	}
+
+	fields := []zap.Field{
+		zap.Int("status", problem.Status),
+		zap.String("code", problem.Code),
+		zap.Error(err),
+	}
+	logger := h.requestLogger(c)
+	if problem.Status >= http.StatusInternalServerError {
+		logger.Error("request failed", fields...)
+	} else {
+		logger.Info("request rejected", fields...)
+	}
+
+	c.Header("Content-Type", types.ProblemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// fieldErrors converts the errors of binding a request body. The fields are
+// named by their JSON names, see useJSONFieldNames.
+func fieldErrors(errs validator.ValidationErrors) []types.FieldError {
+	out := make([]types.FieldError, 0, len(errs))
+	for _, fe := range errs {
+		// Namespace начинается с имени структуры запроса
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		out = append(out, types.FieldError{Field: field, Message: validationMessage(fe)})
+	}
+	return out
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "min":
+		return "must have at least " + fe.Param() + " items"
+	case "max":
+		return "must have at most " + fe.Param() + " items"
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
+
+var jsonFieldNamesOnce sync.Once
+
+// useJSONFieldNames makes the binding validator report fields by their JSON
+// names, which is what clients send.
+func useJSONFieldNames() {
+	jsonFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	})
+}
@@ -1,13 +1,17 @@
 package router
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -32,7 +36,7 @@ func TestWrap_HTTPError(t *testing.T) {
 	ginHandler(c)
 
 	assert.Equal(t, 404, w.Code)
-	assert.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+	assertProblem(t, w, types.CodeNotFound, "not found")
 }
 
 func TestWrap_GenericError(t *testing.T) {
@@ -47,7 +51,8 @@ func TestWrap_GenericError(t *testing.T) {
 	ginHandler(c)
 
 	assert.Equal(t, 500, w.Code)
-	assert.JSONEq(t, `{"error": "assert.AnError general error for testing"}`, w.Body.String())
+	assertProblem(t, w, types.CodeInternal, "")
+	assert.NotContains(t, w.Body.String(), assert.AnError.Error())
 }
 
 func TestWrap_NoError(t *testing.T) {
@@ -62,3 +67,95 @@ func TestWrap_NoError(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Empty(t, w.Body.String())
 }
+
+// assertProblem checks that w holds a problem+json response with the code and
+// detail.
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, code, detail string) *types.Problem {
+	t.Helper()
+	assert.Equal(t, types.ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem types.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, code, problem.Code)
+	assert.Equal(t, "urn:wallet-task:problem:"+code, problem.Type)
+	assert.NotEmpty(t, problem.Title)
+	assert.Equal(t, w.Code, problem.Status)
+	assert.Equal(t, detail, problem.Detail)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.Equal(t, problem.RequestID, w.Header().Get("X-Request-ID"))
+	return &problem
+}
+
+func TestWrap_DatabaseErrorDetailHidden(t *testing.T) {
+	handler, w, c := setupHandler(t)
+
+	dbErr := &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint \"wallet_operations_reference_id_key\""}
+	ginHandler := handler.wrap(func(c *gin.Context) error {
+		return types.ErrConflict(fmt.Errorf("failed to log operation: %w: %w", types.ErrOperationExists, dbErr))
+	})
+
+	ginHandler(c)
+
+	assert.Equal(t, 409, w.Code)
+	assertProblem(t, w, types.CodeIdempotencyConflict, types.ErrOperationExists.Error())
+	assert.NotContains(t, w.Body.String(), "wallet_operations_reference_id_key")
+}
+
+func TestWrap_ServiceUnavailable(t *testing.T) {
+	handler, w, c := setupHandler(t)
+
+	ginHandler := handler.wrap(func(c *gin.Context) error {
+		return types.ErrServiceUnavailable(fmt.Errorf("failed to load wallet: %w", types.ErrQueryCanceled))
+	})
+
+	ginHandler(c)
+
+	assert.Equal(t, 503, w.Code)
+	assertProblem(t, w, types.CodeServiceUnavailable, "")
+}
+
+func TestWrap_RequestID(t *testing.T) {
+	handler, w, c := setupHandler(t)
+	c.Request = httptest.NewRequest("GET", "/api/v1/wallet/x", nil)
+	c.Request.Header.Set("X-Request-ID", "req-42")
+
+	ginHandler := handler.wrap(func(c *gin.Context) error {
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	})
+
+	ginHandler(c)
+
+	problem := assertProblem(t, w, types.CodeWalletNotFound, "wallet not found")
+	assert.Equal(t, "req-42", problem.RequestID)
+	assert.Equal(t, "/api/v1/wallet/x", problem.Instance)
+}
+
+func TestWrap_FieldErrors(t *testing.T) {
+	t.Run("binding errors", func(t *testing.T) {
+		handler, w, c := setupHandler(t)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/batch",
+			strings.NewReader(`{"mode": "atomic", "items": [{"valletId": "x", "operationType": "PAY", "amount": 1, "referenceId": "r"}]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.wrap(handler.updateBalanceBatch)(c)
+
+		assert.Equal(t, 400, w.Code)
+		var problem types.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, types.CodeValidationFailed, problem.Code)
+		assert.Equal(t, []types.FieldError{
+			{Field: "items[0].operationType", Message: "must be one of: DEPOSIT WITHDRAW"},
+		}, problem.Errors)
+	})
+
+	t.Run("service field error", func(t *testing.T) {
+		handler, w, c := setupHandler(t)
+
+		handler.wrap(func(c *gin.Context) error {
+			return types.ErrBadRequest(types.NewFieldError("amount", "amount must be positive"))
+		})(c)
+
+		problem := assertProblem(t, w, types.CodeValidationFailed, "amount must be positive")
+		assert.Equal(t, []types.FieldError{{Field: "amount", Message: "amount must be positive"}}, problem.Errors)
+	})
+}
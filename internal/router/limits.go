@@ -0,0 +1,35 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) getWalletLimits(c *gin.Context) error {
+	limits, err := h.walletservice.GetWalletLimits(c, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, limits)
+	return nil
+}
+
+func (h *Handler) setWalletLimits(c *gin.Context) error {
+	var update types.WalletLimitsUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	limits, err := h.walletservice.SetWalletLimits(c, c.Param("uuid"), &update)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, limits)
+	return nil
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_walletLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+	daily := 1000
+
+	setup := func(t *testing.T, scopes ...string) (*MockWalletService, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: scopes}, nil)
+		return walletSvc, NewHandler(walletSvc, zaptest.NewLogger(t), WithAPIKeys(apikeySvc)).InitRouter()
+	}
+
+	serve := func(r *gin.Engine, method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/admin/wallet/"+walletUUID+"/limits", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_test")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("get", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("GetWalletLimits", mock.Anything, walletUUID).Return(&types.WalletLimits{
+			WalletUUID:     walletUUID,
+			Tier:           "standard",
+			Limits:         types.LimitValues{DailyWithdrawal: &daily},
+			DailyWithdrawn: 300,
+		}, nil)
+
+		w := serve(r, "GET", "")
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{
+			"valletId": "`+walletUUID+`",
+			"tier": "standard",
+			"limits": {"maxWithdrawal": null, "dailyWithdrawal": 1000, "monthlyWithdrawal": null, "maxBalance": null},
+			"overrides": {"maxWithdrawal": null, "dailyWithdrawal": null, "monthlyWithdrawal": null, "maxBalance": null},
+			"dailyWithdrawn": 300,
+			"monthlyWithdrawn": 0
+		}`, w.Body.String())
+	})
+
+	t.Run("put", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("SetWalletLimits", mock.Anything, walletUUID, &types.WalletLimitsUpdate{
+			Tier:      "gold",
+			Overrides: types.LimitValues{DailyWithdrawal: &daily},
+		}).Return(&types.WalletLimits{WalletUUID: walletUUID, Tier: "gold"}, nil)
+
+		w := serve(r, "PUT", `{"tier": "gold", "overrides": {"dailyWithdrawal": 1000}}`)
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+
+	t.Run("put invalid body", func(t *testing.T) {
+		_, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "PUT", `{"tier": 1}`)
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("requires admin scope", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeWalletRead, types.ScopeWalletWrite)
+
+		w := serve(r, "GET", "")
+		assert.Equal(t, 403, w.Code)
+		walletSvc.AssertNotCalled(t, "GetWalletLimits", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"net/http"
+	"time"
+	"unicode"
+
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// requestIDHeader carries the ID that ties a response to the server logs.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the ID accepted from clients, a longer one is
+// replaced like a missing one.
+const maxRequestIDLength = 128
+
+// requestContext accepts the client's X-Request-ID or generates one, echoes
+// it in the response and stores a logger carrying the request ID and route in
+// the request context. Everything serving the request logs through it, see
+// logger.FromContext.
+func (h *Handler) requestContext(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	c.Header(requestIDHeader, id)
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	requestLogger := h.logger.With(
+		zap.String("request_id", id),
+		zap.String("method", c.Request.Method),
+		zap.String("route", route))
+	c.Request = c.Request.WithContext(logger.ContextWith(c.Request.Context(), requestLogger))
+	c.Next()
+}
+
+// addLogFields adds fields, such as the client identity, to the request
+// logger for the rest of the request.
+func (h *Handler) addLogFields(c *gin.Context, fields ...zap.Field) {
+	requestLogger := h.requestLogger(c).With(fields...)
+	c.Request = c.Request.WithContext(logger.ContextWith(c.Request.Context(), requestLogger))
+}
+
+// requestLogger returns the logger of the request, the handler's logger when
+// requestContext did not run.
+func (h *Handler) requestLogger(c *gin.Context) *zap.Logger {
+	if c.Request == nil {
+		return h.logger
+	}
+	return logger.FromContext(c.Request.Context(), h.logger)
+}
+
+// accessLog logs every request once it is served, replacing gin.Logger.
+func (h *Handler) accessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	status := c.Writer.Status()
+	level := zapcore.InfoLevel
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = zapcore.ErrorLevel
+	case status >= http.StatusBadRequest:
+		level = zapcore.WarnLevel
+	}
+
+	h.requestLogger(c).Log(level, "request served",
+		zap.String("path", c.Request.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("latency", time.Since(start)),
+		zap.Int("response_size", max(c.Writer.Size(), 0)),
+		zap.String("client_ip", c.ClientIP()),
+		zap.String("user_agent", c.Request.UserAgent()))
+}
+
+// requestID returns the ID of the request. Outside of requestContext, as in
+// handler tests, the ID is taken from the request or generated here.
+func requestID(c *gin.Context) string {
+	if id := c.Writer.Header().Get(requestIDHeader); id != "" {
+		return id
+	}
+	var id string
+	if c.Request != nil {
+		id = c.GetHeader(requestIDHeader)
+	}
+	if !validRequestID(id) {
+		id = uuid.NewString()
+	}
+	c.Header(requestIDHeader, id)
+	return id
+}
+
+// validRequestID accepts short printable IDs, so that a client cannot inject
+// arbitrary text into the logs and response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHandler_requestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+
+	setup := func() (*MockWalletService, *MockAPIKeyService, *observer.ObservedLogs, *gin.Engine) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		walletSvc := new(MockWalletService)
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_read").
+			Return(&types.APIKey{ID: 7, Name: "reporting", Scopes: []string{types.ScopeWalletRead}}, nil)
+		h := NewHandler(walletSvc, zap.New(core), WithAPIKeys(apikeySvc))
+		return walletSvc, apikeySvc, logs, h.InitRouter()
+	}
+
+	getBalance := func(r *gin.Engine, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+		req.Header.Set("X-API-Key", "wk_read")
+		if id != "" {
+			req.Header.Set(requestIDHeader, id)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("echoes client id", func(t *testing.T) {
+		walletSvc, _, _, r := setup()
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := getBalance(r, "client-req-1")
+
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "client-req-1", w.Header().Get(requestIDHeader))
+	})
+
+	t.Run("generates missing id", func(t *testing.T) {
+		walletSvc, _, _, r := setup()
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := getBalance(r, "")
+
+		_, err := uuid.Parse(w.Header().Get(requestIDHeader))
+		assert.NoError(t, err)
+	})
+
+	t.Run("replaces invalid id", func(t *testing.T) {
+		walletSvc, _, _, r := setup()
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+
+		w := getBalance(r, strings.Repeat("x", maxRequestIDLength+1))
+
+		_, err := uuid.Parse(w.Header().Get(requestIDHeader))
+		assert.NoError(t, err)
+	})
+
+	t.Run("service logs carry request fields", func(t *testing.T) {
+		walletSvc, _, logs, r := setup()
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).
+			Run(func(args mock.Arguments) {
+				ctx := args.Get(0).(context.Context)
+				logger.FromContext(ctx, zap.NewNop()).Info("in service")
+			}).
+			Return(types.NewFunds(100, 0), nil)
+
+		getBalance(r, "client-req-2")
+
+		entries := logs.FilterMessage("in service").All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "client-req-2", fields["request_id"])
+		assert.Equal(t, "/api/v1/wallet/:uuid", fields["route"])
+		assert.Equal(t, "GET", fields["method"])
+		assert.Equal(t, int64(7), fields["api_key_id"])
+		assert.Equal(t, "reporting", fields["api_key_name"])
+	})
+
+	t.Run("access log", func(t *testing.T) {
+		walletSvc, _, logs, r := setup()
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).
+			Return(types.Funds{}, types.ErrNotFound(types.ErrWalletNotFound))
+
+		w := getBalance(r, "client-req-3")
+
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, "client-req-3", assertProblem(t, w, types.CodeWalletNotFound, types.ErrWalletNotFound.Error()).RequestID)
+
+		entries := logs.FilterMessage("request served").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "client-req-3", fields["request_id"])
+		assert.Equal(t, "/api/v1/wallet/"+walletUUID, fields["path"])
+		assert.Equal(t, int64(404), fields["status"])
+		assert.Equal(t, int64(7), fields["api_key_id"])
+	})
+
+	t.Run("unmatched route", func(t *testing.T) {
+		_, _, logs, r := setup()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/nowhere", nil))
+
+		assert.Equal(t, 404, w.Code)
+		entries := logs.FilterMessage("request served").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "unmatched", entries[0].ContextMap()["route"])
+	})
+}
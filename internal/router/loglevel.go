@@ -0,0 +1,50 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithLogLevel exposes /admin/log-level, which reads and changes level at
+// runtime, e.g. to turn on debug logs while investigating an incident.
+func WithLogLevel(level zap.AtomicLevel) Option {
+	return func(h *Handler) {
+		h.logLevel = &level
+	}
+}
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+func (h *Handler) getLogLevel(c *gin.Context) error {
+	c.JSON(http.StatusOK, gin.H{"level": h.logLevel.Level().String()})
+	return nil
+}
+
+func (h *Handler) setLogLevel(c *gin.Context) error {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	level, err := zap.ParseAtomicLevel(req.Level)
+	if err != nil {
+		return types.ErrBadRequest(types.NewFieldError("level", "unknown log level %q", req.Level))
+	}
+
+	previous := h.logLevel.Level()
+	h.logLevel.SetLevel(level.Level())
+	// Пишем на уровне error: запись уровня warn после перехода на error
+	// отбросилась бы новым уровнем
+	h.requestLogger(c).Error("log level changed",
+		zap.Stringer("from", previous),
+		zap.Stringer("to", level.Level()))
+
+	c.JSON(http.StatusOK, gin.H{"level": level.Level().String()})
+	return nil
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHandler_logLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T, scopes ...string) (zap.AtomicLevel, *gin.Engine) {
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: scopes}, nil)
+		level := zap.NewAtomicLevelAt(zap.InfoLevel)
+		h := NewHandler(new(MockWalletService), zaptest.NewLogger(t), WithAPIKeys(apikeySvc), WithLogLevel(level))
+		return level, h.InitRouter()
+	}
+
+	serve := func(r *gin.Engine, method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/v1/admin/log-level", strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_test")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("get level", func(t *testing.T) {
+		_, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "GET", "")
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"level": "info"}`, w.Body.String())
+	})
+
+	t.Run("set level", func(t *testing.T) {
+		level, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "PUT", `{"level": "debug"}`)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"level": "debug"}`, w.Body.String())
+		assert.Equal(t, zap.DebugLevel, level.Level())
+	})
+
+	t.Run("change to error is logged", func(t *testing.T) {
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: []string{types.ScopeAdmin}}, nil)
+		level := zap.NewAtomicLevelAt(zap.InfoLevel)
+		core, logs := observer.New(level)
+		r := NewHandler(new(MockWalletService), zap.New(core), WithAPIKeys(apikeySvc), WithLogLevel(level)).InitRouter()
+
+		w := serve(r, "PUT", `{"level": "error"}`)
+		assert.Equal(t, 200, w.Code)
+		entries := logs.FilterMessage("log level changed").All()
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "info", entries[0].ContextMap()["from"])
+			assert.Equal(t, "error", entries[0].ContextMap()["to"])
+		}
+	})
+
+	t.Run("unknown level", func(t *testing.T) {
+		level, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "PUT", `{"level": "verbose"}`)
+		assert.Equal(t, 400, w.Code)
+		problem := assertProblem(t, w, types.CodeValidationFailed, `unknown log level "verbose"`)
+		assert.Equal(t, []types.FieldError{{Field: "level", Message: `unknown log level "verbose"`}}, problem.Errors)
+		assert.Equal(t, zap.InfoLevel, level.Level())
+	})
+
+	t.Run("requires admin scope", func(t *testing.T) {
+		level, r := setup(t, types.ScopeWalletWrite)
+
+		w := serve(r, "PUT", `{"level": "debug"}`)
+		assert.Equal(t, 403, w.Code)
+		assert.Equal(t, zap.InfoLevel, level.Level())
+	})
+
+	t.Run("not exposed without option", func(t *testing.T) {
+		r := NewHandler(new(MockWalletService), zaptest.NewLogger(t)).InitRouter()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/log-level", nil))
+		assert.Equal(t, 404, w.Code)
+	})
+}
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) setCreditLimit(c *gin.Context) error {
+	var req types.CreditLimitRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	funds, err := h.walletservice.SetCreditLimit(c, c.Param("uuid"), *req.CreditLimit)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, funds)
+	return nil
+}
+
+func (h *Handler) getOverdraftReport(c *gin.Context) error {
+	wallets, err := h.walletservice.GetOverdraftWallets(c)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"wallets": wallets})
+	return nil
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_overdraft(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+
+	setup := func(t *testing.T, scopes ...string) (*MockWalletService, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: scopes}, nil)
+		return walletSvc, NewHandler(walletSvc, zaptest.NewLogger(t), WithAPIKeys(apikeySvc)).InitRouter()
+	}
+
+	serve := func(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_test")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	creditLimitPath := "/api/v1/admin/wallet/" + walletUUID + "/credit-limit"
+
+	t.Run("set credit limit", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("SetCreditLimit", mock.Anything, walletUUID, 500).
+			Return(types.NewFunds(-100, 500), nil)
+
+		w := serve(r, "PUT", creditLimitPath, `{"creditLimit": 500}`)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"balance": -100, "creditLimit": 500, "available": 400}`, w.Body.String())
+	})
+
+	t.Run("set zero credit limit", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("SetCreditLimit", mock.Anything, walletUUID, 0).
+			Return(types.NewFunds(100, 0), nil)
+
+		w := serve(r, "PUT", creditLimitPath, `{"creditLimit": 0}`)
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+
+	t.Run("negative credit limit", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "PUT", creditLimitPath, `{"creditLimit": -1}`)
+		assert.Equal(t, 400, w.Code)
+		walletSvc.AssertNotCalled(t, "SetCreditLimit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing credit limit", func(t *testing.T) {
+		_, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "PUT", creditLimitPath, `{}`)
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("overdraft report", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("GetOverdraftWallets", mock.Anything).Return([]types.OverdraftWallet{
+			{WalletUUID: walletUUID, Funds: types.NewFunds(-300, 1000)},
+		}, nil)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/overdraft", "")
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"wallets": [
+			{"valletId": "`+walletUUID+`", "balance": -300, "creditLimit": 1000, "available": 700}
+		]}`, w.Body.String())
+	})
+
+	t.Run("requires admin scope", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeWalletRead, types.ScopeWalletWrite)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/overdraft", "")
+		assert.Equal(t, 403, w.Code)
+		walletSvc.AssertNotCalled(t, "GetOverdraftWallets", mock.Anything)
+	})
+}
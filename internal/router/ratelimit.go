@@ -0,0 +1,154 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/ratelimit"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WithRateLimits limits requests per client (API key, user or IP) and, on the
+// write path, per wallet.
+func WithRateLimits(limiter ratelimit.Limiter, client, wallet ratelimit.Limit) Option {
+	return func(h *Handler) {
+		h.limiter = limiter
+		h.clientLimit = client
+		h.walletLimit = wallet
+	}
+}
+
+// WithIPRateLimit limits requests per client IP before authentication, so
+// that requests with invalid credentials are throttled too. It requires the
+// limiter of WithRateLimits.
+func WithIPRateLimit(limit ratelimit.Limit) Option {
+	return func(h *Handler) {
+		h.ipLimit = limit
+	}
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For and X-Real-IP
+// headers are trusted to carry the client IP. By default no proxy is trusted
+// and the client IP is the address of the connection.
+func WithTrustedProxies(proxies []string) Option {
+	return func(h *Handler) {
+		h.trustedProxies = proxies
+	}
+}
+
+// limitIP counts the request against the bucket of its IP. It runs before
+// authenticate, which limitClient cannot do.
+func (h *Handler) limitIP(c *gin.Context) {
+	if err := h.allow(c, "ip:"+c.ClientIP(), h.ipLimit); err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+	c.Next()
+}
+
+// limitClient counts the request against the bucket of its caller. It runs
+// after authenticate so that clients behind one IP get separate limits.
+func (h *Handler) limitClient(c *gin.Context) {
+	if err := h.allow(c, clientKey(c), h.clientLimit); err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+	c.Next()
+}
+
+// limitWallets counts a write against the bucket of every wallet it touches.
+// Buckets are keyed by canonical UUID, so that one wallet spelled differently
+// shares its bucket. Every ID is checked before a token is taken; an ID that
+// does not parse fails with a *types.BatchItemError naming its request.
+func (h *Handler) limitWallets(c *gin.Context, reqs ...*types.WalletUpdateRequest) error {
+	if h.limiter == nil || h.walletLimit.Unlimited() {
+		return nil
+	}
+
+	keys := make([]string, 0, len(reqs))
+	seen := make(map[string]struct{}, len(reqs))
+	for i, req := range reqs {
+		id, err := uuid.Parse(req.WalletUUID)
+		if err != nil {
+			return types.ErrBadRequest(&types.BatchItemError{
+				Index:       i,
+				ReferenceID: req.ReferenceID,
+				Err:         types.NewFieldError("valletId", "WalletUUID is not valid UUID: %v", err),
+			})
+		}
+		key := "wallet:" + id.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		if err := h.allow(c, key, h.walletLimit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// limitWallet counts a write against the bucket of its only wallet.
+func (h *Handler) limitWallet(c *gin.Context, walletUUID string) error {
+	err := h.limitWallets(c, &types.WalletUpdateRequest{WalletUUID: walletUUID})
+	// Запрос не пакетный, номер элемента в ответе не нужен
+	var itemErr *types.BatchItemError
+	if errors.As(err, &itemErr) {
+		return types.ErrBadRequest(itemErr.Err)
+	}
+	return err
+}
+
+// allow sets the RateLimit-* headers and returns 429 if the bucket is empty.
+// If the limiter fails, the request is let through.
+func (h *Handler) allow(c *gin.Context, key string, limit ratelimit.Limit) error {
+	if h.limiter == nil || limit.Unlimited() {
+		return nil
+	}
+
+	res, err := h.limiter.Allow(c, key, limit)
+	if err != nil {
+		h.requestLogger(c).Error("rate limiter failed",
+			zap.String("key", key),
+			zap.Error(err))
+		return nil
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+	if !res.Allowed {
+		c.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+		h.requestLogger(c).Warn("rate limit exceeded",
+			zap.String("key", key),
+			zap.Duration("retry_after", res.RetryAfter))
+		return types.ErrTooManyRequests(fmt.Errorf("%w, retry in %s", types.ErrRateLimited, res.RetryAfter.Round(time.Millisecond)))
+	}
+
+	return nil
+}
+
+func clientKey(c *gin.Context) string {
+	if key, ok := apiKeyFrom(c); ok {
+		return "client:key:" + strconv.FormatInt(key.ID, 10)
+	}
+	if ownerID, ok := c.Get(ownerContextKey); ok {
+		return fmt.Sprintf("client:user:%v", ownerID)
+	}
+	return "client:ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,193 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/ratelimit"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis is down")
+}
+
+func TestHandler_rateLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+	slow := ratelimit.Limit{Rate: 0.001, Burst: 2}
+
+	t.Run("client limit", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), slow, ratelimit.Limit{}),
+		).InitRouter()
+
+		for _, remaining := range []string{"1", "0"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil))
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+			assert.Equal(t, remaining, w.Header().Get("RateLimit-Remaining"))
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil))
+		assert.Equal(t, 429, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.NotEmpty(t, w.Header().Get("RateLimit-Reset"))
+		assert.Contains(t, w.Body.String(), types.ErrRateLimited.Error())
+		walletSvc.AssertNumberOfCalls(t, "GetBalance", 2)
+	})
+
+	t.Run("wallet limit on write path", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.Fee{}, nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), ratelimit.Limit{}, slow),
+		).InitRouter()
+
+		send := func(wallet string) int {
+			w := httptest.NewRecorder()
+			body := `{"valletId":"` + wallet + `","operationType":"DEPOSIT","amount":100}`
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body)))
+			return w.Code
+		}
+
+		assert.Equal(t, 200, send(walletUUID))
+		assert.Equal(t, 200, send(walletUUID))
+		assert.Equal(t, 429, send(walletUUID))
+		assert.Equal(t, 200, send("b2c3d4e5-6789-0123-4567-890123456789"))
+		walletSvc.AssertNumberOfCalls(t, "UpdateBalance", 3)
+	})
+
+	t.Run("wallet limit uses canonical UUID", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.Fee{}, nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), ratelimit.Limit{}, slow),
+		).InitRouter()
+
+		send := func(wallet string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			body := `{"valletId":"` + wallet + `","operationType":"DEPOSIT","amount":100}`
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body)))
+			return w
+		}
+
+		assert.Equal(t, 200, send(strings.ToUpper(walletUUID)).Code)
+		assert.Equal(t, 200, send("{"+walletUUID+"}").Code)
+		assert.Equal(t, 429, send("urn:uuid:"+walletUUID).Code)
+
+		w := send("not-a-wallet")
+		problem := assertProblem(t, w, types.CodeValidationFailed, "WalletUUID is not valid UUID: invalid UUID length: 12")
+		assert.Equal(t, "valletId", problem.Errors[0].Field)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		walletSvc.AssertNumberOfCalls(t, "UpdateBalance", 2)
+	})
+
+	t.Run("batch checks every wallet before taking tokens", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("UpdateBalanceBatch", mock.Anything, true, mock.Anything).
+			Return([]types.BatchItemResult{}, nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), ratelimit.Limit{}, slow),
+		).InitRouter()
+
+		send := func(wallets ...string) *httptest.ResponseRecorder {
+			items := make([]string, 0, len(wallets))
+			for i, wallet := range wallets {
+				items = append(items, fmt.Sprintf(
+					`{"valletId":"%s","operationType":"DEPOSIT","amount":100,"referenceId":"ref-%d"}`, wallet, i))
+			}
+			w := httptest.NewRecorder()
+			body := `{"mode":"atomic","items":[` + strings.Join(items, ",") + `]}`
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/wallet/batch", strings.NewReader(body)))
+			return w
+		}
+
+		for i := 0; i < 3; i++ {
+			w := send(walletUUID, "not-a-wallet")
+			problem := assertProblem(t, w, types.CodeValidationFailed,
+				"item 1 (reference_id ref-1): WalletUUID is not valid UUID: invalid UUID length: 12")
+			assert.Equal(t, "items[1].valletId", problem.Errors[0].Field)
+		}
+		assert.Equal(t, 200, send(walletUUID, strings.ToUpper(walletUUID)).Code)
+		assert.Equal(t, 200, send(walletUUID).Code)
+		assert.Equal(t, 429, send(walletUUID).Code)
+		walletSvc.AssertNumberOfCalls(t, "UpdateBalanceBatch", 2)
+	})
+
+	t.Run("invalid keys are limited per IP", func(t *testing.T) {
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, mock.Anything).
+			Return(nil, types.ErrUnauthorized(types.ErrAPIKeyInvalid))
+		r := NewHandler(new(MockWalletService), zaptest.NewLogger(t),
+			WithAPIKeys(apikeySvc),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), ratelimit.Limit{}, ratelimit.Limit{}),
+			WithIPRateLimit(slow),
+		).InitRouter()
+
+		send := func(key, forwardedFor string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+			req.Header.Set("X-API-Key", key)
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, 401, send("wk_guess1", "203.0.113.1"))
+		assert.Equal(t, 401, send("wk_guess2", "203.0.113.2"))
+		// X-Forwarded-For от недоверенного клиента не меняет бакет
+		assert.Equal(t, 429, send("wk_guess3", "203.0.113.3"))
+		apikeySvc.AssertNumberOfCalls(t, "Authenticate", 2)
+	})
+
+	t.Run("trusted proxy sets client IP", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(ratelimit.NewMemoryLimiter(), ratelimit.Limit{}, ratelimit.Limit{}),
+			WithIPRateLimit(ratelimit.Limit{Rate: 0.001, Burst: 1}),
+			WithTrustedProxies([]string{"192.0.2.0/24"}),
+		).InitRouter()
+
+		send := func(forwardedFor string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil)
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, 200, send("203.0.113.1"))
+		assert.Equal(t, 429, send("203.0.113.1"))
+		assert.Equal(t, 200, send("203.0.113.2"))
+	})
+
+	t.Run("limiter failure lets requests through", func(t *testing.T) {
+		walletSvc := new(MockWalletService)
+		walletSvc.On("GetBalance", mock.Anything, walletUUID).Return(types.NewFunds(100, 0), nil)
+		r := NewHandler(walletSvc, zaptest.NewLogger(t),
+			WithRateLimits(failingLimiter{}, slow, slow),
+		).InitRouter()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID, nil))
+		assert.Equal(t, 200, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
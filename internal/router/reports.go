@@ -0,0 +1,29 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) getFailureReport(c *gin.Context) error {
+	// По умолчанию отчёт за последние сутки
+	since := time.Now().Add(-24 * time.Hour)
+	if value := c.Query("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			return types.ErrBadRequest(fmt.Errorf("since must be an RFC3339 time: %w", err))
+		}
+	}
+
+	report, err := h.walletservice.FailureReport(c, since)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, report)
+	return nil
+}
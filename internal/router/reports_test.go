@@ -0,0 +1,77 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_failureReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T, scopes ...string) (*MockWalletService, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: scopes}, nil)
+		return walletSvc, NewHandler(walletSvc, zaptest.NewLogger(t), WithAPIKeys(apikeySvc)).InitRouter()
+	}
+
+	serve := func(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_test")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("failure report", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		since := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+		walletSvc.On("FailureReport", mock.Anything, since).Return(&types.FailureReport{
+			Since:  since,
+			Total:  3,
+			Counts: []types.FailureCount{{Code: types.FailureInsufficientFunds, Count: 3}},
+		}, nil)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/failures?since=2026-10-18T00:00:00Z", "")
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"since": "2026-10-18T00:00:00Z", "total": 3, "counts": [
+			{"code": "INSUFFICIENT_FUNDS", "count": 3}
+		]}`, w.Body.String())
+	})
+
+	t.Run("failure report defaults to the last day", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+		walletSvc.On("FailureReport", mock.Anything, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since) > 23*time.Hour && time.Since(since) < 25*time.Hour
+		})).Return(&types.FailureReport{Counts: []types.FailureCount{}}, nil)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/failures", "")
+		assert.Equal(t, 200, w.Code)
+		walletSvc.AssertExpectations(t)
+	})
+
+	t.Run("failure report with invalid since", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeAdmin)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/failures?since=yesterday", "")
+		assert.Equal(t, 400, w.Code)
+		walletSvc.AssertNotCalled(t, "FailureReport", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires admin scope", func(t *testing.T) {
+		walletSvc, r := setup(t, types.ScopeWalletRead, types.ScopeWalletWrite)
+
+		w := serve(r, "GET", "/api/v1/admin/reports/failures", "")
+		assert.Equal(t, 403, w.Code)
+		walletSvc.AssertNotCalled(t, "FailureReport", mock.Anything, mock.Anything)
+	})
+}
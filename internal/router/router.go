@@ -3,29 +3,81 @@ package router
 import (
 	"net/http"
 
+	"github.com/artyomkorchagin/wallet-task/internal/ratelimit"
+	apikeyservice "github.com/artyomkorchagin/wallet-task/internal/services/apikey"
+	scheduleservice "github.com/artyomkorchagin/wallet-task/internal/services/schedule"
 	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
 	walletservice walletservice.ServiceInterface
-	logger        *zap.Logger
+	apikeys       apikeyservice.ServiceInterface
+	schedules     scheduleservice.ServiceInterface
+	tokens        TokenVerifier
+	limiter       ratelimit.Limiter
+	clientLimit   ratelimit.Limit
+	walletLimit   ratelimit.Limit
+	ipLimit       ratelimit.Limit
+	// trustedProxies may set the client IP, see WithTrustedProxies
+	trustedProxies []string
+	logLevel       *zap.AtomicLevel
+	logger         *zap.Logger
 }
 
-func NewHandler(walletservice walletservice.ServiceInterface, logger *zap.Logger) *Handler {
-	return &Handler{
+type Option func(*Handler)
+
+// WithAPIKeys requires every /api/v1 request to carry an API key accepted by
+// apikeys and granting the scope of the route.
+func WithAPIKeys(apikeys apikeyservice.ServiceInterface) Option {
+	return func(h *Handler) {
+		h.apikeys = apikeys
+	}
+}
+
+// TokenVerifier checks an end-user bearer token and returns its subject.
+type TokenVerifier interface {
+	Verify(rawToken string) (string, error)
+}
+
+// WithJWT accepts bearer tokens of end users next to API keys. Requests made
+// with a token may only access wallets owned by the token's subject.
+func WithJWT(tokens TokenVerifier) Option {
+	return func(h *Handler) {
+		h.tokens = tokens
+	}
+}
+
+func NewHandler(walletservice walletservice.ServiceInterface, logger *zap.Logger, opts ...Option) *Handler {
+	h := &Handler{
 		walletservice: walletservice,
 		logger:        logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	useJSONFieldNames()
+	return h
 }
 
 func (h *Handler) InitRouter() *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
+	// Values put into the request context (e.g. the wallet owner) must be
+	// visible to services that receive *gin.Context as context.Context
+	router.ContextWithFallback = true
+	// Без доверенных прокси клиент мог бы подменить свой IP заголовком
+	// X-Forwarded-For и выбрать себе бакет лимита
+	if err := router.SetTrustedProxies(h.trustedProxies); err != nil {
+		h.logger.Error("invalid trusted proxies, trusting none", zap.Error(err))
+		_ = router.SetTrustedProxies(nil)
+	}
 
-	router.Use(gin.Logger())
+	router.Use(h.requestContext)
+	router.Use(h.accessLog)
 	router.Use(gin.Recovery())
 
 	main := router.Group("/")
@@ -36,9 +88,42 @@ func (h *Handler) InitRouter() *gin.Engine {
 	}
 
 	apiv1 := router.Group("/api/v1/")
+	if h.limiter != nil {
+		apiv1.Use(h.limitIP)
+	}
+	if h.authEnabled() {
+		apiv1.Use(h.authenticate)
+	}
+	if h.limiter != nil {
+		apiv1.Use(h.limitClient)
+	}
 	{
-		apiv1.GET("/wallet/:uuid", h.wrap(h.getBalance))
-		apiv1.POST("/wallet", h.wrap(h.updateBalance))
+		apiv1.GET("/wallet/:uuid", h.requireScope(types.ScopeWalletRead), h.wrap(h.getBalance))
+		apiv1.GET("/wallet/:uuid/balance", h.requireScope(types.ScopeWalletRead), h.wrap(h.getBalanceAt))
+		apiv1.GET("/wallet/:uuid/statement", h.requireScope(types.ScopeWalletRead), h.wrap(h.getStatement))
+		apiv1.GET("/wallet/:uuid/operations", h.requireScope(types.ScopeWalletRead), h.wrap(h.listOperations))
+		apiv1.POST("/wallet", h.requireScope(types.ScopeWalletWrite), h.wrap(h.updateBalance))
+		apiv1.POST("/wallet/batch", h.requireScope(types.ScopeWalletWrite), h.wrap(h.updateBalanceBatch))
+		apiv1.POST("/wallet/balances", h.requireScope(types.ScopeWalletRead), h.wrap(h.getBalances))
+		apiv1.GET("/operations/:referenceId", h.requireScope(types.ScopeWalletRead), h.wrap(h.getOperation))
+
+		apiv1.GET("/admin/wallet/:uuid/limits", h.requireScope(types.ScopeAdmin), h.wrap(h.getWalletLimits))
+		apiv1.PUT("/admin/wallet/:uuid/limits", h.requireScope(types.ScopeAdmin), h.wrap(h.setWalletLimits))
+		apiv1.PUT("/admin/wallet/:uuid/credit-limit", h.requireScope(types.ScopeAdmin), h.wrap(h.setCreditLimit))
+		apiv1.GET("/admin/reports/overdraft", h.requireScope(types.ScopeAdmin), h.wrap(h.getOverdraftReport))
+		apiv1.GET("/admin/reports/failures", h.requireScope(types.ScopeAdmin), h.wrap(h.getFailureReport))
+	}
+	if h.logLevel != nil {
+		apiv1.GET("/admin/log-level", h.requireScope(types.ScopeAdmin), h.wrap(h.getLogLevel))
+		apiv1.PUT("/admin/log-level", h.requireScope(types.ScopeAdmin), h.wrap(h.setLogLevel))
+	}
+	if h.schedules != nil {
+		apiv1.POST("/schedules", h.requireScope(types.ScopeWalletWrite), h.wrap(h.createSchedule))
+		apiv1.GET("/schedules", h.requireScope(types.ScopeWalletRead), h.wrap(h.listSchedules))
+		apiv1.GET("/schedules/:id", h.requireScope(types.ScopeWalletRead), h.wrap(h.getSchedule))
+		apiv1.PATCH("/schedules/:id", h.requireScope(types.ScopeWalletWrite), h.wrap(h.updateSchedule))
+		apiv1.DELETE("/schedules/:id", h.requireScope(types.ScopeWalletWrite), h.wrap(h.cancelSchedule))
+		apiv1.GET("/schedules/:id/runs", h.requireScope(types.ScopeWalletRead), h.wrap(h.listScheduleRuns))
 	}
 	h.logger.Info("Routes initialized")
 	return router
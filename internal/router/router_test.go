@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/stretchr/testify/mock"
@@ -11,12 +12,174 @@ type MockWalletService struct {
 	mock.Mock
 }
 
-func (m *MockWalletService) GetBalance(ctx context.Context, walletUUID string) (int, error) {
+func (m *MockWalletService) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
 	args := m.Called(ctx, walletUUID)
-	return args.Int(0), args.Error(1)
+	funds, _ := args.Get(0).(types.Funds)
+	return funds, args.Error(1)
 }
 
-func (m *MockWalletService) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) error {
+func (m *MockWalletService) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
 	args := m.Called(ctx, req)
+	fee, _ := args.Get(0).(types.Fee)
+	return fee, args.Error(1)
+}
+
+func (m *MockWalletService) UpdateBalanceBatch(ctx context.Context, atomic bool, reqs []*types.WalletUpdateRequest) ([]types.BatchItemResult, error) {
+	args := m.Called(ctx, atomic, reqs)
+	results, _ := args.Get(0).([]types.BatchItemResult)
+	return results, args.Error(1)
+}
+
+func (m *MockWalletService) GetBalances(ctx context.Context, walletUUIDs []string) ([]types.WalletBalance, error) {
+	args := m.Called(ctx, walletUUIDs)
+	balances, _ := args.Get(0).([]types.WalletBalance)
+	return balances, args.Error(1)
+}
+
+func (m *MockWalletService) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	args := m.Called(ctx, walletUUID)
+	limits, _ := args.Get(0).(*types.WalletLimits)
+	return limits, args.Error(1)
+}
+
+func (m *MockWalletService) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) (types.Funds, error) {
+	args := m.Called(ctx, walletUUID, creditLimit)
+	funds, _ := args.Get(0).(types.Funds)
+	return funds, args.Error(1)
+}
+
+func (m *MockWalletService) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	args := m.Called(ctx)
+	wallets, _ := args.Get(0).([]types.OverdraftWallet)
+	return wallets, args.Error(1)
+}
+
+func (m *MockWalletService) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) (*types.WalletLimits, error) {
+	args := m.Called(ctx, walletUUID, update)
+	limits, _ := args.Get(0).(*types.WalletLimits)
+	return limits, args.Error(1)
+}
+
+func (m *MockWalletService) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (*types.HistoricalBalance, error) {
+	args := m.Called(ctx, walletUUID, at)
+	balance, _ := args.Get(0).(*types.HistoricalBalance)
+	return balance, args.Error(1)
+}
+
+func (m *MockWalletService) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	args := m.Called(ctx, walletUUID, from, to, w)
 	return args.Error(0)
 }
+
+func (m *MockWalletService) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	args := m.Called(ctx, owner, creditLimit)
+	wallet, _ := args.Get(0).(*types.Wallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockWalletService) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	args := m.Called(ctx, walletUUID)
+	wallet, _ := args.Get(0).(*types.Wallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockWalletService) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) (*types.Wallet, error) {
+	args := m.Called(ctx, walletUUID, frozen)
+	wallet, _ := args.Get(0).(*types.Wallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockWalletService) EnqueueUpdate(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	args := m.Called(ctx, req)
+	op, _ := args.Get(0).(*types.Operation)
+	return op, args.Error(1)
+}
+
+func (m *MockWalletService) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	args := m.Called(ctx, referenceID)
+	op, _ := args.Get(0).(*types.Operation)
+	return op, args.Error(1)
+}
+
+func (m *MockWalletService) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) (*types.OperationsPage, error) {
+	args := m.Called(ctx, walletUUID, filter)
+	page, _ := args.Get(0).(*types.OperationsPage)
+	return page, args.Error(1)
+}
+
+func (m *MockWalletService) FailureReport(ctx context.Context, since time.Time) (*types.FailureReport, error) {
+	args := m.Called(ctx, since)
+	report, _ := args.Get(0).(*types.FailureReport)
+	return report, args.Error(1)
+}
+
+func (m *MockWalletService) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	args := m.Called(ctx)
+	mismatches, _ := args.Get(0).([]types.BalanceMismatch)
+	return mismatches, args.Error(1)
+}
+
+func (m *MockWalletService) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	n, _ := args.Get(0).(int64)
+	return n, args.Error(1)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, rawKey string) (*types.APIKey, error) {
+	args := m.Called(ctx, rawKey)
+	key, _ := args.Get(0).(*types.APIKey)
+	return key, args.Error(1)
+}
+
+type MockTokenVerifier struct {
+	mock.Mock
+}
+
+func (m *MockTokenVerifier) Verify(rawToken string) (string, error) {
+	args := m.Called(rawToken)
+	return args.String(0), args.Error(1)
+}
+
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateSchedule(ctx context.Context, req *types.ScheduleRequest) (*types.Schedule, error) {
+	args := m.Called(ctx, req)
+	schedule, _ := args.Get(0).(*types.Schedule)
+	return schedule, args.Error(1)
+}
+
+func (m *MockScheduleService) GetSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	args := m.Called(ctx, id)
+	schedule, _ := args.Get(0).(*types.Schedule)
+	return schedule, args.Error(1)
+}
+
+func (m *MockScheduleService) ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error) {
+	args := m.Called(ctx, walletUUID)
+	schedules, _ := args.Get(0).([]*types.Schedule)
+	return schedules, args.Error(1)
+}
+
+func (m *MockScheduleService) UpdateSchedule(ctx context.Context, id string, update *types.ScheduleUpdate) (*types.Schedule, error) {
+	args := m.Called(ctx, id, update)
+	schedule, _ := args.Get(0).(*types.Schedule)
+	return schedule, args.Error(1)
+}
+
+func (m *MockScheduleService) CancelSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	args := m.Called(ctx, id)
+	schedule, _ := args.Get(0).(*types.Schedule)
+	return schedule, args.Error(1)
+}
+
+func (m *MockScheduleService) ListScheduleRuns(ctx context.Context, id string) ([]types.ScheduleRun, error) {
+	args := m.Called(ctx, id)
+	runs, _ := args.Get(0).([]types.ScheduleRun)
+	return runs, args.Error(1)
+}
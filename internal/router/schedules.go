@@ -0,0 +1,99 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	scheduleservice "github.com/artyomkorchagin/wallet-task/internal/services/schedule"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// WithSchedules exposes the /schedules endpoints backed by schedules.
+func WithSchedules(schedules scheduleservice.ServiceInterface) Option {
+	return func(h *Handler) {
+		h.schedules = schedules
+	}
+}
+
+func (h *Handler) createSchedule(c *gin.Context) error {
+	var req types.ScheduleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	if err := h.limitWallet(c, req.WalletUUID); err != nil {
+		return err
+	}
+
+	schedule, err := h.schedules.CreateSchedule(c, &req)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+	return nil
+}
+
+func (h *Handler) listSchedules(c *gin.Context) error {
+	walletUUID := c.Query("valletId")
+	if walletUUID == "" {
+		return types.ErrBadRequest(errors.New("valletId query parameter is required"))
+	}
+
+	schedules, err := h.schedules.ListSchedules(c, walletUUID)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+	return nil
+}
+
+func (h *Handler) getSchedule(c *gin.Context) error {
+	schedule, err := h.schedules.GetSchedule(c, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, schedule)
+	return nil
+}
+
+func (h *Handler) updateSchedule(c *gin.Context) error {
+	var update types.ScheduleUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	schedule, err := h.schedules.UpdateSchedule(c, c.Param("id"), &update)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, schedule)
+	return nil
+}
+
+func (h *Handler) cancelSchedule(c *gin.Context) error {
+	schedule, err := h.schedules.CancelSchedule(c, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, schedule)
+	return nil
+}
+
+func (h *Handler) listScheduleRuns(c *gin.Context) error {
+	runs, err := h.schedules.ListScheduleRuns(c, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+	return nil
+}
@@ -0,0 +1,152 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_schedules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+		scheduleID = "7d1c2a3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	)
+	next := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	schedule := &types.Schedule{
+		ID:         scheduleID,
+		WalletUUID: walletUUID,
+		Operation:  "WITHDRAW",
+		Amount:     500,
+		Recurrence: "0 0 1 * *",
+		Status:     types.ScheduleStatusActive,
+		NextRunAt:  &next,
+		CreatedAt:  created,
+	}
+
+	setup := func(t *testing.T, scopes ...string) (*MockScheduleService, *gin.Engine) {
+		scheduleSvc := new(MockScheduleService)
+		apikeySvc := new(MockAPIKeyService)
+		apikeySvc.On("Authenticate", mock.Anything, "wk_test").
+			Return(&types.APIKey{ID: 1, Scopes: scopes}, nil)
+		h := NewHandler(new(MockWalletService), zaptest.NewLogger(t), WithAPIKeys(apikeySvc), WithSchedules(scheduleSvc))
+		return scheduleSvc, h.InitRouter()
+	}
+
+	serve := func(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "wk_test")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("create", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletWrite)
+		scheduleSvc.On("CreateSchedule", mock.Anything, &types.ScheduleRequest{
+			WalletUUID: walletUUID,
+			Operation:  "WITHDRAW",
+			Amount:     500,
+			Recurrence: "0 0 1 * *",
+		}).Return(schedule, nil)
+
+		w := serve(r, "POST", "/api/v1/schedules",
+			`{"valletId": "`+walletUUID+`", "operationType": "WITHDRAW", "amount": 500, "recurrence": "0 0 1 * *"}`)
+		assert.Equal(t, 201, w.Code)
+		assert.JSONEq(t, `{
+			"id": "`+scheduleID+`",
+			"valletId": "`+walletUUID+`",
+			"operationType": "WITHDRAW",
+			"amount": 500,
+			"recurrence": "0 0 1 * *",
+			"status": "ACTIVE",
+			"nextRunAt": "2026-11-01T00:00:00Z",
+			"failures": 0,
+			"createdAt": "2026-10-19T12:00:00Z"
+		}`, w.Body.String())
+		scheduleSvc.AssertExpectations(t)
+	})
+
+	t.Run("create invalid body", func(t *testing.T) {
+		_, r := setup(t, types.ScopeWalletWrite)
+
+		w := serve(r, "POST", "/api/v1/schedules", `{"valletId": "`+walletUUID+`", "operationType": "REFUND", "amount": 500}`)
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletRead)
+		scheduleSvc.On("ListSchedules", mock.Anything, walletUUID).Return([]*types.Schedule{schedule}, nil)
+
+		w := serve(r, "GET", "/api/v1/schedules?valletId="+walletUUID, "")
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"schedules":[{"id":"`+scheduleID+`"`)
+	})
+
+	t.Run("list without wallet", func(t *testing.T) {
+		_, r := setup(t, types.ScopeWalletRead)
+
+		w := serve(r, "GET", "/api/v1/schedules", "")
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletWrite)
+		paused := types.ScheduleStatusPaused
+		scheduleSvc.On("UpdateSchedule", mock.Anything, scheduleID, &types.ScheduleUpdate{Status: &paused}).
+			Return(schedule, nil)
+
+		w := serve(r, "PATCH", "/api/v1/schedules/"+scheduleID, `{"status": "PAUSED"}`)
+		assert.Equal(t, 200, w.Code)
+		scheduleSvc.AssertExpectations(t)
+	})
+
+	t.Run("cancel not found", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletWrite)
+		scheduleSvc.On("CancelSchedule", mock.Anything, scheduleID).
+			Return(nil, types.ErrNotFound(types.ErrScheduleNotFound))
+
+		w := serve(r, "DELETE", "/api/v1/schedules/"+scheduleID, "")
+		assert.Equal(t, 404, w.Code)
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletRead)
+		scheduleSvc.On("ListScheduleRuns", mock.Anything, scheduleID).Return([]types.ScheduleRun{{
+			ScheduleID:  scheduleID,
+			Occurrence:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			ReferenceID: "3f0c8a52-3b4e-5d6f-8a9b-0c1d2e3f4a5b",
+			Status:      types.ScheduleRunFailed,
+			Error:       "insufficient funds",
+			CreatedAt:   created,
+		}}, nil)
+
+		w := serve(r, "GET", "/api/v1/schedules/"+scheduleID+"/runs", "")
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"FAILED"`)
+	})
+
+	t.Run("write requires wallet:write scope", func(t *testing.T) {
+		scheduleSvc, r := setup(t, types.ScopeWalletRead)
+
+		w := serve(r, "DELETE", "/api/v1/schedules/"+scheduleID, "")
+		assert.Equal(t, 403, w.Code)
+		scheduleSvc.AssertNotCalled(t, "CancelSchedule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("routes disabled without schedule service", func(t *testing.T) {
+		r := NewHandler(new(MockWalletService), zaptest.NewLogger(t)).InitRouter()
+
+		w := serve(r, "GET", "/api/v1/schedules/"+scheduleID, "")
+		assert.Equal(t, 404, w.Code)
+	})
+}
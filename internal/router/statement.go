@@ -0,0 +1,147 @@
+package router
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) getStatement(c *gin.Context) error {
+	walletUUID := c.Param("uuid")
+
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return types.ErrBadRequest(fmt.Errorf("from must be an RFC3339 time: %w", err))
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		return types.ErrBadRequest(fmt.Errorf("to must be an RFC3339 time: %w", err))
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	var w statementEncoder
+	switch format {
+	case "csv":
+		w = &csvStatementWriter{from: from, to: to}
+	case "jsonl":
+		w = &jsonlStatementWriter{from: from, to: to}
+	default:
+		return types.ErrBadRequest(fmt.Errorf("format must be csv or jsonl"))
+	}
+
+	// Заголовки выставляются с первой строкой выписки, чтобы ошибки до неё
+	// отдавались обычным JSON
+	filename := fmt.Sprintf("statement-%s-%s-%s.%s",
+		walletUUID, from.UTC().Format("20060102"), to.UTC().Format("20060102"), format)
+	w.start(func() io.Writer {
+		c.Header("Content-Type", w.contentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		return c.Writer
+	})
+
+	if err := h.walletservice.StreamStatement(c, walletUUID, from, to, w); err != nil {
+		if !c.Writer.Written() {
+			return err
+		}
+		// Статус уже отправлен: обрываем выписку без закрывающей строки
+		h.requestLogger(c).Error("statement cut short",
+			zap.String("wallet_uuid", walletUUID),
+			zap.Error(err))
+		c.Abort()
+	}
+	return nil
+}
+
+// statementEncoder is a types.StatementWriter that opens the response lazily,
+// calling open on its first write.
+type statementEncoder interface {
+	types.StatementWriter
+	start(open func() io.Writer)
+	contentType() string
+}
+
+// csvStatementWriter writes a statement as CSV: a header, then an opening row,
+// a row per operation and a closing row.
+type csvStatementWriter struct {
+	from, to time.Time
+	open     func() io.Writer
+	w        *csv.Writer
+}
+
+func (w *csvStatementWriter) start(open func() io.Writer) { w.open = open }
+
+func (w *csvStatementWriter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (w *csvStatementWriter) WriteOpening(balance int) error {
+	w.w = csv.NewWriter(w.open())
+	if err := w.w.Write([]string{"type", "time", "reference_id", "operation_type", "amount", "fee", "balance"}); err != nil {
+		return err
+	}
+	return w.w.Write([]string{"opening", w.from.Format(time.RFC3339), "", "", "", "", strconv.Itoa(balance)})
+}
+
+func (w *csvStatementWriter) WriteEntry(entry *types.StatementEntry) error {
+	return w.w.Write([]string{
+		"operation",
+		entry.AppliedAt.Format(time.RFC3339Nano),
+		entry.ReferenceID,
+		entry.Operation,
+		strconv.Itoa(entry.Amount),
+		strconv.Itoa(entry.Fee),
+		strconv.Itoa(entry.Balance),
+	})
+}
+
+func (w *csvStatementWriter) WriteClosing(balance int) error {
+	if err := w.w.Write([]string{"closing", w.to.Format(time.RFC3339), "", "", "", "", strconv.Itoa(balance)}); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonlStatementWriter writes a statement as JSON Lines, one object per line
+// with its kind in "type".
+type jsonlStatementWriter struct {
+	from, to time.Time
+	open     func() io.Writer
+	enc      *json.Encoder
+}
+
+type statementBalanceLine struct {
+	Type    string    `json:"type"`
+	At      time.Time `json:"at"`
+	Balance int       `json:"balance"`
+}
+
+type statementEntryLine struct {
+	Type string `json:"type"`
+	*types.StatementEntry
+}
+
+func (w *jsonlStatementWriter) start(open func() io.Writer) { w.open = open }
+
+func (w *jsonlStatementWriter) contentType() string { return "application/x-ndjson" }
+
+func (w *jsonlStatementWriter) WriteOpening(balance int) error {
+	w.enc = json.NewEncoder(w.open())
+	return w.enc.Encode(statementBalanceLine{Type: "opening", At: w.from, Balance: balance})
+}
+
+func (w *jsonlStatementWriter) WriteEntry(entry *types.StatementEntry) error {
+	return w.enc.Encode(statementEntryLine{Type: "operation", StatementEntry: entry})
+}
+
+func (w *jsonlStatementWriter) WriteClosing(balance int) error {
+	return w.enc.Encode(statementBalanceLine{Type: "closing", At: w.to, Balance: balance})
+}
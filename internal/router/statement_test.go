@@ -0,0 +1,117 @@
+package router
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHandler_getStatement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	path := "/api/v1/wallet/" + walletUUID + "/statement?from=2026-09-01T00:00:00Z&to=2026-10-01T00:00:00Z"
+
+	writeStatement := func(args mock.Arguments) {
+		w := args.Get(4).(types.StatementWriter)
+		_ = w.WriteOpening(1000)
+		_ = w.WriteEntry(&types.StatementEntry{
+			AppliedAt:   time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC),
+			ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b",
+			Operation:   "WITHDRAW",
+			Amount:      100,
+			Fee:         20,
+			Balance:     880,
+		})
+		_ = w.WriteClosing(880)
+	}
+
+	setup := func(t *testing.T) (*MockWalletService, *gin.Engine) {
+		walletSvc := new(MockWalletService)
+		return walletSvc, NewHandler(walletSvc, zaptest.NewLogger(t)).InitRouter()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		walletSvc, r := setup(t)
+		walletSvc.On("StreamStatement", mock.Anything, walletUUID, from, to, mock.Anything).
+			Run(writeStatement).Return(nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="statement-`+walletUUID+`-20260901-20261001.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "type,time,reference_id,operation_type,amount,fee,balance\n"+
+			"opening,2026-09-01T00:00:00Z,,,,,1000\n"+
+			"operation,2026-09-15T12:00:00Z,5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b,WITHDRAW,100,20,880\n"+
+			"closing,2026-10-01T00:00:00Z,,,,,880\n", w.Body.String())
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		walletSvc, r := setup(t)
+		walletSvc.On("StreamStatement", mock.Anything, walletUUID, from, to, mock.Anything).
+			Run(writeStatement).Return(nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path+"&format=jsonl", nil))
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="statement-`+walletUUID+`-20260901-20261001.jsonl"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, `{"type":"opening","at":"2026-09-01T00:00:00Z","balance":1000}`+"\n"+
+			`{"type":"operation","appliedAt":"2026-09-15T12:00:00Z","referenceId":"5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b","operationType":"WITHDRAW","amount":100,"fee":20,"balance":880}`+"\n"+
+			`{"type":"closing","at":"2026-10-01T00:00:00Z","balance":880}`+"\n", w.Body.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		walletSvc, r := setup(t)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path+"&format=xlsx", nil))
+		assert.Equal(t, 400, w.Code)
+		walletSvc.AssertNotCalled(t, "StreamStatement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing period", func(t *testing.T) {
+		_, r := setup(t)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID+"/statement?from=2026-09-01T00:00:00Z", nil))
+		assert.Equal(t, 400, w.Code)
+	})
+
+	t.Run("error before the statement starts", func(t *testing.T) {
+		walletSvc, r := setup(t)
+		walletSvc.On("StreamStatement", mock.Anything, walletUUID, from, to, mock.Anything).
+			Return(types.ErrNotFound(types.ErrWalletNotFound))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, 404, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		assertProblem(t, w, types.CodeWalletNotFound, "wallet not found")
+	})
+
+	t.Run("error after the statement started", func(t *testing.T) {
+		walletSvc, r := setup(t)
+		walletSvc.On("StreamStatement", mock.Anything, walletUUID, from, to, mock.Anything).
+			Run(func(args mock.Arguments) {
+				_ = args.Get(4).(types.StatementWriter).WriteOpening(1000)
+			}).
+			Return(errors.New("connection reset"))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path+"&format=jsonl", nil))
+		assert.Equal(t, 200, w.Code)
+		assert.NotContains(t, w.Body.String(), "closing")
+		assert.NotContains(t, w.Body.String(), "error")
+	})
+}
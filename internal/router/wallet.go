@@ -3,6 +3,9 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/gin-gonic/gin"
@@ -12,12 +15,43 @@ import (
 func (h *Handler) getBalance(c *gin.Context) error {
 	walletUUID := c.Param("uuid")
 
-	balance, err := h.walletservice.GetBalance(c, walletUUID)
+	funds, err := h.walletservice.GetBalance(c, walletUUID)
 	if err != nil {
 		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"balance": balance})
+	c.JSON(http.StatusOK, funds)
+	return nil
+}
+
+func (h *Handler) getBalanceAt(c *gin.Context) error {
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		return types.ErrBadRequest(fmt.Errorf("at must be an RFC3339 time: %w", err))
+	}
+
+	balance, err := h.walletservice.GetBalanceAt(c, c.Param("uuid"), at)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, balance)
+	return nil
+}
+
+func (h *Handler) getBalances(c *gin.Context) error {
+	var req types.BalancesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	balances, err := h.walletservice.GetBalances(c, req.WalletUUIDs)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balances": balances})
 	return nil
 }
 
@@ -28,14 +62,132 @@ func (h *Handler) updateBalance(c *gin.Context) error {
 		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
 	}
 
-	// it's supposed to be generated on the client side,
-	// but for this task there isnt one, so i'll simulate it
-	wur.ReferenceID = uuid.New().String()
+	if err := h.limitWallet(c, wur.WalletUUID); err != nil {
+		return err
+	}
+
+	// ReferenceID должен генерировать клиент, чтобы повтор запроса не провёл
+	// операцию дважды. Клиенты, которые его не передают, получают новый
+	if wur.ReferenceID == "" {
+		wur.ReferenceID = uuid.New().String()
+	}
 
-	if err := h.walletservice.UpdateBalance(c, wur); err != nil {
+	async, err := asyncRequested(c)
+	if err != nil {
+		return err
+	}
+	if async {
+		return h.enqueueUpdate(c, wur)
+	}
+
+	fee, err := h.walletservice.UpdateBalance(c, wur)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "balance updated", "amount": wur.Amount, "fee": fee})
+	return nil
+}
+
+// asyncRequested reports whether the caller asked for the operation to be
+// queued, either with ?async=true or with the Prefer: respond-async header.
+func asyncRequested(c *gin.Context) (bool, error) {
+	if value, ok := c.GetQuery("async"); ok {
+		async, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, types.ErrBadRequest(fmt.Errorf("async must be a boolean: %w", err))
+		}
+		return async, nil
+	}
+
+	for _, prefer := range c.Request.Header.Values("Prefer") {
+		for _, token := range strings.Split(prefer, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// enqueueUpdate queues the operation and answers 202 with the URL its status
+// can be polled at.
+func (h *Handler) enqueueUpdate(c *gin.Context, wur *types.WalletUpdateRequest) error {
+	op, err := h.walletservice.EnqueueUpdate(c, wur)
+	if err != nil {
+		return err
+	}
+
+	statusURL := "/api/v1/operations/" + op.ReferenceID
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":     "operation accepted",
+		"referenceId": op.ReferenceID,
+		"status":      op.Status,
+		"statusUrl":   statusURL,
+	})
+	return nil
+}
+
+func (h *Handler) getOperation(c *gin.Context) error {
+	op, err := h.walletservice.GetOperation(c, c.Param("referenceId"))
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, op)
+	return nil
+}
+
+// listOperations returns the wallet's operations, the newest first. They can
+// be filtered by externalRef and by metadata[key]=value, an empty value only
+// requiring the key; limit and before page through the history.
+func (h *Handler) listOperations(c *gin.Context) error {
+	filter := types.OperationFilter{
+		ExternalRef: c.Query("externalRef"),
+		Metadata:    c.QueryMap("metadata"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return types.ErrBadRequest(fmt.Errorf("limit must be an integer: %w", err))
+		}
+		filter.Limit = n
+	}
+	if before := c.Query("before"); before != "" {
+		id, err := strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			return types.ErrBadRequest(fmt.Errorf("before must be an integer: %w", err))
+		}
+		filter.BeforeID = id
+	}
+
+	page, err := h.walletservice.ListOperations(c, c.Param("uuid"), filter)
+	if err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, page)
+	return nil
+}
+
+func (h *Handler) updateBalanceBatch(c *gin.Context) error {
+	var batch types.BatchUpdateRequest
+
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		return types.ErrBadRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+
+	reqs := batch.Requests()
+	if err := h.limitWallets(c, reqs...); err != nil {
+		return err
+	}
+
+	results, err := h.walletservice.UpdateBalanceBatch(c, batch.Mode == types.BatchModeAtomic, reqs)
+	if err != nil {
 		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "balance updated"})
+	c.JSON(http.StatusOK, gin.H{"mode": batch.Mode, "results": results})
 	return nil
 }
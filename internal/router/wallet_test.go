@@ -1,9 +1,12 @@
 package router
 
 import (
+	"errors"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/gin-gonic/gin"
@@ -22,13 +25,14 @@ func TestHandler_getBalance(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/a1b2c3e4-5678-9012-3456-789012345678", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: "a1b2c3e4-5678-9012-3456-789012345678"}}
 
-		mockService.On("GetBalance", c, "a1b2c3e4-5678-9012-3456-789012345678").Return(500, nil)
+		mockService.On("GetBalance", c, "a1b2c3e4-5678-9012-3456-789012345678").Return(types.NewFunds(500, 200), nil)
 
 		err := handler.getBalance(c)
 		require.NoError(t, err)
 		assert.Equal(t, 200, w.Code)
-		assert.JSONEq(t, `{"balance": 500}`, w.Body.String())
+		assert.JSONEq(t, `{"balance": 500, "creditLimit": 200, "available": 700}`, w.Body.String())
 
 		mockService.AssertExpectations(t)
 	})
@@ -40,8 +44,10 @@ func TestHandler_getBalance(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/invalid-uuid", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: "invalid-uuid"}}
 
-		mockService.On("GetBalance", c, "invalid-uuid").Return(500, nil)
+		mockService.On("GetBalance", c, "invalid-uuid").
+			Return(types.Funds{}, types.ErrBadRequest(errors.New("walletUUID is not valid: invalid UUID length: 12")))
 
 		err := handler.getBalance(c)
 		require.Error(t, err)
@@ -60,7 +66,7 @@ func TestHandler_getBalance(t *testing.T) {
 		c.Request = httptest.NewRequest("GET", "/", nil)
 		c.Params = gin.Params{{Key: "uuid", Value: "a1b2c3e4"}}
 
-		mockService.On("GetBalance", mock.Anything, mock.Anything).Return(0, types.ErrWalletNotFound)
+		mockService.On("GetBalance", mock.Anything, mock.Anything).Return(types.Funds{}, types.ErrNotFound(types.ErrWalletNotFound))
 
 		err := handler.getBalance(c)
 		require.Error(t, err)
@@ -71,6 +77,47 @@ func TestHandler_getBalance(t *testing.T) {
 	})
 }
 
+func TestHandler_getBalanceAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const walletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+		at := time.Date(2026, 9, 30, 23, 59, 0, 0, time.UTC)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID+"/balance?at=2026-09-30T23:59:00Z", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: walletUUID}}
+
+		mockService.On("GetBalanceAt", c, walletUUID, at).
+			Return(&types.HistoricalBalance{WalletUUID: walletUUID, At: at, Balance: 750}, nil)
+
+		err := handler.getBalanceAt(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"valletId": "`+walletUUID+`", "at": "2026-09-30T23:59:00Z", "balance": 750}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID+"/balance?at=yesterday", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: walletUUID}}
+
+		err := handler.getBalanceAt(c)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		mockService.AssertNotCalled(t, "GetBalanceAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestHandler_updateBalance(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -78,7 +125,7 @@ func TestHandler_updateBalance(t *testing.T) {
 		mockService := new(MockWalletService)
 		handler := NewHandler(mockService, nil)
 
-		body := `{"walletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100}`
+		body := `{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100}`
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -90,12 +137,33 @@ func TestHandler_updateBalance(t *testing.T) {
 				req.Operation == "DEPOSIT" &&
 				req.Amount == 100 &&
 				req.ReferenceID != ""
-		})).Return(nil)
+		})).Return(types.Fee{}, nil)
+
+		err := handler.updateBalance(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"message": "balance updated", "amount": 100, "fee": {"flat": 0, "percent": 0, "total": 0}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("client reference id is kept", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		body := `{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100, "referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"}`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("UpdateBalance", mock.Anything, mock.MatchedBy(func(req *types.WalletUpdateRequest) bool {
+			return req.ReferenceID == "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+		})).Return(types.Fee{}, nil)
 
 		err := handler.updateBalance(c)
 		require.NoError(t, err)
 		assert.Equal(t, 200, w.Code)
-		assert.JSONEq(t, `{"message": "balance updated"}`, w.Body.String())
 		mockService.AssertExpectations(t)
 	})
 
@@ -120,7 +188,7 @@ func TestHandler_updateBalance(t *testing.T) {
 		mockService := new(MockWalletService)
 		handler := NewHandler(mockService, nil)
 
-		body := `{"walletId": "", "operationType": "DEPOSIT", "amount": 100}`
+		body := `{"valletId": "", "operationType": "DEPOSIT", "amount": 100}`
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/", strings.NewReader(body))
@@ -131,20 +199,20 @@ func TestHandler_updateBalance(t *testing.T) {
 		httpErr, ok := err.(types.HTTPError)
 		require.True(t, ok)
 		assert.Equal(t, 400, httpErr.Code)
-		assert.Contains(t, err.Error(), "walletUUID is empty")
+		assert.Contains(t, err.Error(), "invalid request body")
 	})
 
 	t.Run("service returns insufficient funds", func(t *testing.T) {
 		mockService := new(MockWalletService)
 		handler := NewHandler(mockService, nil)
 
-		body := `{"walletId": "a1b2c3e4.", "operationType": "WITHDRAW", "amount": 100}`
+		body := `{"valletId": "a1b2c3e4.", "operationType": "WITHDRAW", "amount": 100}`
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/", strings.NewReader(body))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		mockService.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.ErrInsufficientFunds)
+		mockService.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.Fee{}, types.ErrBadRequest(types.ErrInsufficientFunds))
 
 		err := handler.updateBalance(c)
 		require.Error(t, err)
@@ -154,3 +222,342 @@ func TestHandler_updateBalance(t *testing.T) {
 		assert.Contains(t, err.Error(), "insufficient funds")
 	})
 }
+
+func TestHandler_updateBalanceAsync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100, "referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"}`
+	queued := &types.Operation{
+		WalletUUID:  "a1b2c3e4-5678-9012-3456-789012345678",
+		Operation:   types.OperationTypeDeposit,
+		Amount:      100,
+		ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b",
+		Status:      "PENDING",
+		Async:       true,
+	}
+
+	for name, setup := range map[string]func(r *http.Request){
+		"query parameter": func(r *http.Request) { r.URL.RawQuery = "async=true" },
+		"prefer header":   func(r *http.Request) { r.Header.Set("Prefer", "wait=5, respond-async") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockWalletService)
+			handler := NewHandler(mockService, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			setup(c.Request)
+
+			mockService.On("EnqueueUpdate", mock.Anything, mock.MatchedBy(func(req *types.WalletUpdateRequest) bool {
+				return req.ReferenceID == queued.ReferenceID
+			})).Return(queued, nil)
+
+			err := handler.updateBalance(c)
+			require.NoError(t, err)
+			assert.Equal(t, 202, w.Code)
+			assert.Equal(t, "/api/v1/operations/5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", w.Header().Get("Location"))
+			assert.JSONEq(t, `{
+				"message": "operation accepted",
+				"referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b",
+				"status": "PENDING",
+				"statusUrl": "/api/v1/operations/5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+			}`, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("async=false applies synchronously", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet?async=false", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("UpdateBalance", mock.Anything, mock.Anything).Return(types.Fee{}, nil)
+
+		err := handler.updateBalance(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid async flag", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet?async=maybe", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		err := handler.updateBalance(c)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		mockService.AssertNotCalled(t, "EnqueueUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("enqueue fails", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet?async=1", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("EnqueueUpdate", mock.Anything, mock.Anything).Return(nil, types.ErrConflict(types.ErrOperationExists))
+
+		err := handler.updateBalance(c)
+		require.Error(t, err)
+		assert.Equal(t, 409, err.(types.HTTPError).Code)
+	})
+}
+
+func TestHandler_getOperation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	referenceID := "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"
+
+	t.Run("failed operation", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/operations/"+referenceID, nil)
+		c.Params = gin.Params{{Key: "referenceId", Value: referenceID}}
+
+		mockService.On("GetOperation", c, referenceID).Return(&types.Operation{
+			ID:             7,
+			WalletUUID:     "a1b2c3e4-5678-9012-3456-789012345678",
+			Operation:      types.OperationTypeWithdraw,
+			Amount:         100,
+			ReferenceID:    referenceID,
+			Status:         "FAILED",
+			Async:          true,
+			Attempts:       1,
+			FailureCode:    types.FailureInsufficientFunds,
+			FailureMessage: "insufficient funds",
+		}, nil)
+
+		err := handler.getOperation(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"FAILED"`)
+		assert.Contains(t, w.Body.String(), `"failureCode":"INSUFFICIENT_FUNDS"`)
+		assert.Contains(t, w.Body.String(), `"failureMessage":"insufficient funds"`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/operations/"+referenceID, nil)
+		c.Params = gin.Params{{Key: "referenceId", Value: referenceID}}
+
+		mockService.On("GetOperation", c, referenceID).Return(nil, types.ErrNotFound(types.ErrOperationNotFound))
+
+		err := handler.getOperation(c)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+}
+
+func TestHandler_updateBalanceBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid request - success", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		body := `{"mode": "best_effort", "items": [
+			{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100, "referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"},
+			{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "WITHDRAW", "amount": 900, "referenceId": "6c8d1c6f-3b65-4e5f-8b74-2a1f5b2e3d4c"}
+		]}`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/batch", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("UpdateBalanceBatch", mock.Anything, false, mock.MatchedBy(func(reqs []*types.WalletUpdateRequest) bool {
+			return len(reqs) == 2 &&
+				reqs[0].ReferenceID == "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b" &&
+				reqs[1].Operation == "WITHDRAW" &&
+				reqs[1].Amount == 900
+		})).Return([]types.BatchItemResult{
+			{ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", Status: 200},
+			{ReferenceID: "6c8d1c6f-3b65-4e5f-8b74-2a1f5b2e3d4c", Status: 400, Error: "insufficient funds"},
+		}, nil)
+
+		err := handler.updateBalanceBatch(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"mode": "best_effort", "results": [
+			{"referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", "status": 200},
+			{"referenceId": "6c8d1c6f-3b65-4e5f-8b74-2a1f5b2e3d4c", "status": 400, "error": "insufficient funds"}
+		]}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		body := `{"mode": "sometimes", "items": [
+			{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "DEPOSIT", "amount": 100, "referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"}
+		]}`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/batch", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		err := handler.updateBalanceBatch(c)
+		require.Error(t, err)
+		httpErr, ok := err.(types.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, 400, httpErr.Code)
+		assert.Contains(t, err.Error(), "invalid request body")
+		mockService.AssertNotCalled(t, "UpdateBalanceBatch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic batch fails", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		body := `{"mode": "atomic", "items": [
+			{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "operationType": "WITHDRAW", "amount": 100, "referenceId": "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b"}
+		]}`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/batch", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("UpdateBalanceBatch", mock.Anything, true, mock.Anything).
+			Return(nil, types.ErrBadRequest(&types.BatchItemError{Index: 0, ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b", Err: types.ErrInsufficientFunds}))
+
+		err := handler.updateBalanceBatch(c)
+		require.Error(t, err)
+		httpErr, ok := err.(types.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, 400, httpErr.Code)
+		assert.Contains(t, err.Error(), "item 0")
+	})
+}
+
+func TestHandler_getBalances(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid request - success", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		body := `{"valletIds": ["a1b2c3e4-5678-9012-3456-789012345678", "b2c3d4e5-6789-0123-4567-890123456789"]}`
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/balances", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockService.On("GetBalances", mock.Anything, []string{
+			"a1b2c3e4-5678-9012-3456-789012345678",
+			"b2c3d4e5-6789-0123-4567-890123456789",
+		}).Return([]types.WalletBalance{
+			{WalletUUID: "a1b2c3e4-5678-9012-3456-789012345678", Funds: types.NewFunds(500, 0), Found: true},
+			{WalletUUID: "b2c3d4e5-6789-0123-4567-890123456789", Found: false},
+		}, nil)
+
+		err := handler.getBalances(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"balances": [
+			{"valletId": "a1b2c3e4-5678-9012-3456-789012345678", "balance": 500, "creditLimit": 0, "available": 500, "found": true},
+			{"valletId": "b2c3d4e5-6789-0123-4567-890123456789", "balance": 0, "creditLimit": 0, "available": 0, "found": false}
+		]}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/wallet/balances", strings.NewReader(`{"valletIds": []}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		err := handler.getBalances(c)
+		require.Error(t, err)
+		httpErr, ok := err.(types.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, 400, httpErr.Code)
+		assert.Contains(t, err.Error(), "invalid request body")
+	})
+}
+
+func TestHandler_listOperations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	walletUUID := "a1b2c3e4-5678-9012-3456-789012345678"
+
+	t.Run("filters", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID+
+			"/operations?externalRef=INV-1&metadata[order]=42&metadata[promo]=&limit=2&before=10", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: walletUUID}}
+
+		mockService.On("ListOperations", c, walletUUID, types.OperationFilter{
+			ExternalRef: "INV-1",
+			Metadata:    map[string]string{"order": "42", "promo": ""},
+			Limit:       2,
+			BeforeID:    10,
+		}).Return(&types.OperationsPage{
+			Operations: []*types.Operation{{
+				ID:          9,
+				WalletUUID:  walletUUID,
+				Operation:   types.OperationTypeDeposit,
+				Amount:      100,
+				ReferenceID: "5b7c0b5e-2a54-4d4e-9a63-1f0e4a1d2c3b",
+				Status:      "APPLIED",
+				OperationDetails: types.OperationDetails{
+					ExternalRef: "INV-1",
+					Metadata:    map[string]string{"order": "42", "promo": "autumn"},
+				},
+			}},
+			NextBeforeID: 9,
+		}, nil)
+
+		err := handler.listOperations(c)
+		require.NoError(t, err)
+		assert.Equal(t, 200, w.Code)
+		assert.Contains(t, w.Body.String(), `"externalRef":"INV-1"`)
+		assert.Contains(t, w.Body.String(), `"metadata":{"order":"42","promo":"autumn"}`)
+		assert.Contains(t, w.Body.String(), `"nextBeforeId":9`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		mockService := new(MockWalletService)
+		handler := NewHandler(mockService, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/wallet/"+walletUUID+"/operations?limit=ten", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: walletUUID}}
+
+		err := handler.listOperations(c)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		mockService.AssertNotCalled(t, "ListOperations", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
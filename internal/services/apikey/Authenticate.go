@@ -0,0 +1,45 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// Authenticate returns the key matching rawKey and records its use.
+func (s *Service) Authenticate(ctx context.Context, rawKey string) (*types.APIKey, error) {
+	if rawKey == "" {
+		return nil, types.ErrUnauthorized(types.ErrAPIKeyMissing)
+	}
+
+	if !wellFormed(rawKey) {
+		s.logger.Warn("Authenticate: malformed api key")
+		return nil, types.ErrUnauthorized(types.ErrAPIKeyInvalid)
+	}
+
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashKey(rawKey))
+	if err != nil {
+		if errors.Is(err, types.ErrAPIKeyNotFound) {
+			s.logger.Warn("Authenticate: unknown api key")
+			return nil, types.ErrUnauthorized(types.ErrAPIKeyInvalid)
+		}
+		s.logger.Error("Authenticate: failed to load api key", zap.Error(err))
+		return nil, types.ErrInternalServerError(err)
+	}
+
+	logger := s.logger.With(zap.Int64("api_key_id", key.ID), zap.String("api_key_prefix", key.Prefix))
+
+	if key.Revoked() {
+		logger.Warn("Authenticate: revoked api key")
+		return nil, types.ErrUnauthorized(types.ErrAPIKeyRevoked)
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+		// Не отклоняем запрос, время последнего использования не критично
+		logger.Warn("Authenticate: failed to record api key use", zap.Error(err))
+	}
+
+	return key, nil
+}
@@ -0,0 +1,116 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/apikey/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_Authenticate(t *testing.T) {
+	rawKey, prefix, err := generateKey()
+	require.NoError(t, err)
+
+	revokedAt := time.Now()
+	active := &types.APIKey{ID: 1, Prefix: prefix, KeyHash: hashKey(rawKey), Scopes: []string{types.ScopeWalletRead}}
+	revoked := &types.APIKey{ID: 2, Prefix: prefix, KeyHash: hashKey(rawKey), RevokedAt: &revokedAt}
+
+	tests := []struct {
+		name         string
+		rawKey       string
+		mockSetup    func(*mocks.MockReadWriter)
+		expectedErr  error
+		expectedCode int
+	}{
+		{
+			name:   "success",
+			rawKey: rawKey,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashKey(rawKey)).Return(active, nil)
+				m.EXPECT().TouchAPIKey(gomock.Any(), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:   "touch failure does not reject the request",
+			rawKey: rawKey,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashKey(rawKey)).Return(active, nil)
+				m.EXPECT().TouchAPIKey(gomock.Any(), int64(1)).Return(errors.New("db error"))
+			},
+		},
+		{
+			name:         "missing key",
+			rawKey:       "",
+			mockSetup:    func(m *mocks.MockReadWriter) {},
+			expectedErr:  types.ErrAPIKeyMissing,
+			expectedCode: 401,
+		},
+		{
+			name:         "malformed key",
+			rawKey:       "let-me-in",
+			mockSetup:    func(m *mocks.MockReadWriter) {},
+			expectedErr:  types.ErrAPIKeyInvalid,
+			expectedCode: 401,
+		},
+		{
+			name:   "unknown key",
+			rawKey: rawKey,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashKey(rawKey)).Return(nil, types.ErrAPIKeyNotFound)
+			},
+			expectedErr:  types.ErrAPIKeyInvalid,
+			expectedCode: 401,
+		},
+		{
+			name:   "revoked key",
+			rawKey: rawKey,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashKey(rawKey)).Return(revoked, nil)
+			},
+			expectedErr:  types.ErrAPIKeyRevoked,
+			expectedCode: 401,
+		},
+		{
+			name:   "repository error",
+			rawKey: rawKey,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashKey(rawKey)).Return(nil, errors.New("db error"))
+			},
+			expectedCode: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReadWriter(ctrl)
+			tt.mockSetup(mockRepo)
+
+			logger, _ := zap.NewDevelopment()
+			svc := NewService(mockRepo, logger)
+
+			key, err := svc.Authenticate(context.Background(), tt.rawKey)
+			if tt.expectedCode != 0 {
+				require.Error(t, err)
+				assert.Nil(t, key)
+				assert.Equal(t, tt.expectedCode, err.(types.HTTPError).Code)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, active, key)
+		})
+	}
+}
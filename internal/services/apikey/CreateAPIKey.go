@@ -0,0 +1,54 @@
+package apikeyservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// CreateAPIKey creates a key with the given scopes. The returned raw key is
+// not stored anywhere and cannot be recovered later.
+func (s *Service) CreateAPIKey(ctx context.Context, name string, scopes []string) (*types.APIKey, string, error) {
+	logger := s.logger.With(zap.String("name", name), zap.Strings("scopes", scopes))
+	logger.Info("CreateAPIKey called")
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", types.ErrBadRequest(fmt.Errorf("name is empty"))
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", types.ErrBadRequest(fmt.Errorf("at least one scope is required"))
+	}
+	for _, scope := range scopes {
+		if !types.ValidScope(scope) {
+			return nil, "", types.ErrBadRequest(fmt.Errorf("unknown scope %q", scope))
+		}
+	}
+
+	rawKey, prefix, err := generateKey()
+	if err != nil {
+		logger.Error("CreateAPIKey: failed to generate key", zap.Error(err))
+		return nil, "", types.ErrInternalServerError(err)
+	}
+
+	key := &types.APIKey{
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hashKey(rawKey),
+		Scopes:  scopes,
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		logger.Error("CreateAPIKey: failed to store key", zap.Error(err))
+		return nil, "", types.ErrInternalServerError(err)
+	}
+
+	logger.Info("CreateAPIKey: success",
+		zap.Int64("id", key.ID),
+		zap.String("prefix", key.Prefix))
+
+	return key, rawKey, nil
+}
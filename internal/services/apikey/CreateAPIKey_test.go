@@ -0,0 +1,94 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/apikey/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestService_CreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		keyName      string
+		scopes       []string
+		mockSetup    func(*mocks.MockReadWriter)
+		expectedCode int
+	}{
+		{
+			name:    "success",
+			keyName: "payroll",
+			scopes:  []string{types.ScopeWalletWrite},
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *types.APIKey) error {
+					key.ID = 1
+					return nil
+				})
+			},
+		},
+		{
+			name:         "empty name",
+			keyName:      "  ",
+			scopes:       []string{types.ScopeWalletWrite},
+			mockSetup:    func(m *mocks.MockReadWriter) {},
+			expectedCode: 400,
+		},
+		{
+			name:         "no scopes",
+			keyName:      "payroll",
+			mockSetup:    func(m *mocks.MockReadWriter) {},
+			expectedCode: 400,
+		},
+		{
+			name:         "unknown scope",
+			keyName:      "payroll",
+			scopes:       []string{"wallet:everything"},
+			mockSetup:    func(m *mocks.MockReadWriter) {},
+			expectedCode: 400,
+		},
+		{
+			name:    "repository error",
+			keyName: "payroll",
+			scopes:  []string{types.ScopeAdmin},
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedCode: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReadWriter(ctrl)
+			tt.mockSetup(mockRepo)
+
+			logger, _ := zap.NewDevelopment()
+			svc := NewService(mockRepo, logger)
+
+			key, rawKey, err := svc.CreateAPIKey(context.Background(), tt.keyName, tt.scopes)
+			if tt.expectedCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedCode, err.(types.HTTPError).Code)
+				assert.Nil(t, key)
+				assert.Empty(t, rawKey)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), key.ID)
+			assert.Equal(t, tt.scopes, key.Scopes)
+			assert.True(t, wellFormed(rawKey))
+			assert.Equal(t, hashKey(rawKey), key.KeyHash)
+			assert.NotContains(t, key.KeyHash, rawKey)
+		})
+	}
+}
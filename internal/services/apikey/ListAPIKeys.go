@@ -0,0 +1,18 @@
+package apikeyservice
+
+import (
+	"context"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]*types.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		s.logger.Error("ListAPIKeys: failed to list", zap.Error(err))
+		return nil, types.ErrInternalServerError(err)
+	}
+
+	return keys, nil
+}
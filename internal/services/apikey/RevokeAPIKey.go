@@ -0,0 +1,26 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+func (s *Service) RevokeAPIKey(ctx context.Context, id int64) error {
+	logger := s.logger.With(zap.Int64("api_key_id", id))
+	logger.Info("RevokeAPIKey called")
+
+	if err := s.repo.RevokeAPIKey(ctx, id); err != nil {
+		if errors.Is(err, types.ErrAPIKeyNotFound) {
+			return types.ErrNotFound(err)
+		}
+		logger.Error("RevokeAPIKey: failed to revoke", zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+
+	logger.Info("RevokeAPIKey: success")
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package apikeyservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/apikey/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestService_RevokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		repoErr      error
+		expectedCode int
+	}{
+		{name: "success"},
+		{name: "not found", repoErr: types.ErrAPIKeyNotFound, expectedCode: 404},
+		{name: "repository error", repoErr: errors.New("db error"), expectedCode: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReadWriter(ctrl)
+			mockRepo.EXPECT().RevokeAPIKey(gomock.Any(), int64(7)).Return(tt.repoErr)
+
+			logger, _ := zap.NewDevelopment()
+			svc := NewService(mockRepo, logger)
+
+			err := svc.RevokeAPIKey(context.Background(), 7)
+			if tt.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedCode, err.(types.HTTPError).Code)
+		})
+	}
+}
+
+func TestService_ListAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReadWriter(ctrl)
+	logger, _ := zap.NewDevelopment()
+	svc := NewService(mockRepo, logger)
+
+	keys := []*types.APIKey{{ID: 1, Name: "payroll"}}
+	mockRepo.EXPECT().ListAPIKeys(gomock.Any()).Return(keys, nil)
+
+	got, err := svc.ListAPIKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, keys, got)
+
+	mockRepo.EXPECT().ListAPIKeys(gomock.Any()).Return(nil, errors.New("db error"))
+	_, err = svc.ListAPIKeys(context.Background())
+	assert.Equal(t, 500, err.(types.HTTPError).Code)
+}
@@ -0,0 +1,48 @@
+package apikeyservice
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Keys look like wk_<prefix>_<secret>. The prefix is stored in plain text so a
+// key can be recognised in listings, only the hash of the whole key is stored.
+const (
+	keyScheme      = "wk"
+	prefixBytes    = 6
+	secretBytes    = 32
+	keyPartsLength = 3
+)
+
+func generateKey() (rawKey, prefix string, err error) {
+	prefixBuf := make([]byte, prefixBytes)
+	if _, err := rand.Read(prefixBuf); err != nil {
+		return "", "", fmt.Errorf("failed to generate key prefix: %w", err)
+	}
+	secretBuf := make([]byte, secretBytes)
+	if _, err := rand.Read(secretBuf); err != nil {
+		return "", "", fmt.Errorf("failed to generate key secret: %w", err)
+	}
+
+	prefix = hex.EncodeToString(prefixBuf)
+	rawKey = keyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBuf)
+	return rawKey, prefix, nil
+}
+
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// wellFormed rejects obviously wrong keys before they reach the database.
+func wellFormed(rawKey string) bool {
+	parts := strings.SplitN(rawKey, "_", keyPartsLength)
+	return len(parts) == keyPartsLength &&
+		parts[0] == keyScheme &&
+		len(parts[1]) == hex.EncodedLen(prefixBytes) &&
+		len(parts[2]) == base64.RawURLEncoding.EncodedLen(secretBytes)
+}
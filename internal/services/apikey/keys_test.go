@@ -0,0 +1,35 @@
+package apikeyservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKey(t *testing.T) {
+	rawKey, prefix, err := generateKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(rawKey, "wk_"+prefix+"_"))
+	assert.True(t, wellFormed(rawKey))
+
+	other, _, err := generateKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, rawKey, other)
+}
+
+func TestHashKey(t *testing.T) {
+	assert.Equal(t, hashKey("wk_key"), hashKey("wk_key"))
+	assert.NotEqual(t, hashKey("wk_key"), hashKey("wk_other"))
+	assert.Len(t, hashKey("wk_key"), 64)
+}
+
+func TestWellFormed(t *testing.T) {
+	assert.False(t, wellFormed(""))
+	assert.False(t, wellFormed("not-a-key"))
+	assert.False(t, wellFormed("wk_a1b2c3d4e5f6_short"))
+	assert.False(t, wellFormed("xx_a1b2c3d4e5f6_"+strings.Repeat("a", 43)))
+	assert.True(t, wellFormed("wk_a1b2c3d4e5f6_"+strings.Repeat("a", 43)))
+}
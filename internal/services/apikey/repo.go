@@ -0,0 +1,23 @@
+package apikeyservice
+
+import (
+	"context"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+type Reader interface {
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*types.APIKey, error)
+}
+
+type Writer interface {
+	CreateAPIKey(ctx context.Context, key *types.APIKey) error
+	RevokeAPIKey(ctx context.Context, id int64) error
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+type ReadWriter interface {
+	Reader
+	Writer
+}
@@ -0,0 +1,24 @@
+package apikeyservice
+
+import (
+	"context"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+type ServiceInterface interface {
+	Authenticate(ctx context.Context, rawKey string) (*types.APIKey, error)
+}
+
+type Service struct {
+	repo   ReadWriter
+	logger *zap.Logger
+}
+
+func NewService(repo ReadWriter, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
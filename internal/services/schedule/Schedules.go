@@ -0,0 +1,241 @@
+package scheduleservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxListedRuns is how many of the latest runs ListScheduleRuns returns.
+const maxListedRuns = 100
+
+// CreateSchedule schedules an operation once at req.RunAt or repeatedly
+// according to req.Recurrence.
+func (s *Service) CreateSchedule(ctx context.Context, req *types.ScheduleRequest) (*types.Schedule, error) {
+	logger := s.logger.With(zap.String("wallet_uuid", req.WalletUUID))
+	logger.Info("CreateSchedule called")
+
+	if _, err := uuid.Parse(req.WalletUUID); err != nil {
+		logger.Warn("CreateSchedule: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if req.Operation != types.OperationTypeDeposit && req.Operation != types.OperationTypeWithdraw {
+		return nil, types.ErrBadRequest(fmt.Errorf(
+			"operation must be either %s or %s",
+			types.OperationTypeDeposit,
+			types.OperationTypeWithdraw,
+		))
+	}
+
+	if req.Amount <= 0 {
+		return nil, types.ErrBadRequest(fmt.Errorf("amount must be positive"))
+	}
+
+	if (req.RunAt == nil) == (req.Recurrence == "") {
+		logger.Warn("CreateSchedule: neither or both of runAt and recurrence are set")
+		return nil, types.ErrBadRequest(fmt.Errorf("exactly one of runAt and recurrence must be set"))
+	}
+
+	now := s.now()
+	schedule := &types.Schedule{
+		WalletUUID: req.WalletUUID,
+		Operation:  req.Operation,
+		Amount:     req.Amount,
+		Status:     types.ScheduleStatusActive,
+	}
+	if ownerID, ok := types.OwnerFromContext(ctx); ok {
+		schedule.CreatedBy = ownerID
+	}
+
+	if req.RunAt != nil {
+		if !req.RunAt.After(now) {
+			return nil, types.ErrBadRequest(fmt.Errorf("runAt must be in the future"))
+		}
+		runAt := req.RunAt.UTC()
+		schedule.RunAt = &runAt
+		schedule.NextRunAt = &runAt
+	} else {
+		next, err := nextOccurrence(req.Recurrence, now)
+		if err != nil {
+			logger.Warn("CreateSchedule: invalid recurrence",
+				zap.String("recurrence", req.Recurrence),
+				zap.Error(err))
+			return nil, types.ErrBadRequest(err)
+		}
+		schedule.Recurrence = req.Recurrence
+		schedule.NextRunAt = &next
+	}
+
+	if err := s.checkWallet(ctx, logger, req.WalletUUID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	logger.Info("CreateSchedule: success",
+		zap.String("schedule_id", schedule.ID),
+		zap.Timep("next_run_at", schedule.NextRunAt))
+
+	return schedule, nil
+}
+
+func (s *Service) GetSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	logger := s.logger.With(zap.String("schedule_id", id))
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.ErrBadRequest(fmt.Errorf("schedule id is not valid: %w", err))
+	}
+
+	return s.loadSchedule(ctx, logger, id)
+}
+
+// ListSchedules returns all schedules of a wallet, including finished ones.
+func (s *Service) ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error) {
+	logger := s.logger.With(zap.String("wallet_uuid", walletUUID))
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if err := s.checkWallet(ctx, logger, walletUUID); err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.repo.ListSchedules(ctx, walletUUID)
+	if err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	return schedules, nil
+}
+
+// UpdateSchedule changes the amount or the recurrence of a schedule, or pauses
+// and resumes it. Resuming clears the failure count; the occurrence the
+// schedule was paused at is run first.
+func (s *Service) UpdateSchedule(ctx context.Context, id string, update *types.ScheduleUpdate) (*types.Schedule, error) {
+	logger := s.logger.With(zap.String("schedule_id", id))
+	logger.Info("UpdateSchedule called")
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.ErrBadRequest(fmt.Errorf("schedule id is not valid: %w", err))
+	}
+
+	schedule, err := s.loadSchedule(ctx, logger, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isFinished(schedule) {
+		logger.Info("UpdateSchedule: schedule is finished",
+			zap.String("status", schedule.Status))
+		return nil, types.ErrConflict(fmt.Errorf("schedule is %s", schedule.Status))
+	}
+
+	changes := &types.ScheduleChanges{}
+	if update.Amount != nil {
+		if *update.Amount <= 0 {
+			return nil, types.ErrBadRequest(fmt.Errorf("amount must be positive"))
+		}
+		schedule.Amount = *update.Amount
+		changes.Amount = update.Amount
+	}
+
+	if update.Recurrence != nil {
+		if schedule.Recurrence == "" {
+			return nil, types.ErrBadRequest(fmt.Errorf("recurrence of a one-off schedule cannot be set"))
+		}
+		next, err := nextOccurrence(*update.Recurrence, s.now())
+		if err != nil {
+			return nil, types.ErrBadRequest(err)
+		}
+		schedule.Recurrence = *update.Recurrence
+		schedule.NextRunAt = &next
+		changes.Recurrence = update.Recurrence
+		changes.NextRunAt = &next
+	}
+
+	if update.Status != nil {
+		switch *update.Status {
+		case types.ScheduleStatusActive:
+			if schedule.Status == types.ScheduleStatusPaused {
+				schedule.Failures = 0
+				changes.ResetFailures = true
+			}
+		case types.ScheduleStatusPaused:
+		default:
+			return nil, types.ErrBadRequest(fmt.Errorf("status must be either %s or %s",
+				types.ScheduleStatusActive, types.ScheduleStatusPaused))
+		}
+		schedule.Status = *update.Status
+		changes.Status = update.Status
+	}
+
+	if err := s.repo.UpdateSchedule(ctx, id, changes); err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	logger.Info("UpdateSchedule: success",
+		zap.String("status", schedule.Status))
+
+	return schedule, nil
+}
+
+// CancelSchedule stops a schedule for good. Its runs are kept.
+func (s *Service) CancelSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	logger := s.logger.With(zap.String("schedule_id", id))
+	logger.Info("CancelSchedule called")
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.ErrBadRequest(fmt.Errorf("schedule id is not valid: %w", err))
+	}
+
+	schedule, err := s.loadSchedule(ctx, logger, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isFinished(schedule) {
+		return nil, types.ErrConflict(fmt.Errorf("schedule is %s", schedule.Status))
+	}
+
+	schedule.Status = types.ScheduleStatusCancelled
+	schedule.NextRunAt = nil
+
+	if err := s.repo.UpdateSchedule(ctx, id, &types.ScheduleChanges{
+		Status:         &schedule.Status,
+		ClearNextRunAt: true,
+	}); err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	logger.Info("CancelSchedule: success")
+
+	return schedule, nil
+}
+
+// ListScheduleRuns returns the latest runs of a schedule.
+func (s *Service) ListScheduleRuns(ctx context.Context, id string) ([]types.ScheduleRun, error) {
+	logger := s.logger.With(zap.String("schedule_id", id))
+
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, types.ErrBadRequest(fmt.Errorf("schedule id is not valid: %w", err))
+	}
+
+	if _, err := s.loadSchedule(ctx, logger, id); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.repo.ListScheduleRuns(ctx, id, maxListedRuns)
+	if err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	return runs, nil
+}
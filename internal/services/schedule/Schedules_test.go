@@ -0,0 +1,263 @@
+package scheduleservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/schedule/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testScheduleID = "7d1c2a3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	testWalletUUID = "a1b2c3e4-5678-9012-3456-789012345678"
+)
+
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T) (*Service, *mocks.MockReadWriter) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockReadWriter(ctrl)
+	s := NewService(repo, zap.NewNop())
+	s.now = func() time.Time { return testNow }
+	return s, repo
+}
+
+func TestService_CreateSchedule(t *testing.T) {
+	ctx := context.Background()
+	runAt := testNow.Add(24 * time.Hour)
+	past := testNow.Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		req          types.ScheduleRequest
+		ctx          context.Context
+		mockSetup    func(*mocks.MockReadWriter)
+		expectedNext time.Time
+		expectedCode int
+	}{
+		{
+			name: "recurring",
+			req:  types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, Recurrence: "0 0 1 * *"},
+			ctx:  ctx,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedNext: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "one-off",
+			req:  types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "DEPOSIT", Amount: 500, RunAt: &runAt},
+			ctx:  ctx,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedNext: runAt,
+		},
+		{
+			name: "owned wallet",
+			req:  types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, Recurrence: "@monthly"},
+			ctx:  types.ContextWithOwner(ctx, "user-1"),
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetWalletOwner(gomock.Any(), testWalletUUID).Return("user-1", nil)
+				m.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *types.Schedule) error {
+					assert.Equal(t, "user-1", s.CreatedBy)
+					return nil
+				})
+			},
+			expectedNext: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "foreign wallet",
+			req:  types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, Recurrence: "@monthly"},
+			ctx:  types.ContextWithOwner(ctx, "user-2"),
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().GetWalletOwner(gomock.Any(), testWalletUUID).Return("user-1", nil)
+			},
+			expectedCode: 404,
+		},
+		{
+			name:         "neither runAt nor recurrence",
+			req:          types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500},
+			ctx:          ctx,
+			expectedCode: 400,
+		},
+		{
+			name:         "both runAt and recurrence",
+			req:          types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, RunAt: &runAt, Recurrence: "@monthly"},
+			ctx:          ctx,
+			expectedCode: 400,
+		},
+		{
+			name:         "runAt in the past",
+			req:          types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, RunAt: &past},
+			ctx:          ctx,
+			expectedCode: 400,
+		},
+		{
+			name:         "invalid recurrence",
+			req:          types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, Recurrence: "every month"},
+			ctx:          ctx,
+			expectedCode: 400,
+		},
+		{
+			name:         "invalid wallet UUID",
+			req:          types.ScheduleRequest{WalletUUID: "invalid-uuid", Operation: "WITHDRAW", Amount: 500, Recurrence: "@monthly"},
+			ctx:          ctx,
+			expectedCode: 400,
+		},
+		{
+			name: "wallet not found",
+			req:  types.ScheduleRequest{WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500, Recurrence: "@monthly"},
+			ctx:  ctx,
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(types.ErrWalletNotFound)
+			},
+			expectedCode: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newTestService(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repo)
+			}
+
+			schedule, err := s.CreateSchedule(tt.ctx, &tt.req)
+			if tt.expectedCode != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedCode, err.(types.HTTPError).Code)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, types.ScheduleStatusActive, schedule.Status)
+			assert.Equal(t, tt.expectedNext, *schedule.NextRunAt)
+		})
+	}
+}
+
+func TestService_UpdateSchedule(t *testing.T) {
+	ctx := context.Background()
+	paused := types.ScheduleStatusPaused
+	active := types.ScheduleStatusActive
+	amount := 700
+	recurrence := "0 0 15 * *"
+
+	newSchedule := func(status string) *types.Schedule {
+		next := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		return &types.Schedule{
+			ID: testScheduleID, WalletUUID: testWalletUUID, Operation: "WITHDRAW", Amount: 500,
+			Recurrence: "0 0 1 * *", Status: status, NextRunAt: &next, Failures: 3,
+		}
+	}
+
+	t.Run("resume clears failures and keeps the occurrence", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(newSchedule(types.ScheduleStatusPaused), nil)
+		repo.EXPECT().UpdateSchedule(gomock.Any(), testScheduleID, &types.ScheduleChanges{Status: &active, ResetFailures: true}).Return(nil)
+
+		schedule, err := s.UpdateSchedule(ctx, testScheduleID, &types.ScheduleUpdate{Status: &active})
+		require.NoError(t, err)
+		assert.Equal(t, types.ScheduleStatusActive, schedule.Status)
+		assert.Equal(t, 0, schedule.Failures)
+		assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *schedule.NextRunAt)
+	})
+
+	t.Run("change amount and recurrence", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(newSchedule(types.ScheduleStatusActive), nil)
+		next := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+		repo.EXPECT().UpdateSchedule(gomock.Any(), testScheduleID, &types.ScheduleChanges{
+			Amount:     &amount,
+			Recurrence: &recurrence,
+			NextRunAt:  &next,
+		}).Return(nil)
+
+		schedule, err := s.UpdateSchedule(ctx, testScheduleID, &types.ScheduleUpdate{Amount: &amount, Recurrence: &recurrence})
+		require.NoError(t, err)
+		assert.Equal(t, 700, schedule.Amount)
+		assert.Equal(t, time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC), *schedule.NextRunAt)
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(newSchedule(types.ScheduleStatusActive), nil)
+		repo.EXPECT().UpdateSchedule(gomock.Any(), testScheduleID, &types.ScheduleChanges{Status: &paused}).Return(nil)
+
+		schedule, err := s.UpdateSchedule(ctx, testScheduleID, &types.ScheduleUpdate{Status: &paused})
+		require.NoError(t, err)
+		assert.Equal(t, types.ScheduleStatusPaused, schedule.Status)
+	})
+
+	t.Run("finished schedule", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(newSchedule(types.ScheduleStatusCancelled), nil)
+
+		_, err := s.UpdateSchedule(ctx, testScheduleID, &types.ScheduleUpdate{Status: &active})
+		require.Error(t, err)
+		assert.Equal(t, 409, err.(types.HTTPError).Code)
+	})
+
+	t.Run("foreign schedule", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(newSchedule(types.ScheduleStatusActive), nil)
+		repo.EXPECT().GetWalletOwner(gomock.Any(), testWalletUUID).Return("user-1", nil)
+
+		_, err := s.UpdateSchedule(types.ContextWithOwner(ctx, "user-2"), testScheduleID, &types.ScheduleUpdate{Status: &paused})
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		assert.ErrorIs(t, err, types.ErrScheduleNotFound)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).Return(nil, types.ErrScheduleNotFound)
+
+		_, err := s.UpdateSchedule(ctx, testScheduleID, &types.ScheduleUpdate{Status: &paused})
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+}
+
+func TestService_CancelSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		s, repo := newTestService(t)
+		next := testNow
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).
+			Return(&types.Schedule{ID: testScheduleID, WalletUUID: testWalletUUID, Status: types.ScheduleStatusActive, NextRunAt: &next}, nil)
+		cancelled := types.ScheduleStatusCancelled
+		repo.EXPECT().UpdateSchedule(gomock.Any(), testScheduleID, &types.ScheduleChanges{Status: &cancelled, ClearNextRunAt: true}).Return(nil)
+
+		schedule, err := s.CancelSchedule(ctx, testScheduleID)
+		require.NoError(t, err)
+		assert.Equal(t, types.ScheduleStatusCancelled, schedule.Status)
+		assert.Nil(t, schedule.NextRunAt)
+	})
+
+	t.Run("already completed", func(t *testing.T) {
+		s, repo := newTestService(t)
+		repo.EXPECT().GetSchedule(gomock.Any(), testScheduleID).
+			Return(&types.Schedule{ID: testScheduleID, WalletUUID: testWalletUUID, Status: types.ScheduleStatusCompleted}, nil)
+
+		_, err := s.CancelSchedule(ctx, testScheduleID)
+		require.Error(t, err)
+		assert.Equal(t, 409, err.(types.HTTPError).Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		_, err := s.CancelSchedule(ctx, "invalid-id")
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+}
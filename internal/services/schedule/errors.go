@@ -0,0 +1,23 @@
+package scheduleservice
+
+import (
+	"errors"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+func translateRepoError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrScheduleNotFound):
+		logger.Info("schedule not found")
+		return types.ErrNotFound(types.ErrScheduleNotFound)
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("wallet not found")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	default:
+		logger.Error("failed to access schedules",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+}
@@ -0,0 +1,227 @@
+package scheduleservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// BalanceUpdater applies an operation to a wallet. It is implemented by
+// walletservice.Service.
+type BalanceUpdater interface {
+	UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error)
+}
+
+// Executor runs the schedules that are due. Several executors may run against
+// the same database, a schedule is claimed by one of them at a time.
+type Executor struct {
+	repo        ReadWriter
+	wallets     BalanceUpdater
+	logger      *zap.Logger
+	now         func() time.Time
+	interval    time.Duration
+	batchSize   int
+	lease       time.Duration
+	maxFailures int
+	retryDelay  time.Duration
+}
+
+type ExecutorOption func(*Executor)
+
+// WithInterval sets how often the executor looks for due schedules.
+func WithInterval(interval time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.interval = interval
+	}
+}
+
+// WithBatchSize sets how many due schedules are claimed at once.
+func WithBatchSize(size int) ExecutorOption {
+	return func(e *Executor) {
+		e.batchSize = size
+	}
+}
+
+// WithMaxFailures sets after how many insufficient-funds failures in a row a
+// schedule is paused.
+func WithMaxFailures(n int) ExecutorOption {
+	return func(e *Executor) {
+		e.maxFailures = n
+	}
+}
+
+// WithRetryDelay sets how long a failed occurrence waits before it is retried.
+func WithRetryDelay(delay time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.retryDelay = delay
+	}
+}
+
+func NewExecutor(repo ReadWriter, wallets BalanceUpdater, logger *zap.Logger, opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		repo:        repo,
+		wallets:     wallets,
+		logger:      logger,
+		now:         time.Now,
+		interval:    10 * time.Second,
+		batchSize:   100,
+		lease:       5 * time.Minute,
+		maxFailures: 3,
+		retryDelay:  time.Hour,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run executes due schedules every interval until ctx is done.
+func (e *Executor) Run(ctx context.Context) {
+	e.logger.Info("Schedule executor started",
+		zap.Duration("interval", e.interval))
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			e.logger.Info("Schedule executor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce executes one batch of due schedules and returns how many were run.
+func (e *Executor) RunOnce(ctx context.Context) int {
+	schedules, err := e.repo.ClaimDueSchedules(ctx, e.batchSize, e.lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Error("Failed to claim due schedules",
+				zap.Error(err))
+		}
+		return 0
+	}
+
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			// Не записываем результат, запуск заберётся снова после истечения аренды
+			break
+		}
+		e.runSchedule(ctx, schedule)
+	}
+
+	return len(schedules)
+}
+
+// runSchedule applies the operation of the due occurrence and records the
+// outcome. Successful runs move the schedule to its next occurrence, while
+// failed ones are retried after retryDelay at the same occurrence, so that the
+// retry reuses the reference ID.
+func (e *Executor) runSchedule(ctx context.Context, schedule *types.Schedule) {
+	occurrence := *schedule.NextRunAt
+	referenceID := OccurrenceReferenceID(schedule.ID, occurrence)
+	logger := e.logger.With(
+		zap.String("schedule_id", schedule.ID),
+		zap.String("wallet_uuid", schedule.WalletUUID),
+		zap.Time("occurrence", occurrence),
+		zap.String("reference_id", referenceID))
+
+	req := types.NewWalletUpdateRequest(schedule.WalletUUID, schedule.Operation, schedule.Amount, referenceID)
+	_, err := e.wallets.UpdateBalance(ctx, req)
+
+	now := e.now().UTC()
+	run := &types.ScheduleRun{
+		ScheduleID:  schedule.ID,
+		Occurrence:  occurrence,
+		ReferenceID: referenceID,
+	}
+	var retryAt *time.Time
+
+	switch {
+	case err == nil, errors.Is(err, types.ErrOperationExists):
+		logger.Info("Scheduled operation applied")
+		run.Status = types.ScheduleRunApplied
+		schedule.Failures = 0
+		schedule.LastError = ""
+		e.advance(logger, schedule, occurrence)
+	case errors.Is(err, types.ErrInsufficientFunds):
+		run.Status = types.ScheduleRunFailed
+		run.Error = err.Error()
+		schedule.Failures++
+		schedule.LastError = run.Error
+		if schedule.Failures >= e.maxFailures {
+			logger.Warn("Scheduled operation failed too many times, pausing schedule",
+				zap.Int("failures", schedule.Failures))
+			schedule.Status = types.ScheduleStatusPaused
+		} else {
+			logger.Info("Scheduled operation failed, will retry",
+				zap.Int("failures", schedule.Failures),
+				zap.Duration("retry_delay", e.retryDelay))
+			at := now.Add(e.retryDelay)
+			retryAt = &at
+		}
+	case isTransient(err):
+		logger.Warn("Scheduled operation failed temporarily, will retry",
+			zap.Error(err))
+		run = nil
+		at := now.Add(e.retryDelay)
+		retryAt = &at
+	default:
+		logger.Error("Scheduled operation rejected, pausing schedule",
+			zap.Error(err))
+		run.Status = types.ScheduleRunFailed
+		run.Error = err.Error()
+		schedule.Failures++
+		schedule.LastError = run.Error
+		schedule.Status = types.ScheduleStatusPaused
+	}
+
+	if run != nil {
+		schedule.LastRunAt = &now
+	}
+
+	if err := e.repo.RecordScheduleRun(ctx, schedule, run, retryAt); err != nil {
+		logger.Error("Failed to record schedule run",
+			zap.Error(err))
+	}
+}
+
+// advance moves a schedule past occurrence: a recurring one to its next
+// occurrence, a one-off one to completed.
+func (e *Executor) advance(logger *zap.Logger, schedule *types.Schedule, occurrence time.Time) {
+	if schedule.Recurrence == "" {
+		schedule.Status = types.ScheduleStatusCompleted
+		schedule.NextRunAt = nil
+		return
+	}
+
+	next, err := nextOccurrence(schedule.Recurrence, occurrence)
+	if err != nil {
+		logger.Error("Invalid recurrence, pausing schedule",
+			zap.String("recurrence", schedule.Recurrence),
+			zap.Error(err))
+		schedule.Status = types.ScheduleStatusPaused
+		schedule.LastError = err.Error()
+		return
+	}
+	schedule.NextRunAt = &next
+}
+
+// isTransient reports whether an operation failed for a reason that is likely
+// to go away by itself, such as a conflict with a concurrent update or an
+// unavailable database.
+func isTransient(err error) bool {
+	var httpErr types.HTTPError
+	if !errors.As(err, &httpErr) {
+		return true
+	}
+	return httpErr.Code == http.StatusConflict || httpErr.Code >= http.StatusInternalServerError
+}
@@ -0,0 +1,170 @@
+package scheduleservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/schedule/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeBalanceUpdater struct {
+	requests []*types.WalletUpdateRequest
+	err      error
+}
+
+func (f *fakeBalanceUpdater) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
+	f.requests = append(f.requests, req)
+	return types.Fee{}, f.err
+}
+
+func newTestExecutor(t *testing.T, updateErr error) (*Executor, *mocks.MockReadWriter, *fakeBalanceUpdater) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockReadWriter(ctrl)
+	wallets := &fakeBalanceUpdater{err: updateErr}
+	e := NewExecutor(repo, wallets, zap.NewNop(), WithMaxFailures(3), WithRetryDelay(time.Hour))
+	e.now = func() time.Time { return testNow }
+	return e, repo, wallets
+}
+
+func dueSchedule(recurrence string, failures int) *types.Schedule {
+	next := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	return &types.Schedule{
+		ID:         testScheduleID,
+		WalletUUID: testWalletUUID,
+		Operation:  "WITHDRAW",
+		Amount:     500,
+		Recurrence: recurrence,
+		Status:     types.ScheduleStatusActive,
+		NextRunAt:  &next,
+		Failures:   failures,
+	}
+}
+
+func TestExecutor_RunOnce(t *testing.T) {
+	occurrence := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	retryAt := testNow.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		schedule       *types.Schedule
+		updateErr      error
+		expectedStatus string
+		expectedNext   *time.Time
+		expectedRun    string
+		expectedRetry  *time.Time
+		failures       int
+	}{
+		{
+			name:           "recurring schedule advances",
+			schedule:       dueSchedule("0 0 1 * *", 1),
+			expectedStatus: types.ScheduleStatusActive,
+			expectedNext:   ptrTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)),
+			expectedRun:    types.ScheduleRunApplied,
+		},
+		{
+			name:           "one-off schedule completes",
+			schedule:       dueSchedule("", 0),
+			expectedStatus: types.ScheduleStatusCompleted,
+			expectedRun:    types.ScheduleRunApplied,
+		},
+		{
+			name:           "already applied occurrence",
+			schedule:       dueSchedule("0 0 1 * *", 0),
+			updateErr:      types.ErrConflict(types.ErrOperationExists),
+			expectedStatus: types.ScheduleStatusActive,
+			expectedNext:   ptrTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)),
+			expectedRun:    types.ScheduleRunApplied,
+		},
+		{
+			name:           "insufficient funds is retried",
+			schedule:       dueSchedule("0 0 1 * *", 0),
+			updateErr:      types.ErrBadRequest(types.ErrInsufficientFunds),
+			expectedStatus: types.ScheduleStatusActive,
+			expectedNext:   &occurrence,
+			expectedRun:    types.ScheduleRunFailed,
+			expectedRetry:  &retryAt,
+			failures:       1,
+		},
+		{
+			name:           "insufficient funds too many times pauses",
+			schedule:       dueSchedule("0 0 1 * *", 2),
+			updateErr:      types.ErrBadRequest(types.ErrInsufficientFunds),
+			expectedStatus: types.ScheduleStatusPaused,
+			expectedNext:   &occurrence,
+			expectedRun:    types.ScheduleRunFailed,
+			failures:       3,
+		},
+		{
+			name:           "transient error is retried without a run",
+			schedule:       dueSchedule("0 0 1 * *", 0),
+			updateErr:      types.ErrServiceUnavailable(errors.New("database unavailable")),
+			expectedStatus: types.ScheduleStatusActive,
+			expectedNext:   &occurrence,
+			expectedRetry:  &retryAt,
+		},
+		{
+			name:           "rejected operation pauses",
+			schedule:       dueSchedule("0 0 1 * *", 0),
+			updateErr:      types.ErrNotFound(types.ErrWalletNotFound),
+			expectedStatus: types.ScheduleStatusPaused,
+			expectedNext:   &occurrence,
+			expectedRun:    types.ScheduleRunFailed,
+			failures:       1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, repo, wallets := newTestExecutor(t, tt.updateErr)
+			repo.EXPECT().ClaimDueSchedules(gomock.Any(), 100, 5*time.Minute).Return([]*types.Schedule{tt.schedule}, nil)
+			repo.EXPECT().RecordScheduleRun(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, s *types.Schedule, run *types.ScheduleRun, at *time.Time) error {
+					assert.Equal(t, tt.expectedStatus, s.Status)
+					assert.Equal(t, tt.expectedNext, s.NextRunAt)
+					assert.Equal(t, tt.failures, s.Failures)
+					assert.Equal(t, tt.expectedRetry, at)
+					if tt.expectedRun == "" {
+						assert.Nil(t, run)
+						return nil
+					}
+					require.NotNil(t, run)
+					assert.Equal(t, tt.expectedRun, run.Status)
+					assert.Equal(t, occurrence, run.Occurrence)
+					assert.Equal(t, OccurrenceReferenceID(testScheduleID, occurrence), run.ReferenceID)
+					return nil
+				})
+
+			assert.Equal(t, 1, e.RunOnce(context.Background()))
+			require.Len(t, wallets.requests, 1)
+			assert.Equal(t, OccurrenceReferenceID(testScheduleID, occurrence), wallets.requests[0].ReferenceID)
+			assert.Equal(t, 500, wallets.requests[0].Amount)
+		})
+	}
+}
+
+func TestExecutor_RunOnce_ClaimError(t *testing.T) {
+	e, repo, wallets := newTestExecutor(t, nil)
+	repo.EXPECT().ClaimDueSchedules(gomock.Any(), 100, 5*time.Minute).Return(nil, errors.New("connection refused"))
+
+	assert.Equal(t, 0, e.RunOnce(context.Background()))
+	assert.Empty(t, wallets.requests)
+}
+
+func TestOccurrenceReferenceID(t *testing.T) {
+	occurrence := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	id := OccurrenceReferenceID(testScheduleID, occurrence)
+	assert.Equal(t, id, OccurrenceReferenceID(testScheduleID, occurrence.In(time.FixedZone("MSK", 3*60*60))))
+	assert.NotEqual(t, id, OccurrenceReferenceID(testScheduleID, occurrence.AddDate(0, 1, 0)))
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
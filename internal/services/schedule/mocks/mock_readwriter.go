@@ -0,0 +1,317 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./repo.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	types "github.com/artyomkorchagin/wallet-task/internal/types"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// GetSchedule mocks base method.
+func (m *MockReader) GetSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, id)
+	ret0, _ := ret[0].(*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockReaderMockRecorder) GetSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockReader)(nil).GetSchedule), ctx, id)
+}
+
+// GetWalletOwner mocks base method.
+func (m *MockReader) GetWalletOwner(ctx context.Context, walletUUID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletOwner", ctx, walletUUID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletOwner indicates an expected call of GetWalletOwner.
+func (mr *MockReaderMockRecorder) GetWalletOwner(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletOwner", reflect.TypeOf((*MockReader)(nil).GetWalletOwner), ctx, walletUUID)
+}
+
+// ListScheduleRuns mocks base method.
+func (m *MockReader) ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]types.ScheduleRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduleRuns", ctx, scheduleID, limit)
+	ret0, _ := ret[0].([]types.ScheduleRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduleRuns indicates an expected call of ListScheduleRuns.
+func (mr *MockReaderMockRecorder) ListScheduleRuns(ctx, scheduleID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduleRuns", reflect.TypeOf((*MockReader)(nil).ListScheduleRuns), ctx, scheduleID, limit)
+}
+
+// ListSchedules mocks base method.
+func (m *MockReader) ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx, walletUUID)
+	ret0, _ := ret[0].([]*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockReaderMockRecorder) ListSchedules(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockReader)(nil).ListSchedules), ctx, walletUUID)
+}
+
+// MockWriter is a mock of Writer interface.
+type MockWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWriterMockRecorder
+}
+
+// MockWriterMockRecorder is the mock recorder for MockWriter.
+type MockWriterMockRecorder struct {
+	mock *MockWriter
+}
+
+// NewMockWriter creates a new mock instance.
+func NewMockWriter(ctrl *gomock.Controller) *MockWriter {
+	mock := &MockWriter{ctrl: ctrl}
+	mock.recorder = &MockWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueSchedules mocks base method.
+func (m *MockWriter) ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueSchedules", ctx, limit, lease)
+	ret0, _ := ret[0].([]*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueSchedules indicates an expected call of ClaimDueSchedules.
+func (mr *MockWriterMockRecorder) ClaimDueSchedules(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueSchedules", reflect.TypeOf((*MockWriter)(nil).ClaimDueSchedules), ctx, limit, lease)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockWriter) CreateSchedule(ctx context.Context, s *types.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockWriterMockRecorder) CreateSchedule(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockWriter)(nil).CreateSchedule), ctx, s)
+}
+
+// RecordScheduleRun mocks base method.
+func (m *MockWriter) RecordScheduleRun(ctx context.Context, s *types.Schedule, run *types.ScheduleRun, retryAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScheduleRun", ctx, s, run, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScheduleRun indicates an expected call of RecordScheduleRun.
+func (mr *MockWriterMockRecorder) RecordScheduleRun(ctx, s, run, retryAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScheduleRun", reflect.TypeOf((*MockWriter)(nil).RecordScheduleRun), ctx, s, run, retryAt)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockWriter) UpdateSchedule(ctx context.Context, id string, changes *types.ScheduleChanges) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, id, changes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockWriterMockRecorder) UpdateSchedule(ctx, id, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockWriter)(nil).UpdateSchedule), ctx, id, changes)
+}
+
+// MockReadWriter is a mock of ReadWriter interface.
+type MockReadWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadWriterMockRecorder
+}
+
+// MockReadWriterMockRecorder is the mock recorder for MockReadWriter.
+type MockReadWriterMockRecorder struct {
+	mock *MockReadWriter
+}
+
+// NewMockReadWriter creates a new mock instance.
+func NewMockReadWriter(ctrl *gomock.Controller) *MockReadWriter {
+	mock := &MockReadWriter{ctrl: ctrl}
+	mock.recorder = &MockReadWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadWriter) EXPECT() *MockReadWriterMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueSchedules mocks base method.
+func (m *MockReadWriter) ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueSchedules", ctx, limit, lease)
+	ret0, _ := ret[0].([]*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueSchedules indicates an expected call of ClaimDueSchedules.
+func (mr *MockReadWriterMockRecorder) ClaimDueSchedules(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueSchedules", reflect.TypeOf((*MockReadWriter)(nil).ClaimDueSchedules), ctx, limit, lease)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockReadWriter) CreateSchedule(ctx context.Context, s *types.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockReadWriterMockRecorder) CreateSchedule(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockReadWriter)(nil).CreateSchedule), ctx, s)
+}
+
+// GetSchedule mocks base method.
+func (m *MockReadWriter) GetSchedule(ctx context.Context, id string) (*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, id)
+	ret0, _ := ret[0].(*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockReadWriterMockRecorder) GetSchedule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockReadWriter)(nil).GetSchedule), ctx, id)
+}
+
+// GetWalletOwner mocks base method.
+func (m *MockReadWriter) GetWalletOwner(ctx context.Context, walletUUID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletOwner", ctx, walletUUID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletOwner indicates an expected call of GetWalletOwner.
+func (mr *MockReadWriterMockRecorder) GetWalletOwner(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletOwner", reflect.TypeOf((*MockReadWriter)(nil).GetWalletOwner), ctx, walletUUID)
+}
+
+// ListScheduleRuns mocks base method.
+func (m *MockReadWriter) ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]types.ScheduleRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduleRuns", ctx, scheduleID, limit)
+	ret0, _ := ret[0].([]types.ScheduleRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduleRuns indicates an expected call of ListScheduleRuns.
+func (mr *MockReadWriterMockRecorder) ListScheduleRuns(ctx, scheduleID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduleRuns", reflect.TypeOf((*MockReadWriter)(nil).ListScheduleRuns), ctx, scheduleID, limit)
+}
+
+// ListSchedules mocks base method.
+func (m *MockReadWriter) ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx, walletUUID)
+	ret0, _ := ret[0].([]*types.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockReadWriterMockRecorder) ListSchedules(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockReadWriter)(nil).ListSchedules), ctx, walletUUID)
+}
+
+// RecordScheduleRun mocks base method.
+func (m *MockReadWriter) RecordScheduleRun(ctx context.Context, s *types.Schedule, run *types.ScheduleRun, retryAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScheduleRun", ctx, s, run, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScheduleRun indicates an expected call of RecordScheduleRun.
+func (mr *MockReadWriterMockRecorder) RecordScheduleRun(ctx, s, run, retryAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScheduleRun", reflect.TypeOf((*MockReadWriter)(nil).RecordScheduleRun), ctx, s, run, retryAt)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockReadWriter) UpdateSchedule(ctx context.Context, id string, changes *types.ScheduleChanges) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, id, changes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockReadWriterMockRecorder) UpdateSchedule(ctx, id, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockReadWriter)(nil).UpdateSchedule), ctx, id, changes)
+}
@@ -0,0 +1,47 @@
+package scheduleservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// checkWallet fails with 404 if the request is made for a user that does not
+// own the wallet. Requests made without an owner are not restricted.
+func (s *Service) checkWallet(ctx context.Context, logger *zap.Logger, walletUUID string) error {
+	ownerID, ok := types.OwnerFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	walletOwner, err := s.repo.GetWalletOwner(ctx, walletUUID)
+	if err != nil {
+		return translateRepoError(logger, err)
+	}
+	if walletOwner != ownerID {
+		logger.Info("wallet is owned by another user")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	}
+	return nil
+}
+
+// loadSchedule returns the schedule if the caller may see it. Schedules of
+// other users' wallets look nonexistent.
+func (s *Service) loadSchedule(ctx context.Context, logger *zap.Logger, id string) (*types.Schedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	if err := s.checkWallet(ctx, logger, schedule.WalletUUID); err != nil {
+		var httpErr types.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == 404 {
+			return nil, types.ErrNotFound(types.ErrScheduleNotFound)
+		}
+		return nil, err
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,47 @@
+package scheduleservice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// parseRecurrence parses a standard five-field cron expression or one of the
+// @monthly-style descriptors. Times are evaluated in UTC unless the expression
+// starts with CRON_TZ=.
+func parseRecurrence(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("recurrence is not a valid cron expression: %w", err)
+	}
+	return schedule, nil
+}
+
+// nextOccurrence returns the first occurrence of a recurring schedule after
+// the given time.
+func nextOccurrence(recurrence string, after time.Time) (time.Time, error) {
+	schedule, err := parseRecurrence(recurrence)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(after.UTC()), nil
+}
+
+// OccurrenceReferenceID is the reference ID of the operation applied for one
+// occurrence of a schedule. It is the same every time the occurrence is run,
+// so a retried run cannot apply the operation twice.
+func OccurrenceReferenceID(scheduleID string, occurrence time.Time) string {
+	namespace, err := uuid.Parse(scheduleID)
+	if err != nil {
+		namespace = uuid.NameSpaceOID
+	}
+	return uuid.NewSHA1(namespace, []byte(occurrence.UTC().Format(time.RFC3339Nano))).String()
+}
+
+// isFinished reports whether a schedule can no longer be changed.
+func isFinished(s *types.Schedule) bool {
+	return s.Status == types.ScheduleStatusCompleted || s.Status == types.ScheduleStatusCancelled
+}
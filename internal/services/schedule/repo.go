@@ -0,0 +1,27 @@
+package scheduleservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+)
+
+type Reader interface {
+	GetSchedule(ctx context.Context, id string) (*types.Schedule, error)
+	ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error)
+	ListScheduleRuns(ctx context.Context, scheduleID string, limit int) ([]types.ScheduleRun, error)
+	GetWalletOwner(ctx context.Context, walletUUID string) (string, error)
+}
+
+type Writer interface {
+	CreateSchedule(ctx context.Context, s *types.Schedule) error
+	UpdateSchedule(ctx context.Context, id string, changes *types.ScheduleChanges) error
+	ClaimDueSchedules(ctx context.Context, limit int, lease time.Duration) ([]*types.Schedule, error)
+	RecordScheduleRun(ctx context.Context, s *types.Schedule, run *types.ScheduleRun, retryAt *time.Time) error
+}
+
+type ReadWriter interface {
+	Reader
+	Writer
+}
@@ -0,0 +1,32 @@
+package scheduleservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+type ServiceInterface interface {
+	CreateSchedule(ctx context.Context, req *types.ScheduleRequest) (*types.Schedule, error)
+	GetSchedule(ctx context.Context, id string) (*types.Schedule, error)
+	ListSchedules(ctx context.Context, walletUUID string) ([]*types.Schedule, error)
+	UpdateSchedule(ctx context.Context, id string, update *types.ScheduleUpdate) (*types.Schedule, error)
+	CancelSchedule(ctx context.Context, id string) (*types.Schedule, error)
+	ListScheduleRuns(ctx context.Context, id string) ([]types.ScheduleRun, error)
+}
+
+type Service struct {
+	repo   ReadWriter
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewService(repo ReadWriter, logger *zap.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,192 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CreateWallet opens an empty wallet. The owner may be left empty for wallets
+// that are not bound to a user.
+func (s *Service) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("owner", owner),
+		zap.Int("credit_limit", creditLimit))
+	logger.Info("CreateWallet called")
+
+	if creditLimit < 0 {
+		logger.Warn("CreateWallet: negative credit limit")
+		return nil, types.ErrBadRequest(fmt.Errorf("credit limit must not be negative"))
+	}
+
+	wallet, err := s.repo.CreateWallet(ctx, owner, creditLimit)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	logger.Info("CreateWallet: success",
+		zap.String("wallet_uuid", wallet.WalletUUID))
+
+	return wallet, nil
+}
+
+// GetWallet returns the full state of a wallet.
+func (s *Service) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	logger := s.loggerFrom(ctx).With(zap.String("wallet_uuid", walletUUID))
+	logger.Info("GetWallet called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("GetWallet: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	wallet, err := s.repo.GetWallet(ctx, walletUUID)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	return wallet, nil
+}
+
+// SetWalletFrozen freezes or unfreezes a wallet and returns it afterwards.
+// Operations on a frozen wallet are rejected; reads keep working.
+func (s *Service) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) (*types.Wallet, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.Bool("frozen", frozen))
+	logger.Info("SetWalletFrozen called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("SetWalletFrozen: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if err := s.repo.SetWalletFrozen(ctx, walletUUID, frozen); err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	wallet, err := s.repo.GetWallet(ctx, walletUUID)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	logger.Info("SetWalletFrozen: success")
+
+	return wallet, nil
+}
+
+// GetOperation looks up a single operation by its reference ID. Operations on
+// wallets of other users are reported as not found.
+func (s *Service) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	logger := s.loggerFrom(ctx).With(zap.String("reference_id", referenceID))
+	logger.Info("GetOperation called")
+
+	if _, err := uuid.Parse(referenceID); err != nil {
+		logger.Warn("GetOperation: invalid reference ID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("referenceId is not valid: %w", err))
+	}
+
+	op, err := s.repo.GetOperation(ctx, referenceID)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	hidden, err := s.hiddenWallets(ctx, logger, []string{op.WalletUUID})
+	if err != nil {
+		return nil, err
+	}
+	if len(hidden) > 0 {
+		return nil, types.ErrNotFound(types.ErrOperationNotFound)
+	}
+
+	return op, nil
+}
+
+// ReconcileBalances reports the wallets whose balance disagrees with the
+// balance recorded by their latest applied operation.
+func (s *Service) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	logger := s.loggerFrom(ctx)
+	logger.Info("ReconcileBalances called")
+
+	mismatches, err := s.repo.ReconcileBalances(ctx)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	if len(mismatches) > 0 {
+		logger.Warn("ReconcileBalances: balances out of sync",
+			zap.Int("wallets", len(mismatches)))
+	}
+
+	return mismatches, nil
+}
+
+// SweepPendingOperations fails the operations that have been pending for
+// longer than olderThan, so that their reference IDs can be investigated and
+// their wallets are not left with dangling journal entries.
+func (s *Service) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	logger := s.loggerFrom(ctx).With(zap.Duration("older_than", olderThan))
+	logger.Info("SweepPendingOperations called")
+
+	if olderThan <= 0 {
+		logger.Warn("SweepPendingOperations: non-positive age")
+		return 0, types.ErrBadRequest(fmt.Errorf("age must be positive"))
+	}
+
+	n, err := s.repo.SweepPendingOperations(ctx, olderThan)
+	if err != nil {
+		return 0, translateAdminError(logger, err)
+	}
+
+	logger.Info("SweepPendingOperations: success",
+		zap.Int64("operations", n))
+
+	return n, nil
+}
+
+// FailureReport counts the operations failed since the given moment by
+// failure code. It covers async operations and the ones swept as abandoned:
+// a synchronous operation that fails is rolled back and only reported to its
+// caller.
+func (s *Service) FailureReport(ctx context.Context, since time.Time) (*types.FailureReport, error) {
+	logger := s.loggerFrom(ctx).With(zap.Time("since", since))
+	logger.Info("FailureReport called")
+
+	counts, err := s.repo.CountFailedOperations(ctx, since)
+	if err != nil {
+		return nil, translateAdminError(logger, err)
+	}
+
+	report := &types.FailureReport{Since: since, Counts: counts}
+	for _, c := range counts {
+		report.Total += c.Count
+	}
+	return report, nil
+}
+
+func translateAdminError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("wallet not found")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	case errors.Is(err, types.ErrOperationNotFound):
+		logger.Info("operation not found")
+		return types.ErrNotFound(types.ErrOperationNotFound)
+	case errors.Is(err, types.ErrQueryCanceled):
+		logger.Warn("query canceled",
+			zap.Error(err))
+		return types.ErrServiceUnavailable(err)
+	default:
+		logger.Error("admin operation failed",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+}
@@ -0,0 +1,164 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWallet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("negative credit limit", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.CreateWallet(ctx, "", -1)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		created := &types.Wallet{WalletUUID: uuid.New().String(), Owner: "user-1", Funds: types.NewFunds(0, 100)}
+		repo.On("CreateWallet", ctx, "user-1", 100).Return(created, nil)
+
+		wallet, err := service.CreateWallet(ctx, "user-1", 100)
+		require.NoError(t, err)
+		assert.Equal(t, created, wallet)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestGetWallet(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	t.Run("invalid UUID", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.GetWallet(ctx, "invalid-uuid")
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetWallet", ctx, walletUUID).Return(nil, types.ErrWalletNotFound)
+
+		_, err := service.GetWallet(ctx, walletUUID)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+}
+
+func TestSetWalletFrozen(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	t.Run("not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SetWalletFrozen", ctx, walletUUID, true).Return(types.ErrWalletNotFound)
+
+		_, err := service.SetWalletFrozen(ctx, walletUUID, true)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("success returns the wallet", func(t *testing.T) {
+		service, repo := setupService(t)
+		frozen := &types.Wallet{WalletUUID: walletUUID, Frozen: true}
+		repo.On("SetWalletFrozen", ctx, walletUUID, true).Return(nil)
+		repo.On("GetWallet", ctx, walletUUID).Return(frozen, nil)
+
+		wallet, err := service.SetWalletFrozen(ctx, walletUUID, true)
+		require.NoError(t, err)
+		assert.True(t, wallet.Frozen)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestGetOperation(t *testing.T) {
+	ctx := context.Background()
+	referenceID := uuid.New().String()
+
+	t.Run("invalid reference ID", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.GetOperation(ctx, "ref")
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetOperation", ctx, referenceID).Return(nil, types.ErrOperationNotFound)
+
+		_, err := service.GetOperation(ctx, referenceID)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		assert.ErrorIs(t, err, types.ErrOperationNotFound)
+	})
+}
+
+func TestFailureReport(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("CountFailedOperations", ctx, since).Return([]types.FailureCount{
+			{Code: types.FailureInsufficientFunds, Count: 5},
+			{Code: types.FailureWalletFrozen, Count: 2},
+		}, nil)
+
+		report, err := service.FailureReport(ctx, since)
+		require.NoError(t, err)
+		assert.Equal(t, since, report.Since)
+		assert.Equal(t, int64(7), report.Total)
+		assert.Len(t, report.Counts, 2)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("CountFailedOperations", ctx, since).Return(nil, errors.New("db error"))
+
+		_, err := service.FailureReport(ctx, since)
+		require.Error(t, err)
+		assert.Equal(t, 500, err.(types.HTTPError).Code)
+	})
+}
+
+func TestSweepPendingOperations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("non-positive age", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.SweepPendingOperations(ctx, 0)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SweepPendingOperations", ctx, 10*time.Minute).Return(int64(3), nil)
+
+		n, err := service.SweepPendingOperations(ctx, 10*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+}
+
+func TestUpdateBalance_FrozenWallet(t *testing.T) {
+	ctx := context.Background()
+	service, repo := setupService(t)
+	req := types.NewWalletUpdateRequest(uuid.New().String(), types.OperationTypeDeposit, 100, uuid.New().String())
+	repo.On("CheckOperationExists", ctx, req.ReferenceID).Return(false, nil)
+	repo.On("UpdateBalance", ctx, req).Return(types.Fee{}, types.ErrWalletFrozen)
+
+	_, err := service.UpdateBalance(ctx, req)
+	require.Error(t, err)
+	assert.Equal(t, 403, err.(types.HTTPError).Code)
+}
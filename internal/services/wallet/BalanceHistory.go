@@ -0,0 +1,154 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetBalanceAt returns the balance the wallet had at the given time.
+func (s *Service) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (*types.HistoricalBalance, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.Time("at", at))
+	logger.Info("GetBalanceAt called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("GetBalanceAt: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if at.After(time.Now()) {
+		logger.Warn("GetBalanceAt: time is in the future")
+		return nil, types.ErrBadRequest(fmt.Errorf("at must not be in the future"))
+	}
+
+	if err := s.checkOwnership(ctx, logger, walletUUID); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.repo.GetBalanceAt(ctx, walletUUID, at)
+	if err != nil {
+		return nil, translateHistoryError(logger, err)
+	}
+
+	logger.Info("GetBalanceAt: success",
+		zap.Int("balance", balance))
+
+	return &types.HistoricalBalance{
+		WalletUUID: walletUUID,
+		At:         at,
+		Balance:    balance,
+	}, nil
+}
+
+// ListOperations returns a page of the wallet's operations that match the
+// filter, the newest first. A zero limit means types.DefaultOperationsPage.
+func (s *Service) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) (*types.OperationsPage, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.String("external_ref", filter.ExternalRef),
+		zap.Int("metadata_keys", len(filter.Metadata)),
+		zap.Int64("before_id", filter.BeforeID))
+	logger.Info("ListOperations called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("ListOperations: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if err := validateOperationFilter(&filter); err != nil {
+		logger.Warn("ListOperations: invalid filter",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(err)
+	}
+
+	if err := s.checkOwnership(ctx, logger, walletUUID); err != nil {
+		return nil, err
+	}
+
+	// Лишняя операция показывает, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+	ops, err := s.repo.ListOperations(ctx, walletUUID, filter)
+	if err != nil {
+		return nil, translateHistoryError(logger, err)
+	}
+
+	page := &types.OperationsPage{Operations: ops}
+	if len(ops) > limit {
+		page.Operations = ops[:limit]
+		page.NextBeforeID = page.Operations[limit-1].ID
+	}
+
+	logger.Info("ListOperations: success",
+		zap.Int("operations", len(page.Operations)))
+
+	return page, nil
+}
+
+// validateOperationFilter checks the filter and fills in the default limit.
+func validateOperationFilter(filter *types.OperationFilter) error {
+	if filter.Limit == 0 {
+		filter.Limit = types.DefaultOperationsPage
+	}
+	if filter.Limit < 0 || filter.Limit > types.MaxOperationsPage {
+		return fmt.Errorf("limit must be between 1 and %d", types.MaxOperationsPage)
+	}
+	if filter.BeforeID < 0 {
+		return fmt.Errorf("before must be positive")
+	}
+	if utf8.RuneCountInString(filter.ExternalRef) > types.MaxExternalRefLength {
+		return fmt.Errorf("externalRef must be at most %d characters", types.MaxExternalRefLength)
+	}
+	if len(filter.Metadata) > types.MaxMetadataKeys {
+		return fmt.Errorf("metadata filter must have at most %d keys", types.MaxMetadataKeys)
+	}
+	for key := range filter.Metadata {
+		if err := types.ValidateMetadataKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SnapshotBalances stores the end-of-day balances of the given UTC day and
+// returns how many wallets were snapshotted.
+func (s *Service) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	logger := s.loggerFrom(ctx).With(zap.String("day", day.Format(time.DateOnly)))
+	logger.Info("SnapshotBalances called")
+
+	n, err := s.repo.SnapshotBalances(ctx, day)
+	if err != nil {
+		return 0, translateHistoryError(logger, err)
+	}
+
+	logger.Info("SnapshotBalances: success",
+		zap.Int64("wallets", n))
+
+	return n, nil
+}
+
+func translateHistoryError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("wallet not found")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	case errors.Is(err, types.ErrQueryCanceled):
+		logger.Warn("query canceled",
+			zap.Error(err))
+		return types.ErrServiceUnavailable(err)
+	default:
+		logger.Error("failed to access balance history",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+}
@@ -0,0 +1,158 @@
+package walletservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBalanceAt(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+	at := time.Date(2026, 9, 30, 23, 59, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetBalanceAt", ctx, walletUUID, at).Return(750, nil)
+
+		balance, err := service.GetBalanceAt(ctx, walletUUID, at)
+		require.NoError(t, err)
+		assert.Equal(t, &types.HistoricalBalance{WalletUUID: walletUUID, At: at, Balance: 750}, balance)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid UUID", func(t *testing.T) {
+		service, _ := setupService(t)
+
+		_, err := service.GetBalanceAt(ctx, "invalid-uuid", at)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("time in the future", func(t *testing.T) {
+		service, _ := setupService(t)
+
+		_, err := service.GetBalanceAt(ctx, walletUUID, time.Now().Add(time.Hour))
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetBalanceAt", ctx, walletUUID, at).Return(0, types.ErrWalletNotFound)
+
+		_, err := service.GetBalanceAt(ctx, walletUUID, at)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("foreign wallet", func(t *testing.T) {
+		service, repo := setupService(t)
+		ownerCtx := types.ContextWithOwner(ctx, "user-2")
+		repo.On("GetWalletOwners", ownerCtx, []string{walletUUID}).
+			Return(map[string]string{walletUUID: "user-1"}, nil)
+
+		_, err := service.GetBalanceAt(ownerCtx, walletUUID, at)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		repo.AssertNotCalled(t, "GetBalanceAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestListOperations(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+	ops := []*types.Operation{{ID: 9}, {ID: 8}, {ID: 7}}
+
+	t.Run("last page", func(t *testing.T) {
+		service, repo := setupService(t)
+		filter := types.OperationFilter{ExternalRef: "INV-1", Metadata: map[string]string{"order": "42"}}
+		repo.On("ListOperations", ctx, walletUUID, types.OperationFilter{
+			ExternalRef: "INV-1",
+			Metadata:    map[string]string{"order": "42"},
+			Limit:       types.DefaultOperationsPage + 1,
+		}).Return(ops, nil)
+
+		page, err := service.ListOperations(ctx, walletUUID, filter)
+		require.NoError(t, err)
+		assert.Equal(t, &types.OperationsPage{Operations: ops}, page)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("more pages", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("ListOperations", ctx, walletUUID, types.OperationFilter{Limit: 3, BeforeID: 10}).
+			Return(append(ops, &types.Operation{ID: 6}), nil)
+
+		page, err := service.ListOperations(ctx, walletUUID, types.OperationFilter{Limit: 2, BeforeID: 10})
+		require.NoError(t, err)
+		assert.Equal(t, ops[:2], page.Operations)
+		assert.Equal(t, int64(8), page.NextBeforeID)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		for name, filter := range map[string]types.OperationFilter{
+			"limit too large": {Limit: types.MaxOperationsPage + 1},
+			"negative before": {BeforeID: -1},
+			"invalid key":     {Metadata: map[string]string{"bad key": ""}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				service, _ := setupService(t)
+
+				_, err := service.ListOperations(ctx, walletUUID, filter)
+				require.Error(t, err)
+				assert.Equal(t, 400, err.(types.HTTPError).Code)
+			})
+		}
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("ListOperations", ctx, walletUUID, mock.Anything).Return(nil, types.ErrWalletNotFound)
+
+		_, err := service.ListOperations(ctx, walletUUID, types.OperationFilter{})
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("foreign wallet", func(t *testing.T) {
+		service, repo := setupService(t)
+		ownerCtx := types.ContextWithOwner(ctx, "user-2")
+		repo.On("GetWalletOwners", ownerCtx, []string{walletUUID}).
+			Return(map[string]string{walletUUID: "user-1"}, nil)
+
+		_, err := service.ListOperations(ownerCtx, walletUUID, types.OperationFilter{})
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		repo.AssertNotCalled(t, "ListOperations", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SnapshotBalances", ctx, day).Return(int64(3), nil)
+
+		n, err := service.SnapshotBalances(ctx, day)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SnapshotBalances", ctx, day).Return(int64(0), assert.AnError)
+
+		_, err := service.SnapshotBalances(ctx, day)
+		require.Error(t, err)
+		assert.Equal(t, 500, err.(types.HTTPError).Code)
+	})
+}
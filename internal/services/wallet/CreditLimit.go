@@ -0,0 +1,91 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SetCreditLimit changes how far below zero the balance of a wallet may go and
+// returns its funds afterwards. The limit cannot be lowered below the current
+// overdraft.
+func (s *Service) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) (types.Funds, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.Int("credit_limit", creditLimit))
+	logger.Info("SetCreditLimit called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("SetCreditLimit: invalid UUID",
+			zap.Error(err))
+		return types.Funds{}, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if creditLimit < 0 {
+		logger.Warn("SetCreditLimit: negative credit limit")
+		return types.Funds{}, types.ErrBadRequest(fmt.Errorf("credit limit must not be negative"))
+	}
+
+	if err := s.repo.SetCreditLimit(ctx, walletUUID, creditLimit); err != nil {
+		return types.Funds{}, translateCreditLimitError(logger, err)
+	}
+
+	// Закешированный доступный остаток больше не актуален
+	cacheKey := balanceCacheKey(walletUUID)
+	if _, err := s.cachePolicy(logger).Do(ctx, func(ctx context.Context) error {
+		return s.redis.Del(ctx, cacheKey).Err()
+	}); err != nil {
+		logger.Warn("SetCreditLimit: failed to drop cached balance",
+			zap.String("cache_key", cacheKey),
+			zap.Error(err))
+	}
+
+	funds, err := s.repo.GetBalance(ctx, walletUUID)
+	if err != nil {
+		return types.Funds{}, translateCreditLimitError(logger, err)
+	}
+
+	logger.Info("SetCreditLimit: success",
+		zap.Int("balance", funds.Balance))
+
+	return funds, nil
+}
+
+// GetOverdraftWallets reports the wallets whose balance is below zero.
+func (s *Service) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	logger := s.loggerFrom(ctx)
+	logger.Info("GetOverdraftWallets called")
+
+	wallets, err := s.repo.GetOverdraftWallets(ctx)
+	if err != nil {
+		return nil, translateCreditLimitError(logger, err)
+	}
+
+	logger.Info("GetOverdraftWallets: success",
+		zap.Int("wallets", len(wallets)))
+
+	return wallets, nil
+}
+
+func translateCreditLimitError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("wallet not found")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	case errors.Is(err, types.ErrInsufficientFunds):
+		logger.Info("balance is below the new credit limit")
+		return types.ErrConflict(fmt.Errorf("balance is below the new credit limit"))
+	case errors.Is(err, types.ErrQueryCanceled):
+		logger.Warn("query canceled",
+			zap.Error(err))
+		return types.ErrServiceUnavailable(err)
+	default:
+		logger.Error("failed to access credit limit",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+}
@@ -0,0 +1,94 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSetCreditLimit(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	t.Run("invalid UUID", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.SetCreditLimit(ctx, "invalid-uuid", 100)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.SetCreditLimit(ctx, walletUUID, -1)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SetCreditLimit", ctx, walletUUID, 100).Return(types.ErrWalletNotFound)
+
+		_, err := service.SetCreditLimit(ctx, walletUUID, 100)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("balance below new limit", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("SetCreditLimit", ctx, walletUUID, 0).Return(types.ErrInsufficientFunds)
+
+		_, err := service.SetCreditLimit(ctx, walletUUID, 0)
+		require.Error(t, err)
+		assert.Equal(t, 409, err.(types.HTTPError).Code)
+	})
+
+	t.Run("success drops cached balance", func(t *testing.T) {
+		redisMockClient, redisMock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		service := walletservice.NewService(repo, redisMockClient, zaptest.NewLogger(t))
+
+		repo.On("SetCreditLimit", ctx, walletUUID, 1000).Return(nil)
+		redisMock.ExpectDel("wallet:balance:" + walletUUID).SetVal(1)
+		repo.On("GetBalance", ctx, walletUUID).Return(types.NewFunds(-200, 1000), nil)
+
+		funds, err := service.SetCreditLimit(ctx, walletUUID, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, types.Funds{Balance: -200, CreditLimit: 1000, Available: 800}, funds)
+
+		repo.AssertExpectations(t)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOverdraftWallets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		expected := []types.OverdraftWallet{
+			{WalletUUID: uuid.New().String(), Funds: types.NewFunds(-100, 500)},
+		}
+		repo.On("GetOverdraftWallets", ctx).Return(expected, nil)
+
+		wallets, err := service.GetOverdraftWallets(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, wallets)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetOverdraftWallets", ctx).Return(nil, errors.New("db error"))
+
+		_, err := service.GetOverdraftWallets(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 500, err.(types.HTTPError).Code)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
@@ -11,9 +13,36 @@ import (
 	"go.uber.org/zap"
 )
 
-func (s *Service) GetBalance(ctx context.Context, walletUUID string) (int, error) {
+const balanceCacheTTL = 15 * time.Second
+
+func balanceCacheKey(walletUUID string) string {
+	return "wallet:balance:" + walletUUID
+}
+
+// encodeFunds packs the balance and the credit limit into one cache value.
+func encodeFunds(funds types.Funds) string {
+	return strconv.Itoa(funds.Balance) + ":" + strconv.Itoa(funds.CreditLimit)
+}
+
+func decodeFunds(value string) (types.Funds, error) {
+	balanceStr, creditLimitStr, ok := strings.Cut(value, ":")
+	if !ok {
+		return types.Funds{}, fmt.Errorf("malformed cached funds %q", value)
+	}
+	balance, err := strconv.Atoi(balanceStr)
+	if err != nil {
+		return types.Funds{}, fmt.Errorf("malformed cached balance: %w", err)
+	}
+	creditLimit, err := strconv.Atoi(creditLimitStr)
+	if err != nil {
+		return types.Funds{}, fmt.Errorf("malformed cached credit limit: %w", err)
+	}
+	return types.NewFunds(balance, creditLimit), nil
+}
+
+func (s *Service) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
 	start := time.Now()
-	logger := s.logger.With(zap.String("wallet_uuid", walletUUID))
+	logger := s.loggerFrom(ctx).With(zap.String("wallet_uuid", walletUUID))
 	defer func() {
 		duration := time.Since(start)
 		if duration > 100*time.Millisecond {
@@ -28,51 +57,71 @@ func (s *Service) GetBalance(ctx context.Context, walletUUID string) (int, error
 
 	if walletUUID == "" {
 		logger.Warn("GetBalance: walletUUID is empty")
-		return 0, types.ErrBadRequest(fmt.Errorf("walletUUID is empty"))
+		return types.Funds{}, types.ErrBadRequest(fmt.Errorf("walletUUID is empty"))
 	}
 
 	_, err := uuid.Parse(walletUUID)
 	if err != nil {
 		logger.Warn("GetBalance: invalid UUID",
 			zap.Error(err))
-		return 0, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+		return types.Funds{}, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if err := s.checkOwnership(ctx, logger, walletUUID); err != nil {
+		return types.Funds{}, err
 	}
 
-	cacheKey := "wallet:balance:" + walletUUID
+	cacheKey := balanceCacheKey(walletUUID)
 
-	if balance, err := s.redis.Get(ctx, cacheKey).Int(); err == nil {
-		logger.Debug("GetBalance: cache hit",
-			zap.Int("balance", balance))
-		return balance, nil
+	var cached string
+	if _, err := s.cachePolicy(logger).Do(ctx, func(ctx context.Context) error {
+		var getErr error
+		cached, getErr = s.redis.Get(ctx, cacheKey).Result()
+		return getErr
+	}); err == nil {
+		funds, decodeErr := decodeFunds(cached)
+		if decodeErr == nil {
+			logger.Debug("GetBalance: cache hit",
+				zap.Int("balance", funds.Balance))
+			return funds, nil
+		}
+		logger.Warn("GetBalance: invalid cached balance",
+			zap.String("cache_key", cacheKey),
+			zap.Error(decodeErr))
 	}
 
 	logger.Debug("GetBalance: cache miss",
 		zap.String("cache_key", cacheKey))
 
-	balance, err := s.repo.GetBalance(ctx, walletUUID)
+	funds, err := s.repo.GetBalance(ctx, walletUUID)
 	if err != nil {
 		logger.Error("GetBalance: failed to load from repository",
 			zap.Error(err))
 
 		if errors.Is(err, types.ErrWalletNotFound) {
-			return 0, types.ErrNotFound(err)
+			return types.Funds{}, types.ErrNotFound(err)
+		}
+		if errors.Is(err, types.ErrQueryCanceled) {
+			return types.Funds{}, types.ErrServiceUnavailable(err)
 		}
-		return 0, types.ErrInternalServerError(err)
+		return types.Funds{}, types.ErrInternalServerError(err)
 	}
 
-	if setErr := s.redis.Set(ctx, cacheKey, balance, 15*time.Second).Err(); setErr != nil {
+	if _, setErr := s.cachePolicy(logger).Do(ctx, func(ctx context.Context) error {
+		return s.redis.Set(ctx, cacheKey, encodeFunds(funds), balanceCacheTTL).Err()
+	}); setErr != nil {
 		logger.Warn("GetBalance: failed to set cache",
 			zap.String("cache_key", cacheKey),
 			zap.Error(setErr))
 		// Не возвращаем ошибку, кеш опционален
 	} else {
 		logger.Debug("GetBalance: balance cached",
-			zap.Int("balance", balance),
-			zap.Duration("ttl", 15*time.Second))
+			zap.Int("balance", funds.Balance),
+			zap.Duration("ttl", balanceCacheTTL))
 	}
 
 	logger.Info("GetBalance: success",
-		zap.Int("balance", balance))
+		zap.Int("balance", funds.Balance))
 
-	return balance, nil
+	return funds, nil
 }
@@ -35,7 +35,7 @@ func TestGetBalance_Validation(t *testing.T) {
 		require.Error(t, err)
 		assert.Equal(t, 400, err.(types.HTTPError).Code)
 		assert.Contains(t, err.Error(), "walletUUID is empty")
-		assert.Equal(t, 0, balance)
+		assert.Equal(t, types.Funds{}, balance)
 	})
 
 	t.Run("invalid UUID", func(t *testing.T) {
@@ -43,7 +43,7 @@ func TestGetBalance_Validation(t *testing.T) {
 		require.Error(t, err)
 		assert.Equal(t, 400, err.(types.HTTPError).Code)
 		assert.Contains(t, err.Error(), "not valid")
-		assert.Equal(t, 0, balance)
+		assert.Equal(t, types.Funds{}, balance)
 	})
 }
 
@@ -57,11 +57,11 @@ func TestGetBalance_CacheHit(t *testing.T) {
 	walletUUID := uuid.New().String()
 	cacheKey := "wallet:balance:" + walletUUID
 
-	redisMock.ExpectGet(cacheKey).SetVal("500")
+	redisMock.ExpectGet(cacheKey).SetVal("500:100")
 
 	balance, err := service.GetBalance(ctx, walletUUID)
 	require.NoError(t, err)
-	assert.Equal(t, 500, balance)
+	assert.Equal(t, types.NewFunds(500, 100), balance)
 
 	repo.AssertNotCalled(t, "GetBalance", mock.Anything, walletUUID)
 	redisMock.ExpectationsWereMet()
@@ -79,13 +79,13 @@ func TestGetBalance_CacheMiss(t *testing.T) {
 
 	redisMock.ExpectGet(cacheKey).RedisNil()
 
-	repo.On("GetBalance", ctx, walletUUID).Return(300, nil)
+	repo.On("GetBalance", ctx, walletUUID).Return(types.NewFunds(300, 0), nil)
 
-	redisMock.ExpectSet(cacheKey, "300", 15*time.Second).SetVal("OK")
+	redisMock.ExpectSet(cacheKey, "300:0", 15*time.Second).SetVal("OK")
 
 	balance, err := service.GetBalance(ctx, walletUUID)
 	require.NoError(t, err)
-	assert.Equal(t, 300, balance)
+	assert.Equal(t, types.NewFunds(300, 0), balance)
 
 	repo.AssertExpectations(t)
 	redisMock.ExpectationsWereMet()
@@ -102,13 +102,13 @@ func TestGetBalance_RepositoryNotFound(t *testing.T) {
 	cacheKey := "wallet:balance:" + walletUUID
 
 	redisMock.ExpectGet(cacheKey).RedisNil()
-	repo.On("GetBalance", ctx, walletUUID).Return(0, types.ErrWalletNotFound)
+	repo.On("GetBalance", ctx, walletUUID).Return(types.Funds{}, types.ErrWalletNotFound)
 
 	balance, err := service.GetBalance(ctx, walletUUID)
 	require.Error(t, err)
 	assert.Equal(t, 404, err.(types.HTTPError).Code)
 	assert.Contains(t, err.Error(), "wallet not found")
-	assert.Equal(t, 0, balance)
+	assert.Equal(t, types.Funds{}, balance)
 
 	repo.AssertExpectations(t)
 	redisMock.ExpectationsWereMet()
@@ -127,12 +127,12 @@ func TestGetBalance_RepositoryError(t *testing.T) {
 	redisMock.ExpectGet(cacheKey).RedisNil()
 
 	dbErr := errors.New("pq: server unavailable")
-	repo.On("GetBalance", ctx, walletUUID).Return(0, dbErr)
+	repo.On("GetBalance", ctx, walletUUID).Return(types.Funds{}, dbErr)
 
 	balance, err := service.GetBalance(ctx, walletUUID)
 
 	require.Error(t, err)
-	assert.Equal(t, 0, balance)
+	assert.Equal(t, types.Funds{}, balance)
 
 	httpErr, ok := err.(types.HTTPError)
 	require.True(t, ok, "error should be types.HTTPError")
@@ -152,14 +152,37 @@ func TestGetBalance_CacheSetFails(t *testing.T) {
 	cacheKey := "wallet:balance:" + walletUUID
 
 	redisMock.ExpectGet(cacheKey).RedisNil()
-	repo.On("GetBalance", ctx, walletUUID).Return(200, nil)
+	repo.On("GetBalance", ctx, walletUUID).Return(types.NewFunds(-50, 250), nil)
 
-	redisMock.ExpectSet(cacheKey, "200", 15*time.Second).SetErr(errors.New("redis down"))
+	redisMock.ExpectSet(cacheKey, "-50:250", 15*time.Second).SetErr(errors.New("redis down"))
 
 	balance, err := service.GetBalance(ctx, walletUUID)
 	require.NoError(t, err)
-	assert.Equal(t, 200, balance)
+	assert.Equal(t, types.NewFunds(-50, 250), balance)
 
 	repo.AssertExpectations(t)
 	redisMock.ExpectationsWereMet()
 }
+
+func TestGetBalance_MalformedCacheValue(t *testing.T) {
+	redisMockClient, redisMock := redismock.NewClientMock()
+	logger := zaptest.NewLogger(t)
+	repo := new(MockRepository)
+	service := walletservice.NewService(repo, redisMockClient, logger)
+
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+	cacheKey := "wallet:balance:" + walletUUID
+
+	// Значение в старом формате, без кредитного лимита
+	redisMock.ExpectGet(cacheKey).SetVal("500")
+	repo.On("GetBalance", ctx, walletUUID).Return(types.NewFunds(500, 0), nil)
+	redisMock.ExpectSet(cacheKey, "500:0", 15*time.Second).SetVal("OK")
+
+	balance, err := service.GetBalance(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewFunds(500, 0), balance)
+
+	repo.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
@@ -0,0 +1,195 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// GetBalances looks up several wallets at once. Cached balances are read with a
+// single MGET, the rest with a single query, which is then written back to the
+// cache. The result has one entry per distinct UUID in the order they were
+// given, with Found set to false for wallets that do not exist. UUIDs are
+// returned in their canonical lowercase form.
+func (s *Service) GetBalances(ctx context.Context, walletUUIDs []string) ([]types.WalletBalance, error) {
+	start := time.Now()
+	logger := s.loggerFrom(ctx).With(zap.Int("wallets", len(walletUUIDs)))
+	defer func() {
+		duration := time.Since(start)
+		if duration > 100*time.Millisecond {
+			logger.Warn("GetBalances slow",
+				zap.Duration("duration", duration))
+		} else {
+			logger.Debug("GetBalances finished",
+				zap.Duration("duration", duration))
+		}
+	}()
+	logger.Info("GetBalances called")
+
+	if len(walletUUIDs) == 0 {
+		logger.Warn("GetBalances: no wallets requested")
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUIDs are empty"))
+	}
+
+	if len(walletUUIDs) > types.MaxBalancesLookup {
+		logger.Warn("GetBalances: too many wallets requested")
+		return nil, types.ErrBadRequest(fmt.Errorf("no more than %d wallets can be requested at once", types.MaxBalancesLookup))
+	}
+
+	unique := make([]string, 0, len(walletUUIDs))
+	seen := make(map[string]struct{}, len(walletUUIDs))
+	for _, walletUUID := range walletUUIDs {
+		parsed, err := uuid.Parse(walletUUID)
+		if err != nil {
+			logger.Warn("GetBalances: invalid UUID",
+				zap.String("wallet_uuid", walletUUID),
+				zap.Error(err))
+			return nil, types.ErrBadRequest(fmt.Errorf("walletUUID %s is not valid: %w", walletUUID, err))
+		}
+		// Кэш, база и ответ работают с каноническим видом UUID
+		walletUUID = parsed.String()
+		if _, ok := seen[walletUUID]; ok {
+			continue
+		}
+		seen[walletUUID] = struct{}{}
+		unique = append(unique, walletUUID)
+	}
+
+	// Чужие кошельки отдаём как несуществующие
+	hidden, err := s.hiddenWallets(ctx, logger, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := unique
+	if len(hidden) > 0 {
+		visible = make([]string, 0, len(unique)-len(hidden))
+		for _, walletUUID := range unique {
+			if _, ok := hidden[walletUUID]; !ok {
+				visible = append(visible, walletUUID)
+			}
+		}
+	}
+
+	balances := make(map[string]types.Funds, len(visible))
+	if len(visible) > 0 {
+		balances = s.getCachedBalances(ctx, logger, visible)
+	}
+
+	misses := make([]string, 0, len(visible)-len(balances))
+	for _, walletUUID := range visible {
+		if _, ok := balances[walletUUID]; !ok {
+			misses = append(misses, walletUUID)
+		}
+	}
+
+	logger.Debug("GetBalances: cache lookup finished",
+		zap.Int("hits", len(balances)),
+		zap.Int("misses", len(misses)))
+
+	if len(misses) > 0 {
+		loaded, err := s.repo.GetBalances(ctx, misses)
+		if err != nil {
+			logger.Error("GetBalances: failed to load from repository",
+				zap.Error(err))
+			if errors.Is(err, types.ErrQueryCanceled) {
+				return nil, types.ErrServiceUnavailable(err)
+			}
+			return nil, types.ErrInternalServerError(err)
+		}
+
+		s.cacheBalances(ctx, logger, misses, loaded)
+
+		for walletUUID, funds := range loaded {
+			balances[walletUUID] = funds
+		}
+	}
+
+	result := make([]types.WalletBalance, 0, len(unique))
+	for _, walletUUID := range unique {
+		funds, found := balances[walletUUID]
+		result = append(result, types.WalletBalance{
+			WalletUUID: walletUUID,
+			Funds:      funds,
+			Found:      found,
+		})
+	}
+
+	logger.Info("GetBalances: success",
+		zap.Int("found", len(balances)))
+
+	return result, nil
+}
+
+// getCachedBalances returns the cached balances of the given wallets. Cache
+// errors are logged and treated as misses.
+func (s *Service) getCachedBalances(ctx context.Context, logger *zap.Logger, walletUUIDs []string) map[string]types.Funds {
+	balances := make(map[string]types.Funds, len(walletUUIDs))
+
+	keys := make([]string, 0, len(walletUUIDs))
+	for _, walletUUID := range walletUUIDs {
+		keys = append(keys, balanceCacheKey(walletUUID))
+	}
+
+	var values []interface{}
+	if _, err := s.cachePolicy(logger).Do(ctx, func(ctx context.Context) error {
+		var mgetErr error
+		values, mgetErr = s.redis.MGet(ctx, keys...).Result()
+		return mgetErr
+	}); err != nil {
+		logger.Warn("GetBalances: failed to read cache",
+			zap.Error(err))
+		return balances
+	}
+
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		funds, err := decodeFunds(str)
+		if err != nil {
+			logger.Warn("GetBalances: invalid cached balance",
+				zap.String("cache_key", keys[i]),
+				zap.Error(err))
+			continue
+		}
+		balances[walletUUIDs[i]] = funds
+	}
+
+	return balances
+}
+
+// cacheBalances writes the balances of the given wallets back to the cache in
+// one pipeline. Failures are only logged, the cache is optional.
+func (s *Service) cacheBalances(ctx context.Context, logger *zap.Logger, walletUUIDs []string, balances map[string]types.Funds) {
+	if len(balances) == 0 {
+		return
+	}
+
+	if _, err := s.cachePolicy(logger).Do(ctx, func(ctx context.Context) error {
+		_, pipeErr := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, walletUUID := range walletUUIDs {
+				if funds, ok := balances[walletUUID]; ok {
+					pipe.Set(ctx, balanceCacheKey(walletUUID), encodeFunds(funds), balanceCacheTTL)
+				}
+			}
+			return nil
+		})
+		return pipeErr
+	}); err != nil {
+		logger.Warn("GetBalances: failed to set cache",
+			zap.Error(err))
+		return
+	}
+
+	logger.Debug("GetBalances: balances cached",
+		zap.Int("count", len(balances)),
+		zap.Duration("ttl", balanceCacheTTL))
+}
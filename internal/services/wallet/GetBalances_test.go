@@ -0,0 +1,131 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGetBalances_Validation(t *testing.T) {
+	service, _ := setupService(t)
+	ctx := context.Background()
+
+	t.Run("empty list", func(t *testing.T) {
+		balances, err := service.GetBalances(ctx, nil)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.Nil(t, balances)
+	})
+
+	t.Run("too many wallets", func(t *testing.T) {
+		walletUUIDs := make([]string, types.MaxBalancesLookup+1)
+		balances, err := service.GetBalances(ctx, walletUUIDs)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.Nil(t, balances)
+	})
+
+	t.Run("invalid UUID", func(t *testing.T) {
+		balances, err := service.GetBalances(ctx, []string{uuid.New().String(), "invalid-uuid"})
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.Contains(t, err.Error(), "invalid-uuid")
+		assert.Nil(t, balances)
+	})
+}
+
+func TestGetBalances_CacheHitsAndMisses(t *testing.T) {
+	redisMockClient, redisMock := redismock.NewClientMock()
+	logger := zaptest.NewLogger(t)
+	repo := new(MockRepository)
+	service := walletservice.NewService(repo, redisMockClient, logger)
+
+	ctx := context.Background()
+	cached := uuid.New().String()
+	stored := uuid.New().String()
+	missing := uuid.New().String()
+
+	redisMock.ExpectMGet("wallet:balance:"+cached, "wallet:balance:"+stored, "wallet:balance:"+missing).
+		SetVal([]interface{}{"500:0", nil, nil})
+	repo.On("GetBalances", ctx, []string{stored, missing}).Return(map[string]types.Funds{stored: types.NewFunds(-300, 1000)}, nil)
+	redisMock.ExpectSet("wallet:balance:"+stored, "-300:1000", 15*time.Second).SetVal("OK")
+
+	balances, err := service.GetBalances(ctx, []string{cached, stored, missing, cached})
+	require.NoError(t, err)
+	assert.Equal(t, []types.WalletBalance{
+		{WalletUUID: cached, Funds: types.NewFunds(500, 0), Found: true},
+		{WalletUUID: stored, Funds: types.NewFunds(-300, 1000), Found: true},
+		{WalletUUID: missing, Found: false},
+	}, balances)
+
+	repo.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetBalances_CanonicalUUIDs(t *testing.T) {
+	redisMockClient, redisMock := redismock.NewClientMock()
+	logger := zaptest.NewLogger(t)
+	repo := new(MockRepository)
+	service := walletservice.NewService(repo, redisMockClient, logger)
+
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	redisMock.ExpectMGet("wallet:balance:" + walletUUID).SetVal([]interface{}{nil})
+	repo.On("GetBalances", ctx, []string{walletUUID}).Return(map[string]types.Funds{walletUUID: types.NewFunds(100, 0)}, nil)
+	redisMock.ExpectSet("wallet:balance:"+walletUUID, "100:0", 15*time.Second).SetVal("OK")
+
+	balances, err := service.GetBalances(ctx, []string{strings.ToUpper(walletUUID), walletUUID, "urn:uuid:" + walletUUID})
+	require.NoError(t, err)
+	assert.Equal(t, []types.WalletBalance{{WalletUUID: walletUUID, Funds: types.NewFunds(100, 0), Found: true}}, balances)
+
+	repo.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetBalances_AllCached(t *testing.T) {
+	redisMockClient, redisMock := redismock.NewClientMock()
+	logger := zaptest.NewLogger(t)
+	repo := new(MockRepository)
+	service := walletservice.NewService(repo, redisMockClient, logger)
+
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	redisMock.ExpectMGet("wallet:balance:" + walletUUID).SetVal([]interface{}{"42:0"})
+
+	balances, err := service.GetBalances(ctx, []string{walletUUID})
+	require.NoError(t, err)
+	assert.Equal(t, []types.WalletBalance{{WalletUUID: walletUUID, Funds: types.NewFunds(42, 0), Found: true}}, balances)
+
+	repo.AssertNotCalled(t, "GetBalances")
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetBalances_RepositoryError(t *testing.T) {
+	redisMockClient, redisMock := redismock.NewClientMock()
+	logger := zaptest.NewLogger(t)
+	repo := new(MockRepository)
+	service := walletservice.NewService(repo, redisMockClient, logger)
+
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	redisMock.ExpectMGet("wallet:balance:" + walletUUID).SetVal([]interface{}{nil})
+	repo.On("GetBalances", ctx, []string{walletUUID}).Return(nil, errors.New("db error"))
+
+	balances, err := service.GetBalances(ctx, []string{walletUUID})
+	require.Error(t, err)
+	assert.Equal(t, 500, err.(types.HTTPError).Code)
+	assert.Nil(t, balances)
+}
@@ -0,0 +1,71 @@
+package walletservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StreamStatement writes to w the statement of the wallet for the operations
+// applied after from and up to to. Errors returned before w is first written
+// to are HTTP errors; later ones mean the statement was cut short.
+func (s *Service) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.Time("from", from),
+		zap.Time("to", to))
+	logger.Info("StreamStatement called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("StreamStatement: invalid UUID",
+			zap.Error(err))
+		return types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if !from.Before(to) {
+		logger.Warn("StreamStatement: empty period")
+		return types.ErrBadRequest(fmt.Errorf("from must be before to"))
+	}
+
+	if err := s.checkOwnership(ctx, logger, walletUUID); err != nil {
+		return err
+	}
+
+	counter := &countingStatementWriter{StatementWriter: w}
+	if err := s.repo.StreamStatement(ctx, walletUUID, from, to, counter); err != nil {
+		if !counter.started {
+			return translateHistoryError(logger, err)
+		}
+		logger.Error("StreamStatement: statement cut short",
+			zap.Int("entries", counter.entries),
+			zap.Error(err))
+		return err
+	}
+
+	logger.Info("StreamStatement: success",
+		zap.Int("entries", counter.entries))
+
+	return nil
+}
+
+// countingStatementWriter tells whether the statement has started and how
+// many operations it has so far.
+type countingStatementWriter struct {
+	types.StatementWriter
+	started bool
+	entries int
+}
+
+func (w *countingStatementWriter) WriteOpening(balance int) error {
+	w.started = true
+	return w.StatementWriter.WriteOpening(balance)
+}
+
+func (w *countingStatementWriter) WriteEntry(entry *types.StatementEntry) error {
+	w.entries++
+	return w.StatementWriter.WriteEntry(entry)
+}
@@ -0,0 +1,72 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopStatementWriter struct{}
+
+func (nopStatementWriter) WriteOpening(balance int) error               { return nil }
+func (nopStatementWriter) WriteEntry(entry *types.StatementEntry) error { return nil }
+func (nopStatementWriter) WriteClosing(balance int) error               { return nil }
+
+func TestStreamStatement(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("StreamStatement", ctx, walletUUID, from, to, mock.Anything).
+			Run(func(args mock.Arguments) {
+				w := args.Get(4).(types.StatementWriter)
+				require.NoError(t, w.WriteOpening(100))
+				require.NoError(t, w.WriteEntry(&types.StatementEntry{Operation: "DEPOSIT", Amount: 50, Balance: 150}))
+				require.NoError(t, w.WriteClosing(150))
+			}).
+			Return(nil)
+
+		require.NoError(t, service.StreamStatement(ctx, walletUUID, from, to, nopStatementWriter{}))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		service, _ := setupService(t)
+
+		err := service.StreamStatement(ctx, walletUUID, to, from, nopStatementWriter{})
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("StreamStatement", ctx, walletUUID, from, to, mock.Anything).Return(types.ErrWalletNotFound)
+
+		err := service.StreamStatement(ctx, walletUUID, from, to, nopStatementWriter{})
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("failure after the statement started", func(t *testing.T) {
+		service, repo := setupService(t)
+		readErr := errors.New("connection reset")
+		repo.On("StreamStatement", ctx, walletUUID, from, to, mock.Anything).
+			Run(func(args mock.Arguments) {
+				require.NoError(t, args.Get(4).(types.StatementWriter).WriteOpening(100))
+			}).
+			Return(readErr)
+
+		err := service.StreamStatement(ctx, walletUUID, from, to, nopStatementWriter{})
+		assert.Equal(t, readErr, err)
+	})
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
-	"strings"
 	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
@@ -13,28 +11,33 @@ import (
 	"go.uber.org/zap"
 )
 
-func (s *Service) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) error {
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		err := s.updateBalanceOnce(ctx, req)
-		if err != nil {
-			if strings.Contains(err.Error(), "deadlock detected") {
-				s.logger.Warn("Deadlock detected, retrying...",
-					zap.Int("attempt", i+1),
-					zap.String("wallet_uuid", req.WalletUUID))
-				time.Sleep(time.Duration(rand.Intn(100)) * time.Millisecond)
-				continue
-			}
-			return err
-		}
-		return nil
+// UpdateBalance applies a single operation and returns the fee charged for it.
+func (s *Service) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", req.WalletUUID),
+		zap.String("reference_id", req.ReferenceID))
+
+	var fee types.Fee
+	attempts, err := s.dbPolicy(logger).Do(ctx, func(ctx context.Context) error {
+		var updateErr error
+		fee, updateErr = s.updateBalanceOnce(ctx, req)
+		return updateErr
+	})
+	if attempts > 1 {
+		logger.Info("UpdateBalance: retried",
+			zap.Int("attempts", attempts),
+			zap.Bool("success", err == nil))
+	}
+
+	if err = retryResult(ctx, err); err != nil {
+		return types.Fee{}, err
 	}
-	return types.ErrConflict(fmt.Errorf("too many retries due to deadlock"))
+	return fee, nil
 }
 
-func (s *Service) updateBalanceOnce(ctx context.Context, wur *types.WalletUpdateRequest) error {
+func (s *Service) updateBalanceOnce(ctx context.Context, wur *types.WalletUpdateRequest) (types.Fee, error) {
 	start := time.Now()
-	logger := s.logger.With(zap.String("wallet_uuid", wur.WalletUUID))
+	logger := s.loggerFrom(ctx).With(zap.String("wallet_uuid", wur.WalletUUID))
 	defer func() {
 		duration := time.Since(start)
 		if duration > 100*time.Millisecond {
@@ -45,27 +48,73 @@ func (s *Service) updateBalanceOnce(ctx context.Context, wur *types.WalletUpdate
 				zap.Duration("duration", duration))
 		}
 	}()
-	logger.Info("UpdateBalance called",
+	logger.Debug("UpdateBalance called",
 		zap.String("operation", wur.Operation),
 		zap.Int("amount", wur.Amount),
 		zap.String("reference_id", wur.ReferenceID),
 	)
 
+	if err := validateUpdateRequest(logger, wur); err != nil {
+		return types.Fee{}, err
+	}
+
+	if err := s.checkOwnership(ctx, logger, wur.WalletUUID); err != nil {
+		return types.Fee{}, err
+	}
+
+	logger.Debug("UpdateBalance: checking idempotency",
+		zap.String("reference_id", wur.ReferenceID))
+	exists, err := s.repo.CheckOperationExists(ctx, wur.ReferenceID)
+	if err != nil {
+		logger.Error("UpdateBalance: failed to check idempotency",
+			zap.String("reference_id", wur.ReferenceID),
+			zap.Error(err))
+		return types.Fee{}, types.ErrInternalServerError(fmt.Errorf("failed to check idempotency: %w", err))
+	}
+
+	if exists {
+		logger.Warn("UpdateBalance: idempotency conflict")
+		return types.Fee{}, types.ErrConflict(fmt.Errorf("operation with reference_id %s already processed: %w", wur.ReferenceID, types.ErrOperationExists))
+	}
+
+	logger.Debug("UpdateBalance: idempotency check passed",
+		zap.String("reference_id", wur.ReferenceID))
+
+	fee, err := s.repo.UpdateBalance(ctx, wur)
+	if err != nil {
+		return types.Fee{}, translateRepoError(logger.With(
+			zap.String("operation", wur.Operation),
+			zap.Int("amount", wur.Amount),
+			zap.String("reference_id", wur.ReferenceID),
+		), err)
+	}
+
+	logger.Info("UpdateBalance: success",
+		zap.String("operation", wur.Operation),
+		zap.Int("amount", wur.Amount),
+		zap.String("reference_id", wur.ReferenceID),
+		zap.Int("fee", fee.Total))
+
+	return fee, nil
+}
+
+// validateUpdateRequest checks a request before it reaches the repository.
+func validateUpdateRequest(logger *zap.Logger, wur *types.WalletUpdateRequest) error {
 	if wur.WalletUUID == "" {
 		logger.Warn("UpdateBalance: walletUUID is empty")
-		return types.ErrBadRequest(fmt.Errorf("walletUUID is empty"))
+		return types.ErrBadRequest(types.NewFieldError("valletId", "walletUUID is empty"))
 	}
 
 	if wur.Amount <= 0 {
 		logger.Warn("UpdateBalance: invalid amount",
 			zap.Int("amount", wur.Amount))
-		return types.ErrBadRequest(fmt.Errorf("amount must be positive"))
+		return types.ErrBadRequest(types.NewFieldError("amount", "amount must be positive"))
 	}
 
 	if wur.Operation != types.OperationTypeDeposit && wur.Operation != types.OperationTypeWithdraw {
 		logger.Warn("UpdateBalance: invalid operation type",
 			zap.String("operation", wur.Operation))
-		return types.ErrBadRequest(fmt.Errorf(
+		return types.ErrBadRequest(types.NewFieldError("operationType",
 			"operation must be either %s or %s",
 			types.OperationTypeDeposit,
 			types.OperationTypeWithdraw,
@@ -76,62 +125,65 @@ func (s *Service) updateBalanceOnce(ctx context.Context, wur *types.WalletUpdate
 		logger.Warn("UpdateBalance: invalid reference_id",
 			zap.String("reference_id", wur.ReferenceID),
 			zap.Error(err))
-		return types.ErrBadRequest(fmt.Errorf("ReferenceID is not valid UUID: %w", err))
+		return types.ErrBadRequest(types.NewFieldError("referenceId", "ReferenceID is not valid UUID: %v", err))
 	}
 
 	if _, err := uuid.Parse(wur.WalletUUID); err != nil {
 		logger.Warn("UpdateBalance: invalid wallet_uuid", zap.Error(err))
-		return types.ErrBadRequest(fmt.Errorf("WalletUUID is not valid UUID: %w", err))
-	}
-
-	logger.Debug("UpdateBalance: checking idempotency",
-		zap.String("reference_id", wur.ReferenceID))
-	exists, err := s.repo.CheckOperationExists(ctx, wur.ReferenceID)
-	if err != nil {
-		logger.Error("UpdateBalance: failed to check idempotency",
-			zap.String("reference_id", wur.ReferenceID),
-			zap.Error(err))
-		return types.ErrInternalServerError(fmt.Errorf("failed to check idempotency: %w", err))
+		return types.ErrBadRequest(types.NewFieldError("valletId", "WalletUUID is not valid UUID: %v", err))
 	}
 
-	if exists {
-		logger.Warn("UpdateBalance: idempotency conflict")
-		return types.ErrConflict(fmt.Errorf("operation with reference_id %s already processed", wur.ReferenceID))
+	if err := wur.OperationDetails.Validate(); err != nil {
+		logger.Warn("UpdateBalance: invalid operation details", zap.Error(err))
+		return types.ErrBadRequest(err)
 	}
 
-	logger.Debug("UpdateBalance: idempotency check passed",
-		zap.String("reference_id", wur.ReferenceID))
+	return nil
+}
 
-	if err = s.repo.UpdateBalance(ctx, wur); err != nil {
-		switch {
-		case errors.Is(err, types.ErrWalletNotFound):
-			logger.Info("UpdateBalance: wallet not found")
-			return types.ErrNotFound(err)
-		case errors.Is(err, types.ErrInsufficientFunds):
-			logger.Info("UpdateBalance: insufficient funds",
-				zap.Int("amount", wur.Amount))
-			return types.ErrBadRequest(err)
-		case errors.Is(err, types.ErrConcurrentUpdate):
-			logger.Warn("UpdateBalance: concurrent update",
-				zap.String("reference_id", wur.ReferenceID))
-			return types.ErrConflict(err)
-		case errors.Is(err, types.ErrOperationExists):
-			logger.Warn("UpdateBalance: operation exists (race)",
-				zap.String("reference_id", wur.ReferenceID))
-			return types.ErrConflict(err)
-		default:
-			logger.Error("UpdateBalance: unexpected error",
-				zap.String("operation", wur.Operation),
-				zap.Int("amount", wur.Amount),
-				zap.Error(err))
-			return types.ErrInternalServerError(err)
-		}
+// translateRepoError maps an error returned by the repository to the HTTP
+// error returned to the caller. The logger is expected to carry the request
+// fields.
+func translateRepoError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("UpdateBalance: wallet not found")
+		return types.ErrNotFound(err)
+	case errors.Is(err, types.ErrWalletFrozen):
+		logger.Info("UpdateBalance: wallet is frozen")
+		return types.ErrForbidden(err)
+	case errors.Is(err, types.ErrInsufficientFunds):
+		logger.Info("UpdateBalance: insufficient funds")
+		return types.ErrBadRequest(err)
+	case errors.Is(err, types.ErrLimitExceeded):
+		logger.Info("UpdateBalance: limit exceeded",
+			zap.Error(err))
+		return types.ErrBadRequest(err)
+	case errors.Is(err, types.ErrConcurrentUpdate):
+		logger.Warn("UpdateBalance: concurrent update")
+		return types.ErrConflict(err)
+	case errors.Is(err, types.ErrOperationExists):
+		logger.Warn("UpdateBalance: operation exists (race)")
+		return types.ErrConflict(err)
+	case errors.Is(err, types.ErrDeadlock), errors.Is(err, types.ErrSerializationFailure):
+		logger.Warn("UpdateBalance: transaction aborted by concurrent transaction",
+			zap.Error(err))
+		return types.ErrConflict(err)
+	case errors.Is(err, types.ErrUniqueViolation):
+		logger.Warn("UpdateBalance: unique constraint violated",
+			zap.Error(err))
+		return types.ErrConflict(err)
+	case errors.Is(err, types.ErrCheckViolation):
+		logger.Warn("UpdateBalance: check constraint violated",
+			zap.Error(err))
+		return types.ErrBadRequest(err)
+	case errors.Is(err, types.ErrQueryCanceled):
+		logger.Warn("UpdateBalance: query canceled",
+			zap.Error(err))
+		return types.ErrServiceUnavailable(err)
+	default:
+		logger.Error("UpdateBalance: unexpected error",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
 	}
-
-	logger.Info("UpdateBalance: success",
-		zap.String("operation", wur.Operation),
-		zap.Int("amount", wur.Amount),
-		zap.String("reference_id", wur.ReferenceID))
-
-	return nil
 }
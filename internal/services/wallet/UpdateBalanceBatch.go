@@ -0,0 +1,164 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// UpdateBalanceBatch applies several operations at once. In atomic mode all of
+// them are applied in one transaction or none is, and the first failing item is
+// reported as the error. Otherwise every item is applied on its own and its
+// outcome is reported in the corresponding result.
+func (s *Service) UpdateBalanceBatch(ctx context.Context, atomic bool, reqs []*types.WalletUpdateRequest) ([]types.BatchItemResult, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.Int("batch_size", len(reqs)),
+		zap.Bool("atomic", atomic))
+	logger.Info("UpdateBalanceBatch called")
+
+	if len(reqs) == 0 {
+		logger.Warn("UpdateBalanceBatch: batch is empty")
+		return nil, types.ErrBadRequest(fmt.Errorf("batch is empty"))
+	}
+
+	if len(reqs) > types.MaxBatchSize {
+		logger.Warn("UpdateBalanceBatch: batch is too large")
+		return nil, types.ErrBadRequest(fmt.Errorf("batch must not contain more than %d items", types.MaxBatchSize))
+	}
+
+	seen := make(map[string]int, len(reqs))
+	for i, req := range reqs {
+		if j, ok := seen[req.ReferenceID]; ok {
+			logger.Warn("UpdateBalanceBatch: duplicate reference_id",
+				zap.String("reference_id", req.ReferenceID))
+			return nil, types.ErrBadRequest(fmt.Errorf("items %d and %d have the same reference_id %s", j, i, req.ReferenceID))
+		}
+		seen[req.ReferenceID] = i
+	}
+
+	if !atomic {
+		return s.updateBalanceBestEffort(ctx, logger, reqs), nil
+	}
+
+	if err := s.updateBalanceAtomic(ctx, logger, reqs); err != nil {
+		return nil, err
+	}
+
+	results := make([]types.BatchItemResult, 0, len(reqs))
+	for _, req := range reqs {
+		results = append(results, batchItemResult(req, nil))
+	}
+
+	logger.Info("UpdateBalanceBatch: success")
+
+	return results, nil
+}
+
+func (s *Service) updateBalanceBestEffort(ctx context.Context, logger *zap.Logger, reqs []*types.WalletUpdateRequest) []types.BatchItemResult {
+	results := make([]types.BatchItemResult, 0, len(reqs))
+	failed := 0
+	for _, req := range reqs {
+		var err error
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = types.ErrServiceUnavailable(fmt.Errorf("not processed: %w", ctxErr))
+		} else {
+			_, err = s.UpdateBalance(ctx, req)
+		}
+		if err != nil {
+			failed++
+		}
+		results = append(results, batchItemResult(req, err))
+	}
+
+	logger.Info("UpdateBalanceBatch: finished",
+		zap.Int("applied", len(reqs)-failed),
+		zap.Int("failed", failed))
+
+	return results
+}
+
+func (s *Service) updateBalanceAtomic(ctx context.Context, logger *zap.Logger, reqs []*types.WalletUpdateRequest) error {
+	for i, req := range reqs {
+		if err := validateUpdateRequest(logger, req); err != nil {
+			return batchItemHTTPError(i, req, err)
+		}
+	}
+
+	walletUUIDs := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		walletUUIDs = append(walletUUIDs, req.WalletUUID)
+	}
+	hidden, err := s.hiddenWallets(ctx, logger, walletUUIDs)
+	if err != nil {
+		return err
+	}
+	for i, req := range reqs {
+		if _, ok := hidden[req.WalletUUID]; ok {
+			return batchItemHTTPError(i, req, types.ErrNotFound(types.ErrWalletNotFound))
+		}
+	}
+
+	attempts, err := s.dbPolicy(logger).Do(ctx, func(ctx context.Context) error {
+		return s.updateBalanceAtomicOnce(ctx, logger, reqs)
+	})
+	if attempts > 1 {
+		logger.Info("UpdateBalanceBatch: retried",
+			zap.Int("attempts", attempts),
+			zap.Bool("success", err == nil))
+	}
+
+	return retryResult(ctx, err)
+}
+
+func (s *Service) updateBalanceAtomicOnce(ctx context.Context, logger *zap.Logger, reqs []*types.WalletUpdateRequest) error {
+	err := s.repo.UpdateBalanceBatch(ctx, reqs)
+	if err == nil {
+		return nil
+	}
+
+	var itemErr *types.BatchItemError
+	if errors.As(err, &itemErr) {
+		req := reqs[itemErr.Index]
+		return batchItemHTTPError(itemErr.Index, req, translateRepoError(logger.With(
+			zap.Int("item", itemErr.Index),
+			zap.String("operation", req.Operation),
+			zap.Int("amount", req.Amount),
+			zap.String("reference_id", req.ReferenceID),
+		), itemErr.Err))
+	}
+
+	return translateRepoError(logger, err)
+}
+
+// batchItemHTTPError keeps the status of err and points at the item that
+// caused it.
+func batchItemHTTPError(index int, req *types.WalletUpdateRequest, err error) error {
+	code := http.StatusInternalServerError
+	var httpErr types.HTTPError
+	if errors.As(err, &httpErr) {
+		code = httpErr.Code
+		err = httpErr.Err
+	}
+	return types.HTTPError{
+		Code: code,
+		Err:  &types.BatchItemError{Index: index, ReferenceID: req.ReferenceID, Err: err},
+	}
+}
+
+func batchItemResult(req *types.WalletUpdateRequest, err error) types.BatchItemResult {
+	if err == nil {
+		return types.BatchItemResult{ReferenceID: req.ReferenceID, Status: http.StatusOK}
+	}
+
+	// Ошибка описывается так же, как ответ с ошибкой целиком, без внутренних деталей
+	problem := types.NewProblem(err)
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	return types.BatchItemResult{ReferenceID: req.ReferenceID, Status: problem.Status, Code: problem.Code, Error: message}
+}
@@ -0,0 +1,163 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/mocks"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newBatchRequest(operation string, amount int) *types.WalletUpdateRequest {
+	return types.NewWalletUpdateRequest(uuid.New().String(), operation, amount, uuid.New().String())
+}
+
+func TestService_UpdateBalanceBatch_Atomic(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockReadWriter(ctrl)
+		svc := &Service{repo: mockRepo, logger: logger}
+
+		reqs := []*types.WalletUpdateRequest{
+			newBatchRequest(types.OperationTypeDeposit, 100),
+			newBatchRequest(types.OperationTypeWithdraw, 50),
+		}
+		mockRepo.EXPECT().UpdateBalanceBatch(gomock.Any(), reqs).Return(nil)
+
+		results, err := svc.UpdateBalanceBatch(context.Background(), true, reqs)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for i, result := range results {
+			assert.Equal(t, reqs[i].ReferenceID, result.ReferenceID)
+			assert.Equal(t, http.StatusOK, result.Status)
+		}
+	})
+
+	t.Run("invalid item fails before touching the repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockReadWriter(ctrl)
+		svc := &Service{repo: mockRepo, logger: logger}
+
+		reqs := []*types.WalletUpdateRequest{
+			newBatchRequest(types.OperationTypeDeposit, 100),
+			newBatchRequest(types.OperationTypeDeposit, 0),
+		}
+
+		_, err := svc.UpdateBalanceBatch(context.Background(), true, reqs)
+		require.Error(t, err)
+		httpErr, ok := err.(types.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Contains(t, err.Error(), "item 1")
+		assert.Contains(t, err.Error(), "amount must be positive")
+	})
+
+	t.Run("failing item is reported with its status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockReadWriter(ctrl)
+		svc := &Service{repo: mockRepo, logger: logger}
+
+		reqs := []*types.WalletUpdateRequest{
+			newBatchRequest(types.OperationTypeDeposit, 100),
+			newBatchRequest(types.OperationTypeWithdraw, 5000),
+		}
+		mockRepo.EXPECT().UpdateBalanceBatch(gomock.Any(), reqs).
+			Return(&types.BatchItemError{Index: 1, ReferenceID: reqs[1].ReferenceID, Err: types.ErrInsufficientFunds})
+
+		_, err := svc.UpdateBalanceBatch(context.Background(), true, reqs)
+		require.Error(t, err)
+		httpErr, ok := err.(types.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+
+		var itemErr *types.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+	})
+
+	t.Run("deadlock is retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockReadWriter(ctrl)
+		svc := &Service{repo: mockRepo, logger: logger}
+
+		reqs := []*types.WalletUpdateRequest{newBatchRequest(types.OperationTypeDeposit, 100)}
+		gomock.InOrder(
+			mockRepo.EXPECT().UpdateBalanceBatch(gomock.Any(), reqs).
+				Return(&types.BatchItemError{Index: 0, ReferenceID: reqs[0].ReferenceID, Err: fmt.Errorf("failed to update wallet: %w", types.ErrDeadlock)}),
+			mockRepo.EXPECT().UpdateBalanceBatch(gomock.Any(), reqs).Return(nil),
+		)
+
+		_, err := svc.UpdateBalanceBatch(context.Background(), true, reqs)
+		require.NoError(t, err)
+	})
+}
+
+func TestService_UpdateBalanceBatch_BestEffort(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockReadWriter(ctrl)
+	svc := &Service{repo: mockRepo, logger: logger}
+
+	reqs := []*types.WalletUpdateRequest{
+		newBatchRequest(types.OperationTypeDeposit, 100),
+		newBatchRequest(types.OperationTypeWithdraw, 5000),
+		newBatchRequest(types.OperationTypeDeposit, 0),
+	}
+
+	mockRepo.EXPECT().CheckOperationExists(gomock.Any(), reqs[0].ReferenceID).Return(false, nil)
+	mockRepo.EXPECT().UpdateBalance(gomock.Any(), reqs[0]).Return(types.Fee{}, nil)
+	mockRepo.EXPECT().CheckOperationExists(gomock.Any(), reqs[1].ReferenceID).Return(false, nil)
+	mockRepo.EXPECT().UpdateBalance(gomock.Any(), reqs[1]).Return(types.Fee{}, types.ErrInsufficientFunds)
+
+	results, err := svc.UpdateBalanceBatch(context.Background(), false, reqs)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Equal(t, types.ErrInsufficientFunds.Error(), results[1].Error)
+	assert.Equal(t, types.CodeInsufficientFunds, results[1].Code)
+	assert.Equal(t, http.StatusBadRequest, results[2].Status)
+	assert.Equal(t, "amount must be positive", results[2].Error)
+	assert.Equal(t, types.CodeValidationFailed, results[2].Code)
+}
+
+func TestService_UpdateBalanceBatch_Validation(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	svc := &Service{logger: logger}
+
+	t.Run("empty batch", func(t *testing.T) {
+		_, err := svc.UpdateBalanceBatch(context.Background(), true, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "batch is empty")
+	})
+
+	t.Run("too large batch", func(t *testing.T) {
+		reqs := make([]*types.WalletUpdateRequest, types.MaxBatchSize+1)
+		_, err := svc.UpdateBalanceBatch(context.Background(), false, reqs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not contain more than")
+	})
+
+	t.Run("duplicate reference_id", func(t *testing.T) {
+		req := newBatchRequest(types.OperationTypeDeposit, 100)
+		_, err := svc.UpdateBalanceBatch(context.Background(), false, []*types.WalletUpdateRequest{req, req})
+		require.Error(t, err)
+		var httpErr types.HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Contains(t, err.Error(), "same reference_id")
+	})
+}
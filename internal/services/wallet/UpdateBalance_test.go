@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
 	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/mocks"
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/golang/mock/gomock"
@@ -32,7 +34,7 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, nil)
 			},
 			expectedErr: nil,
 		},
@@ -46,7 +48,7 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, nil)
 			},
 			expectedErr: nil,
 		},
@@ -105,6 +107,34 @@ func TestService_UpdateBalance(t *testing.T) {
 			mockSetup:   func(m *mocks.MockReadWriter) {},
 			expectedErr: types.ErrBadRequest(fmt.Errorf("WalletUUID is not valid UUID")),
 		},
+		{
+			name: "invalid metadata key",
+			req: &types.WalletUpdateRequest{
+				WalletUUID:  uuid.New().String(),
+				Operation:   types.OperationTypeDeposit,
+				Amount:      100,
+				ReferenceID: uuid.New().String(),
+				OperationDetails: types.OperationDetails{
+					Metadata: map[string]string{"order id": "42"},
+				},
+			},
+			mockSetup:   func(m *mocks.MockReadWriter) {},
+			expectedErr: types.ErrBadRequest(fmt.Errorf("metadata key")),
+		},
+		{
+			name: "description too long",
+			req: &types.WalletUpdateRequest{
+				WalletUUID:  uuid.New().String(),
+				Operation:   types.OperationTypeDeposit,
+				Amount:      100,
+				ReferenceID: uuid.New().String(),
+				OperationDetails: types.OperationDetails{
+					Description: strings.Repeat("x", types.MaxDescriptionLength+1),
+				},
+			},
+			mockSetup:   func(m *mocks.MockReadWriter) {},
+			expectedErr: types.ErrBadRequest(fmt.Errorf("description must be at most")),
+		},
 		{
 			name: "idempotency conflict",
 			req: &types.WalletUpdateRequest{
@@ -141,7 +171,7 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.ErrWalletNotFound)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrWalletNotFound)
 			},
 			expectedErr: types.ErrNotFound(types.ErrWalletNotFound),
 		},
@@ -155,7 +185,7 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.ErrInsufficientFunds)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrInsufficientFunds)
 			},
 			expectedErr: types.ErrBadRequest(types.ErrInsufficientFunds),
 		},
@@ -168,10 +198,10 @@ func TestService_UpdateBalance(t *testing.T) {
 				ReferenceID: uuid.New().String(),
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
-				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.ErrConcurrentUpdate)
+				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil).Times(3)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrConcurrentUpdate).Times(3)
 			},
-			expectedErr: types.ErrConflict(types.ErrConcurrentUpdate),
+			expectedErr: types.ErrConflict(fmt.Errorf("too many retries due to concurrent updates")),
 		},
 		{
 			name: "operation exists race",
@@ -183,7 +213,7 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.ErrOperationExists)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrOperationExists)
 			},
 			expectedErr: types.ErrConflict(types.ErrOperationExists),
 		},
@@ -197,10 +227,53 @@ func TestService_UpdateBalance(t *testing.T) {
 			},
 			mockSetup: func(m *mocks.MockReadWriter) {
 				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(errors.New("unknown db error"))
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, errors.New("unknown db error"))
 			},
 			expectedErr: types.ErrInternalServerError(errors.New("unknown db error")),
 		},
+		{
+			name: "query canceled",
+			req: &types.WalletUpdateRequest{
+				WalletUUID:  uuid.New().String(),
+				Operation:   types.OperationTypeDeposit,
+				Amount:      100,
+				ReferenceID: uuid.New().String(),
+			},
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrQueryCanceled)
+			},
+			expectedErr: types.ErrServiceUnavailable(types.ErrQueryCanceled),
+		},
+		{
+			name: "check violation",
+			req: &types.WalletUpdateRequest{
+				WalletUUID:  uuid.New().String(),
+				Operation:   types.OperationTypeDeposit,
+				Amount:      100,
+				ReferenceID: uuid.New().String(),
+			},
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, types.ErrCheckViolation)
+			},
+			expectedErr: types.ErrBadRequest(types.ErrCheckViolation),
+		},
+		{
+			name: "limit exceeded",
+			req: &types.WalletUpdateRequest{
+				WalletUUID:  uuid.New().String(),
+				Operation:   types.OperationTypeWithdraw,
+				Amount:      1000,
+				ReferenceID: uuid.New().String(),
+			},
+			mockSetup: func(m *mocks.MockReadWriter) {
+				m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
+				m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).
+					Return(types.Fee{}, &types.LimitExceededError{Limit: types.LimitDailyWithdrawal, Max: 500, Value: 1000})
+			},
+			expectedErr: types.ErrBadRequest(&types.LimitExceededError{Limit: types.LimitDailyWithdrawal, Max: 500, Value: 1000}),
+		},
 		{
 			name: "deadlock retry success on 2nd attempt",
 			req: &types.WalletUpdateRequest{
@@ -212,9 +285,9 @@ func TestService_UpdateBalance(t *testing.T) {
 			mockSetup: func(m *mocks.MockReadWriter) {
 				gomock.InOrder(
 					m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil),
-					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(errors.New("deadlock detected")),
+					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, fmt.Errorf("failed to update wallet: %w", types.ErrDeadlock)),
 					m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil),
-					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(nil),
+					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, nil),
 				)
 			},
 			expectedErr: nil,
@@ -230,10 +303,10 @@ func TestService_UpdateBalance(t *testing.T) {
 			mockSetup: func(m *mocks.MockReadWriter) {
 				for i := 0; i < 3; i++ {
 					m.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
-					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(errors.New("deadlock detected")).Times(1)
+					m.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(types.Fee{}, fmt.Errorf("failed to update wallet: %w", types.ErrDeadlock)).Times(1)
 				}
 			},
-			expectedErr: types.ErrConflict(fmt.Errorf("too many retries due to deadlock")),
+			expectedErr: types.ErrConflict(fmt.Errorf("too many retries due to concurrent updates")),
 		},
 	}
 
@@ -255,7 +328,7 @@ func TestService_UpdateBalance(t *testing.T) {
 				logger: logger,
 			}
 
-			err := svc.UpdateBalance(context.Background(), tt.req)
+			_, err := svc.UpdateBalance(context.Background(), tt.req)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -273,9 +346,9 @@ func TestService_UpdateBalance_SlowOperationLogged(t *testing.T) {
 
 	mockRepo := mocks.NewMockReadWriter(ctrl)
 	mockRepo.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
-	mockRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *types.WalletUpdateRequest) error {
+	mockRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
 		time.Sleep(150 * time.Millisecond)
-		return nil
+		return types.Fee{}, nil
 	})
 
 	logger, _ := zap.NewDevelopment()
@@ -293,6 +366,70 @@ func TestService_UpdateBalance_SlowOperationLogged(t *testing.T) {
 		ReferenceID: uuid.New().String(),
 	}
 
-	err := svc.UpdateBalance(context.Background(), req)
+	_, err := svc.UpdateBalance(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestService_UpdateBalance_ContextCanceledWhileRetrying(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockRepo := mocks.NewMockReadWriter(ctrl)
+	mockRepo.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	mockRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
+		cancel()
+		return types.Fee{}, types.ErrDeadlock
+	})
+
+	logger, _ := zap.NewDevelopment()
+
+	svc := &Service{
+		repo:    mockRepo,
+		logger:  logger,
+		dbRetry: retry.Policy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour},
+	}
+
+	req := &types.WalletUpdateRequest{
+		WalletUUID:  uuid.New().String(),
+		Operation:   types.OperationTypeDeposit,
+		Amount:      100,
+		ReferenceID: uuid.New().String(),
+	}
+
+	_, err := svc.UpdateBalance(ctx, req)
+	var httpErr types.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, 503, httpErr.Code)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestService_UpdateBalance_ReturnsFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fee := types.Fee{Flat: 5, Percent: 15, Total: 20}
+
+	mockRepo := mocks.NewMockReadWriter(ctrl)
+	mockRepo.EXPECT().CheckOperationExists(gomock.Any(), gomock.Any()).Return(false, nil)
+	mockRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(fee, nil)
+
+	logger, _ := zap.NewDevelopment()
+
+	svc := &Service{
+		repo:   mockRepo,
+		logger: logger,
+	}
+
+	req := &types.WalletUpdateRequest{
+		WalletUUID:  uuid.New().String(),
+		Operation:   types.OperationTypeWithdraw,
+		Amount:      1000,
+		ReferenceID: uuid.New().String(),
+	}
+
+	got, err := svc.UpdateBalance(context.Background(), req)
 	assert.NoError(t, err)
+	assert.Equal(t, fee, got)
 }
@@ -0,0 +1,100 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// GetWalletLimits returns the limits of a wallet and how much of the daily
+// and monthly ones is already used.
+func (s *Service) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	logger := s.loggerFrom(ctx).With(zap.String("wallet_uuid", walletUUID))
+	logger.Info("GetWalletLimits called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("GetWalletLimits: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	limits, err := s.repo.GetWalletLimits(ctx, walletUUID)
+	if err != nil {
+		return nil, translateLimitsError(logger, err)
+	}
+
+	return limits, nil
+}
+
+// SetWalletLimits assigns a tier to a wallet and replaces its overrides.
+// An empty tier means the default one.
+func (s *Service) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) (*types.WalletLimits, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", walletUUID),
+		zap.String("tier", update.Tier))
+	logger.Info("SetWalletLimits called")
+
+	if _, err := uuid.Parse(walletUUID); err != nil {
+		logger.Warn("SetWalletLimits: invalid UUID",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(fmt.Errorf("walletUUID is not valid: %w", err))
+	}
+
+	if update.Tier == "" {
+		update.Tier = types.DefaultLimitTier
+	}
+
+	if err := validateLimitValues(update.Overrides); err != nil {
+		logger.Warn("SetWalletLimits: invalid overrides",
+			zap.Error(err))
+		return nil, types.ErrBadRequest(err)
+	}
+
+	if err := s.repo.SetWalletLimits(ctx, walletUUID, update); err != nil {
+		return nil, translateLimitsError(logger, err)
+	}
+
+	logger.Info("SetWalletLimits: success")
+
+	return s.GetWalletLimits(ctx, walletUUID)
+}
+
+func validateLimitValues(limits types.LimitValues) error {
+	positive := map[string]*int{
+		types.LimitMaxWithdrawal:     limits.MaxWithdrawal,
+		types.LimitDailyWithdrawal:   limits.DailyWithdrawal,
+		types.LimitMonthlyWithdrawal: limits.MonthlyWithdrawal,
+	}
+	for name, value := range positive {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+	if limits.MaxBalance != nil && *limits.MaxBalance < 0 {
+		return fmt.Errorf("%s must not be negative", types.LimitMaxBalance)
+	}
+	return nil
+}
+
+func translateLimitsError(logger *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, types.ErrWalletNotFound):
+		logger.Info("wallet not found")
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	case errors.Is(err, types.ErrUnknownTier):
+		logger.Info("unknown limit tier")
+		return types.ErrBadRequest(types.ErrUnknownTier)
+	case errors.Is(err, types.ErrQueryCanceled):
+		logger.Warn("query canceled",
+			zap.Error(err))
+		return types.ErrServiceUnavailable(err)
+	default:
+		logger.Error("failed to access wallet limits",
+			zap.Error(err))
+		return types.ErrInternalServerError(err)
+	}
+}
@@ -0,0 +1,100 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWalletLimits(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	t.Run("invalid UUID", func(t *testing.T) {
+		service, _ := setupService(t)
+		limits, err := service.GetWalletLimits(ctx, "invalid-uuid")
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.Nil(t, limits)
+	})
+
+	t.Run("wallet not found", func(t *testing.T) {
+		service, repo := setupService(t)
+		repo.On("GetWalletLimits", ctx, walletUUID).Return(nil, types.ErrWalletNotFound)
+
+		_, err := service.GetWalletLimits(ctx, walletUUID)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		expected := &types.WalletLimits{WalletUUID: walletUUID, Tier: types.DefaultLimitTier, DailyWithdrawn: 100}
+		repo.On("GetWalletLimits", ctx, walletUUID).Return(expected, nil)
+
+		limits, err := service.GetWalletLimits(ctx, walletUUID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, limits)
+	})
+}
+
+func TestSetWalletLimits(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+	negative := -1
+
+	t.Run("invalid override", func(t *testing.T) {
+		service, _ := setupService(t)
+		_, err := service.SetWalletLimits(ctx, walletUUID, &types.WalletLimitsUpdate{
+			Overrides: types.LimitValues{DailyWithdrawal: &negative},
+		})
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.Contains(t, err.Error(), types.LimitDailyWithdrawal)
+	})
+
+	t.Run("unknown tier", func(t *testing.T) {
+		service, repo := setupService(t)
+		update := &types.WalletLimitsUpdate{Tier: "platinum"}
+		repo.On("SetWalletLimits", ctx, walletUUID, update).
+			Return(fmt.Errorf("failed to set wallet limits: %w", types.ErrUnknownTier))
+
+		_, err := service.SetWalletLimits(ctx, walletUUID, update)
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		assert.ErrorIs(t, err, types.ErrUnknownTier)
+	})
+
+	t.Run("empty tier means default", func(t *testing.T) {
+		service, repo := setupService(t)
+		maxBalance := 10000
+		update := &types.WalletLimitsUpdate{Overrides: types.LimitValues{MaxBalance: &maxBalance}}
+		expected := &types.WalletLimits{WalletUUID: walletUUID, Tier: types.DefaultLimitTier}
+
+		repo.On("SetWalletLimits", ctx, walletUUID, &types.WalletLimitsUpdate{
+			Tier:      types.DefaultLimitTier,
+			Overrides: types.LimitValues{MaxBalance: &maxBalance},
+		}).Return(nil)
+		repo.On("GetWalletLimits", ctx, walletUUID).Return(expected, nil)
+
+		limits, err := service.SetWalletLimits(ctx, walletUUID, update)
+		require.NoError(t, err)
+		assert.Equal(t, expected, limits)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		service, repo := setupService(t)
+		update := &types.WalletLimitsUpdate{Tier: "gold"}
+		repo.On("SetWalletLimits", ctx, walletUUID, update).Return(errors.New("connection refused"))
+
+		_, err := service.SetWalletLimits(ctx, walletUUID, update)
+		require.Error(t, err)
+		assert.Equal(t, 500, err.(types.HTTPError).Code)
+	})
+}
@@ -0,0 +1,225 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"go.uber.org/zap"
+)
+
+// EnqueueUpdate validates the operation and queues it for AsyncWorker instead
+// of applying it. Limits and funds are checked only when the operation is
+// applied, the outcome is read with GetOperation.
+func (s *Service) EnqueueUpdate(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	logger := s.loggerFrom(ctx).With(
+		zap.String("wallet_uuid", req.WalletUUID),
+		zap.String("reference_id", req.ReferenceID))
+	logger.Info("EnqueueUpdate called",
+		zap.String("operation", req.Operation),
+		zap.Int("amount", req.Amount))
+
+	if err := validateUpdateRequest(logger, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOwnership(ctx, logger, req.WalletUUID); err != nil {
+		return nil, err
+	}
+
+	op, err := s.repo.EnqueueOperation(ctx, req)
+	if err != nil {
+		return nil, translateRepoError(logger, err)
+	}
+
+	logger.Info("EnqueueUpdate: operation queued",
+		zap.Int64("operation_id", op.ID))
+
+	return op, nil
+}
+
+// AsyncWorker applies the operations queued by Service.EnqueueUpdate. Several
+// workers may run against the same database, an operation is claimed by one of
+// them at a time and claimed again if it is still pending when its lease runs
+// out.
+type AsyncWorker struct {
+	repo         ReadWriter
+	logger       *zap.Logger
+	workers      int
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	maxAttempts  int
+	retry        retry.Policy
+}
+
+type AsyncWorkerOption func(*AsyncWorker)
+
+// WithAsyncWorkers sets how many queued operations are applied at once.
+func WithAsyncWorkers(n int) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.workers = n
+	}
+}
+
+// WithPollInterval sets how often the worker looks for queued operations
+// while the queue is empty.
+func WithPollInterval(interval time.Duration) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.pollInterval = interval
+	}
+}
+
+// WithAsyncBatchSize sets how many queued operations are claimed at once.
+func WithAsyncBatchSize(size int) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.batchSize = size
+	}
+}
+
+// WithLease sets how long a claimed operation is hidden from other workers.
+// It must outlast applying the operation with all its retries.
+func WithLease(lease time.Duration) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.lease = lease
+	}
+}
+
+// WithMaxAttempts sets after how many claims an operation that keeps failing
+// for reasons other than a rejection is marked as failed.
+func WithMaxAttempts(n int) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.maxAttempts = n
+	}
+}
+
+// WithAsyncRetry sets how an operation is retried after transaction conflicts
+// within one claim. The retryable predicate is always supplied by the worker.
+func WithAsyncRetry(policy retry.Policy) AsyncWorkerOption {
+	return func(w *AsyncWorker) {
+		w.retry = policy
+	}
+}
+
+func NewAsyncWorker(repo ReadWriter, logger *zap.Logger, opts ...AsyncWorkerOption) *AsyncWorker {
+	w := &AsyncWorker{
+		repo:         repo,
+		logger:       logger,
+		workers:      8,
+		pollInterval: 200 * time.Millisecond,
+		batchSize:    100,
+		lease:        30 * time.Second,
+		maxAttempts:  5,
+		retry:        retry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run applies queued operations until ctx is done. A full batch is followed by
+// the next one right away, otherwise the worker waits for the poll interval.
+func (w *AsyncWorker) Run(ctx context.Context) {
+	w.logger.Info("Async operation worker started",
+		zap.Int("workers", w.workers),
+		zap.Duration("poll_interval", w.pollInterval))
+
+	for {
+		if w.RunOnce(ctx) == w.batchSize && ctx.Err() == nil {
+			continue
+		}
+
+		timer := time.NewTimer(w.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			w.logger.Info("Async operation worker stopped")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// RunOnce claims one batch of queued operations, applies them and returns how
+// many were claimed.
+func (w *AsyncWorker) RunOnce(ctx context.Context) int {
+	ops, err := w.repo.ClaimQueuedOperations(ctx, w.batchSize, w.lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Failed to claim queued operations",
+				zap.Error(err))
+		}
+		return 0
+	}
+
+	var (
+		wg   sync.WaitGroup
+		jobs = make(chan *types.Operation)
+	)
+	for i := 0; i < min(w.workers, len(ops)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range jobs {
+				w.apply(ctx, op)
+			}
+		}()
+	}
+	for _, op := range ops {
+		jobs <- op
+	}
+	close(jobs)
+	wg.Wait()
+
+	return len(ops)
+}
+
+// apply applies a claimed operation. A rejected operation is marked as failed
+// by the repository itself. Other errors leave it pending to be claimed again
+// after the lease, until it runs out of attempts.
+func (w *AsyncWorker) apply(ctx context.Context, op *types.Operation) {
+	logger := w.logger.With(
+		zap.String("wallet_uuid", op.WalletUUID),
+		zap.String("reference_id", op.ReferenceID),
+		zap.Int("attempt", op.Attempts))
+
+	if ctx.Err() != nil {
+		// Операция заберётся снова после истечения аренды
+		return
+	}
+
+	req := types.NewWalletUpdateRequest(op.WalletUUID, op.Operation, op.Amount, op.ReferenceID)
+	policy := w.retry
+	policy.Retryable = isRetryable
+	_, err := policy.Do(ctx, func(ctx context.Context) error {
+		_, updateErr := w.repo.UpdateBalance(ctx, req)
+		return updateErr
+	})
+
+	switch {
+	case err == nil:
+		logger.Info("Queued operation applied")
+	case errors.Is(err, types.ErrOperationExists):
+		// Операцию уже провёл другой обработчик
+		logger.Info("Queued operation already processed")
+	case types.IsRejected(err):
+		logger.Info("Queued operation rejected",
+			zap.Error(err))
+	case ctx.Err() != nil:
+		return
+	case op.Attempts >= w.maxAttempts:
+		logger.Error("Queued operation failed, giving up",
+			zap.Error(err))
+		if failErr := w.repo.FailOperation(ctx, op.ReferenceID, err); failErr != nil {
+			logger.Error("Failed to mark queued operation as failed",
+				zap.Error(failErr))
+		}
+	default:
+		logger.Warn("Queued operation failed, will be retried",
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,205 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	walletmemory "github.com/artyomkorchagin/wallet-task/internal/repository/memory/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEnqueueUpdate(t *testing.T) {
+	ctx := context.Background()
+	walletUUID := uuid.New().String()
+
+	t.Run("invalid request", func(t *testing.T) {
+		service, repo := setupService(t)
+		_, err := service.EnqueueUpdate(ctx, types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 0, uuid.New().String()))
+		require.Error(t, err)
+		assert.Equal(t, 400, err.(types.HTTPError).Code)
+		repo.AssertNotCalled(t, "EnqueueOperation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		service, repo := setupService(t)
+		req := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 100, uuid.New().String())
+		queued := &types.Operation{ID: 1, ReferenceID: req.ReferenceID, Status: "PENDING", Async: true}
+		repo.On("EnqueueOperation", ctx, req).Return(queued, nil)
+
+		op, err := service.EnqueueUpdate(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, queued, op)
+	})
+
+	t.Run("duplicate reference ID", func(t *testing.T) {
+		service, repo := setupService(t)
+		req := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 100, uuid.New().String())
+		repo.On("EnqueueOperation", ctx, req).Return(nil, types.ErrOperationExists)
+
+		_, err := service.EnqueueUpdate(ctx, req)
+		require.Error(t, err)
+		assert.Equal(t, 409, err.(types.HTTPError).Code)
+	})
+
+	t.Run("foreign wallet looks missing", func(t *testing.T) {
+		service, repo := setupService(t)
+		ctx := types.ContextWithOwner(ctx, "user-1")
+		req := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 100, uuid.New().String())
+		repo.On("GetWalletOwners", ctx, []string{walletUUID}).Return(map[string]string{walletUUID: "user-2"}, nil)
+
+		_, err := service.EnqueueUpdate(ctx, req)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		repo.AssertNotCalled(t, "EnqueueOperation", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetOperation_Ownership(t *testing.T) {
+	ctx := types.ContextWithOwner(context.Background(), "user-1")
+	walletUUID := uuid.New().String()
+	referenceID := uuid.New().String()
+
+	service, repo := setupService(t)
+	repo.On("GetOperation", ctx, referenceID).Return(&types.Operation{WalletUUID: walletUUID, ReferenceID: referenceID}, nil)
+	repo.On("GetWalletOwners", ctx, []string{walletUUID}).Return(map[string]string{walletUUID: "user-2"}, nil)
+
+	_, err := service.GetOperation(ctx, referenceID)
+	require.Error(t, err)
+	assert.Equal(t, 404, err.(types.HTTPError).Code)
+	assert.ErrorIs(t, err, types.ErrOperationNotFound)
+}
+
+func TestAsyncWorker_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	lease := time.Minute
+	fastRetry := walletservice.WithAsyncRetry(retry.Policy{MaxAttempts: 3, BaseDelay: time.Microsecond, MaxDelay: time.Microsecond})
+
+	queued := func(attempts int) *types.Operation {
+		return &types.Operation{
+			WalletUUID:  uuid.New().String(),
+			Operation:   types.OperationTypeWithdraw,
+			Amount:      100,
+			ReferenceID: uuid.New().String(),
+			Status:      "PENDING",
+			Async:       true,
+			Attempts:    attempts,
+		}
+	}
+	request := func(op *types.Operation) any {
+		return mock.MatchedBy(func(req *types.WalletUpdateRequest) bool {
+			return req.ReferenceID == op.ReferenceID && req.WalletUUID == op.WalletUUID &&
+				req.Operation == op.Operation && req.Amount == op.Amount
+		})
+	}
+
+	t.Run("applies the claimed operations", func(t *testing.T) {
+		repo := new(MockRepository)
+		first, second := queued(1), queued(1)
+		repo.On("ClaimQueuedOperations", ctx, 10, lease).Return([]*types.Operation{first, second}, nil)
+		repo.On("UpdateBalance", mock.Anything, request(first)).Return(types.Fee{}, nil)
+		// Отказ помечает операцию в самом репозитории
+		repo.On("UpdateBalance", mock.Anything, request(second)).Return(types.Fee{}, types.ErrInsufficientFunds)
+
+		worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t), walletservice.WithAsyncBatchSize(10), walletservice.WithLease(lease))
+		assert.Equal(t, 2, worker.RunOnce(ctx))
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "FailOperation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("retries conflicts", func(t *testing.T) {
+		repo := new(MockRepository)
+		op := queued(1)
+		repo.On("ClaimQueuedOperations", ctx, 100, 30*time.Second).Return([]*types.Operation{op}, nil)
+		repo.On("UpdateBalance", mock.Anything, request(op)).Return(types.Fee{}, types.ErrDeadlock).Once()
+		repo.On("UpdateBalance", mock.Anything, request(op)).Return(types.Fee{}, nil).Once()
+
+		worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t), fastRetry)
+		assert.Equal(t, 1, worker.RunOnce(ctx))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("leaves a failing operation for the next claim", func(t *testing.T) {
+		repo := new(MockRepository)
+		op := queued(1)
+		repo.On("ClaimQueuedOperations", ctx, 100, 30*time.Second).Return([]*types.Operation{op}, nil)
+		repo.On("UpdateBalance", mock.Anything, request(op)).Return(types.Fee{}, errors.New("connection reset"))
+
+		worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t), fastRetry)
+		worker.RunOnce(ctx)
+		repo.AssertNotCalled(t, "FailOperation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		repo := new(MockRepository)
+		op := queued(5)
+		repo.On("ClaimQueuedOperations", ctx, 100, 30*time.Second).Return([]*types.Operation{op}, nil)
+		cause := errors.New("connection reset")
+		repo.On("UpdateBalance", mock.Anything, request(op)).Return(types.Fee{}, cause)
+		repo.On("FailOperation", ctx, op.ReferenceID, cause).Return(nil)
+
+		worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t), fastRetry, walletservice.WithMaxAttempts(5))
+		worker.RunOnce(ctx)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("claim fails", func(t *testing.T) {
+		repo := new(MockRepository)
+		repo.On("ClaimQueuedOperations", ctx, 100, 30*time.Second).Return(nil, errors.New("db down"))
+
+		worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t))
+		assert.Zero(t, worker.RunOnce(ctx))
+	})
+}
+
+// TestAsyncWorker_Ledger queues operations through the service and applies
+// them with the worker on the in-memory repository.
+func TestAsyncWorker_Ledger(t *testing.T) {
+	ctx := context.Background()
+	repo := walletmemory.NewRepository()
+	service := walletservice.NewService(repo, nil, zaptest.NewLogger(t))
+	worker := walletservice.NewAsyncWorker(repo, zaptest.NewLogger(t))
+
+	wallet, err := service.CreateWallet(ctx, "", 0)
+	require.NoError(t, err)
+
+	deposit := types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, 100, uuid.NewString())
+	withdraw := types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeWithdraw, 500, uuid.NewString())
+	for _, req := range []*types.WalletUpdateRequest{deposit, withdraw} {
+		op, err := service.EnqueueUpdate(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "PENDING", op.Status)
+	}
+
+	// Синхронный запрос с той же ссылкой не должен обогнать очередь
+	_, err = service.UpdateBalance(ctx, deposit)
+	require.Error(t, err)
+	assert.Equal(t, 409, err.(types.HTTPError).Code)
+
+	assert.Equal(t, 2, worker.RunOnce(ctx))
+	assert.Zero(t, worker.RunOnce(ctx))
+
+	op, err := service.GetOperation(ctx, deposit.ReferenceID)
+	require.NoError(t, err)
+	assert.Equal(t, "APPLIED", op.Status)
+	assert.True(t, op.Async)
+	assert.Equal(t, 1, op.Attempts)
+
+	op, err = service.GetOperation(ctx, withdraw.ReferenceID)
+	require.NoError(t, err)
+	assert.Equal(t, "FAILED", op.Status)
+	assert.Equal(t, types.FailureInsufficientFunds, op.FailureCode)
+	assert.Contains(t, op.FailureMessage, types.ErrInsufficientFunds.Error())
+
+	funds, err := repo.GetBalance(ctx, wallet.WalletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 100, funds.Balance)
+}
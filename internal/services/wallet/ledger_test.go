@@ -0,0 +1,66 @@
+package walletservice_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	walletmemory "github.com/artyomkorchagin/wallet-task/internal/repository/memory/wallet"
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/services/wallet/ledgertest"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestService_Ledger runs the service on the in-memory repository, so the
+// ledger itself is exercised rather than scripted expectations.
+func TestService_Ledger(t *testing.T) {
+	ctx := context.Background()
+	repo := walletmemory.NewRepository()
+	service := walletservice.NewService(repo, nil, zaptest.NewLogger(t))
+
+	wallet, err := service.CreateWallet(ctx, "", 0)
+	require.NoError(t, err)
+
+	update := func(operation string, amount int, referenceID string) error {
+		_, err := service.UpdateBalance(ctx, types.NewWalletUpdateRequest(wallet.WalletUUID, operation, amount, referenceID))
+		return err
+	}
+	status := func(err error) int {
+		var httpErr types.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		return httpErr.Code
+	}
+
+	referenceID := uuid.NewString()
+	require.NoError(t, update(types.OperationTypeDeposit, 100, referenceID))
+	assert.Equal(t, http.StatusConflict, status(update(types.OperationTypeDeposit, 100, referenceID)))
+	assert.Equal(t, http.StatusBadRequest, status(update(types.OperationTypeWithdraw, 101, uuid.NewString())))
+
+	_, err = service.SetWalletFrozen(ctx, wallet.WalletUUID, true)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, status(update(types.OperationTypeWithdraw, 10, uuid.NewString())))
+	_, err = service.SetWalletFrozen(ctx, wallet.WalletUUID, false)
+	require.NoError(t, err)
+
+	results, err := service.UpdateBalanceBatch(ctx, false, []*types.WalletUpdateRequest{
+		types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeWithdraw, 60, uuid.NewString()),
+		types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeWithdraw, 60, uuid.NewString()),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+
+	funds, err := repo.GetBalance(ctx, wallet.WalletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 40, funds.Balance)
+}
+
+func TestService_LedgerInvariants(t *testing.T) {
+	ledgertest.Run(t, walletmemory.NewRepository(
+		walletmemory.WithTier(types.DefaultLimitTier, types.LimitValues{}, types.FeeRule{Flat: 1, PercentBps: 100})))
+}
@@ -0,0 +1,336 @@
+// Package ledgertest checks that no interleaving of concurrent
+// Service.UpdateBalance calls creates or destroys money. It fires randomized
+// rounds of concurrent deposits and withdrawals, some of them sent several
+// times with the same reference ID, at a service running on the given
+// repository and then checks the ledger of every wallet:
+//
+//   - each reference ID is applied at most once and only if a call succeeded,
+//   - the balance never goes below zero,
+//   - the balance equals the opening balance plus applied deposits minus
+//     applied withdrawals and their fees,
+//   - no operation is left PENDING.
+//
+// The repository is wrapped so that some updates fail with the errors of a
+// concurrent transaction before reaching it, which exercises the retries of
+// the service as well.
+//
+// Every round logs its seed. Setting WALLET_TEST_SEED replays a failed round.
+package ledgertest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const statusPending = "PENDING"
+
+// Run runs the rounds. The repository may be shared with other tests, the
+// rounds only look at the wallets they create.
+func Run(t *testing.T, repo walletservice.ReadWriter) {
+	rounds := 20
+	if testing.Short() {
+		rounds = 3
+	}
+
+	if env := os.Getenv("WALLET_TEST_SEED"); env != "" {
+		seed, err := strconv.ParseUint(env, 10, 64)
+		require.NoError(t, err, "WALLET_TEST_SEED must be an unsigned integer")
+		t.Run("seed "+env, func(t *testing.T) {
+			runRound(t, repo, seed)
+		})
+		return
+	}
+
+	for i := 0; i < rounds; i++ {
+		seed := rand.Uint64()
+		t.Run("seed "+strconv.FormatUint(seed, 10), func(t *testing.T) {
+			runRound(t, repo, seed)
+		})
+	}
+}
+
+// intent is an operation the round wants applied, sent copies times with the
+// same reference ID.
+type intent struct {
+	req    *types.WalletUpdateRequest
+	copies int
+}
+
+// outcome is what the callers were told about one reference ID.
+type outcome struct {
+	successes int
+	fee       int
+}
+
+func runRound(t *testing.T, repo walletservice.ReadWriter, seed uint64) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	var (
+		wallets = 1 + rng.IntN(3)
+		intents = 50 + rng.IntN(150)
+		workers = 4 + rng.IntN(13)
+	)
+	t.Logf("%d wallets, %d operations, %d workers", wallets, intents, workers)
+
+	// Стартовый баланс заводится обычным пополнением, поэтому сверка идёт
+	// от нуля и он проверяется вместе с остальными операциями
+	walletUUIDs := make([]string, wallets)
+	opening := make(map[string]*types.WalletUpdateRequest, wallets)
+	for i := range walletUUIDs {
+		wallet, err := repo.CreateWallet(ctx, "", 0)
+		require.NoError(t, err)
+		walletUUIDs[i] = wallet.WalletUUID
+
+		req := types.NewWalletUpdateRequest(wallet.WalletUUID, types.OperationTypeDeposit, 1+rng.IntN(500), uuid.NewString())
+		_, err = repo.UpdateBalance(ctx, req)
+		require.NoError(t, err)
+		opening[wallet.WalletUUID] = req
+	}
+
+	var calls []*types.WalletUpdateRequest
+	for i := 0; i < intents; i++ {
+		operation := types.OperationTypeDeposit
+		if rng.IntN(100) < 55 {
+			operation = types.OperationTypeWithdraw
+		}
+		in := intent{
+			req: types.NewWalletUpdateRequest(
+				walletUUIDs[rng.IntN(wallets)], operation, 1+rng.IntN(200), uuid.NewString()),
+			copies: 1,
+		}
+		if rng.IntN(100) < 20 {
+			in.copies += 1 + rng.IntN(3)
+		}
+		for c := 0; c < in.copies; c++ {
+			// Каждый вызов получает свою копию запроса, как при повторе по сети
+			req := *in.req
+			calls = append(calls, &req)
+		}
+	}
+	rng.Shuffle(len(calls), func(i, j int) { calls[i], calls[j] = calls[j], calls[i] })
+
+	service := walletservice.NewService(
+		&flakyRepository{ReadWriter: repo, rng: rand.New(rand.NewPCG(seed, ^seed))},
+		nil,
+		zap.NewNop(),
+		walletservice.WithDBRetry(retry.Policy{
+			MaxAttempts: 10,
+			BaseDelay:   100 * time.Microsecond,
+			MaxDelay:    2 * time.Millisecond,
+			Jitter:      0.5,
+		}),
+	)
+
+	outcomes := fire(t, service, calls, workers)
+
+	for _, walletUUID := range walletUUIDs {
+		checkLedger(t, repo, walletUUID, opening[walletUUID], outcomes)
+	}
+	checkOperations(t, repo, outcomes)
+}
+
+// fire sends the calls from workers goroutines and collects what each
+// reference ID got back.
+func fire(t *testing.T, service *walletservice.Service, calls []*types.WalletUpdateRequest, workers int) map[string]*outcome {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		next     int
+		outcomes = make(map[string]*outcome)
+	)
+	for _, req := range calls {
+		outcomes[req.ReferenceID] = &outcome{}
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				if next == len(calls) {
+					mu.Unlock()
+					return
+				}
+				req := calls[next]
+				next++
+				mu.Unlock()
+
+				fee, err := service.UpdateBalance(context.Background(), req)
+
+				mu.Lock()
+				if err == nil {
+					outcomes[req.ReferenceID].successes++
+					outcomes[req.ReferenceID].fee = fee.Total
+				} else if !expected(err) {
+					t.Errorf("%s %d (reference_id %s): unexpected error: %v", req.Operation, req.Amount, req.ReferenceID, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// expected reports whether err is one a caller may legitimately get: a
+// duplicate or exhausted retries (409) or a rejected withdrawal (400).
+func expected(err error) bool {
+	var httpErr types.HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.Code == http.StatusConflict || httpErr.Code == http.StatusBadRequest
+}
+
+// statement collects the statement of a wallet.
+type statement struct {
+	opening int
+	entries []types.StatementEntry
+	closing int
+}
+
+func (s *statement) WriteOpening(balance int) error {
+	s.opening = balance
+	return nil
+}
+
+func (s *statement) WriteEntry(entry *types.StatementEntry) error {
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+func (s *statement) WriteClosing(balance int) error {
+	s.closing = balance
+	return nil
+}
+
+func checkLedger(t *testing.T, repo walletservice.ReadWriter, walletUUID string, opening *types.WalletUpdateRequest, outcomes map[string]*outcome) {
+	t.Helper()
+	ctx := context.Background()
+
+	var st statement
+	require.NoError(t, repo.StreamStatement(ctx, walletUUID, time.Time{}, time.Now().Add(time.Hour), &st))
+	assert.Zero(t, st.opening, "wallet %s: statement must start from an empty wallet", walletUUID)
+
+	var (
+		running      int
+		deposits     int
+		withdrawals  int
+		fees         int
+		applied      = make(map[string]bool)
+		sawOpening   bool
+		successCount int
+	)
+	for _, entry := range st.entries {
+		assert.False(t, applied[entry.ReferenceID], "wallet %s: reference_id %s applied twice", walletUUID, entry.ReferenceID)
+		applied[entry.ReferenceID] = true
+
+		switch entry.Operation {
+		case types.OperationTypeDeposit:
+			running += entry.Amount
+			deposits += entry.Amount
+		case types.OperationTypeWithdraw:
+			running -= entry.Amount + entry.Fee
+			withdrawals += entry.Amount
+			fees += entry.Fee
+		default:
+			t.Errorf("wallet %s: unexpected %s operation %s", walletUUID, entry.Operation, entry.ReferenceID)
+		}
+
+		assert.Equal(t, running, entry.Balance, "wallet %s: balance after %s does not add up", walletUUID, entry.ReferenceID)
+		assert.GreaterOrEqual(t, entry.Balance, 0, "wallet %s: balance went negative after %s", walletUUID, entry.ReferenceID)
+
+		if entry.ReferenceID == opening.ReferenceID {
+			sawOpening = true
+			continue
+		}
+		out, ok := outcomes[entry.ReferenceID]
+		if !assert.True(t, ok, "wallet %s: operation %s was never sent", walletUUID, entry.ReferenceID) {
+			continue
+		}
+		assert.Equal(t, 1, out.successes, "wallet %s: reference_id %s applied, but %d calls succeeded", walletUUID, entry.ReferenceID, out.successes)
+		assert.Equal(t, out.fee, entry.Fee, "wallet %s: fee of %s differs from the one returned", walletUUID, entry.ReferenceID)
+		successCount++
+	}
+	assert.True(t, sawOpening, "wallet %s: opening deposit is missing", walletUUID)
+
+	funds, err := repo.GetBalance(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, st.closing, funds.Balance, "wallet %s: statement closing balance differs from the balance", walletUUID)
+	assert.Equal(t, deposits-withdrawals-fees, funds.Balance,
+		"wallet %s: balance is not opening plus deposits minus withdrawals and fees", walletUUID)
+	assert.GreaterOrEqual(t, funds.Balance, 0, "wallet %s: balance is negative", walletUUID)
+
+	t.Logf("wallet %s: %d operations applied, balance %d", walletUUID, successCount, funds.Balance)
+}
+
+// checkOperations makes sure every call that succeeded left an applied
+// operation behind, no reference ID succeeded twice and nothing is PENDING.
+func checkOperations(t *testing.T, repo walletservice.ReadWriter, outcomes map[string]*outcome) {
+	t.Helper()
+
+	for referenceID, out := range outcomes {
+		assert.LessOrEqual(t, out.successes, 1, "reference_id %s succeeded %d times", referenceID, out.successes)
+
+		op, err := repo.GetOperation(context.Background(), referenceID)
+		if errors.Is(err, types.ErrOperationNotFound) {
+			assert.Zero(t, out.successes, "reference_id %s succeeded, but there is no operation", referenceID)
+			continue
+		}
+		require.NoError(t, err)
+		assert.NotEqual(t, statusPending, op.Status, "reference_id %s is stuck PENDING", referenceID)
+	}
+}
+
+// flakyRepository fails some balance updates the way a concurrent
+// transaction would, before they reach the repository, and yields between
+// the idempotency check and the update to shuffle the interleavings.
+type flakyRepository struct {
+	walletservice.ReadWriter
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+var concurrentErrors = []error{
+	types.ErrConcurrentUpdate,
+	types.ErrDeadlock,
+	types.ErrSerializationFailure,
+}
+
+func (r *flakyRepository) CheckOperationExists(ctx context.Context, referenceID string) (bool, error) {
+	exists, err := r.ReadWriter.CheckOperationExists(ctx, referenceID)
+	runtime.Gosched()
+	return exists, err
+}
+
+func (r *flakyRepository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
+	r.mu.Lock()
+	fail := r.rng.IntN(100) < 10
+	injected := concurrentErrors[r.rng.IntN(len(concurrentErrors))]
+	r.mu.Unlock()
+
+	if fail {
+		return types.Fee{}, injected
+	}
+	return r.ReadWriter.UpdateBalance(ctx, req)
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./internal/services/wallet/repo.go
+// Source: ./repo.go
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/artyomkorchagin/wallet-task/internal/types"
 	gomock "github.com/golang/mock/gomock"
@@ -50,11 +51,26 @@ func (mr *MockReaderMockRecorder) CheckOperationExists(ctx, referenceID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOperationExists", reflect.TypeOf((*MockReader)(nil).CheckOperationExists), ctx, referenceID)
 }
 
+// CountFailedOperations mocks base method.
+func (m *MockReader) CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFailedOperations", ctx, since)
+	ret0, _ := ret[0].([]types.FailureCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFailedOperations indicates an expected call of CountFailedOperations.
+func (mr *MockReaderMockRecorder) CountFailedOperations(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFailedOperations", reflect.TypeOf((*MockReader)(nil).CountFailedOperations), ctx, since)
+}
+
 // GetBalance mocks base method.
-func (m *MockReader) GetBalance(ctx context.Context, walletUUID string) (int, error) {
+func (m *MockReader) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, walletUUID)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(types.Funds)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -65,6 +81,155 @@ func (mr *MockReaderMockRecorder) GetBalance(ctx, walletUUID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockReader)(nil).GetBalance), ctx, walletUUID)
 }
 
+// GetBalanceAt mocks base method.
+func (m *MockReader) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletUUID, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockReaderMockRecorder) GetBalanceAt(ctx, walletUUID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockReader)(nil).GetBalanceAt), ctx, walletUUID, at)
+}
+
+// GetBalances mocks base method.
+func (m *MockReader) GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalances", ctx, walletUUIDs)
+	ret0, _ := ret[0].(map[string]types.Funds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalances indicates an expected call of GetBalances.
+func (mr *MockReaderMockRecorder) GetBalances(ctx, walletUUIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalances", reflect.TypeOf((*MockReader)(nil).GetBalances), ctx, walletUUIDs)
+}
+
+// GetOperation mocks base method.
+func (m *MockReader) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperation", ctx, referenceID)
+	ret0, _ := ret[0].(*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperation indicates an expected call of GetOperation.
+func (mr *MockReaderMockRecorder) GetOperation(ctx, referenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperation", reflect.TypeOf((*MockReader)(nil).GetOperation), ctx, referenceID)
+}
+
+// GetOverdraftWallets mocks base method.
+func (m *MockReader) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftWallets", ctx)
+	ret0, _ := ret[0].([]types.OverdraftWallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftWallets indicates an expected call of GetOverdraftWallets.
+func (mr *MockReaderMockRecorder) GetOverdraftWallets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftWallets", reflect.TypeOf((*MockReader)(nil).GetOverdraftWallets), ctx)
+}
+
+// GetWallet mocks base method.
+func (m *MockReader) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletUUID)
+	ret0, _ := ret[0].(*types.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockReaderMockRecorder) GetWallet(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockReader)(nil).GetWallet), ctx, walletUUID)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockReader) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletUUID)
+	ret0, _ := ret[0].(*types.WalletLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockReaderMockRecorder) GetWalletLimits(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockReader)(nil).GetWalletLimits), ctx, walletUUID)
+}
+
+// GetWalletOwners mocks base method.
+func (m *MockReader) GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletOwners", ctx, walletUUIDs)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletOwners indicates an expected call of GetWalletOwners.
+func (mr *MockReaderMockRecorder) GetWalletOwners(ctx, walletUUIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletOwners", reflect.TypeOf((*MockReader)(nil).GetWalletOwners), ctx, walletUUIDs)
+}
+
+// ListOperations mocks base method.
+func (m *MockReader) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOperations", ctx, walletUUID, filter)
+	ret0, _ := ret[0].([]*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOperations indicates an expected call of ListOperations.
+func (mr *MockReaderMockRecorder) ListOperations(ctx, walletUUID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOperations", reflect.TypeOf((*MockReader)(nil).ListOperations), ctx, walletUUID, filter)
+}
+
+// ReconcileBalances mocks base method.
+func (m *MockReader) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileBalances", ctx)
+	ret0, _ := ret[0].([]types.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileBalances indicates an expected call of ReconcileBalances.
+func (mr *MockReaderMockRecorder) ReconcileBalances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileBalances", reflect.TypeOf((*MockReader)(nil).ReconcileBalances), ctx)
+}
+
+// StreamStatement mocks base method.
+func (m *MockReader) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", ctx, walletUUID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockReaderMockRecorder) StreamStatement(ctx, walletUUID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockReader)(nil).StreamStatement), ctx, walletUUID, from, to, w)
+}
+
 // MockWriter is a mock of Writer interface.
 type MockWriter struct {
 	ctrl     *gomock.Controller
@@ -88,20 +253,166 @@ func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
 	return m.recorder
 }
 
-// UpdateBalance mocks base method.
-func (m *MockWriter) UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) error {
+// ClaimQueuedOperations mocks base method.
+func (m *MockWriter) ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", ctx, wallet)
+	ret := m.ctrl.Call(m, "ClaimQueuedOperations", ctx, limit, lease)
+	ret0, _ := ret[0].([]*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimQueuedOperations indicates an expected call of ClaimQueuedOperations.
+func (mr *MockWriterMockRecorder) ClaimQueuedOperations(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQueuedOperations", reflect.TypeOf((*MockWriter)(nil).ClaimQueuedOperations), ctx, limit, lease)
+}
+
+// CreateWallet mocks base method.
+func (m *MockWriter) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, owner, creditLimit)
+	ret0, _ := ret[0].(*types.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockWriterMockRecorder) CreateWallet(ctx, owner, creditLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWriter)(nil).CreateWallet), ctx, owner, creditLimit)
+}
+
+// EnqueueOperation mocks base method.
+func (m *MockWriter) EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueOperation", ctx, req)
+	ret0, _ := ret[0].(*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueOperation indicates an expected call of EnqueueOperation.
+func (mr *MockWriterMockRecorder) EnqueueOperation(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueOperation", reflect.TypeOf((*MockWriter)(nil).EnqueueOperation), ctx, req)
+}
+
+// FailOperation mocks base method.
+func (m *MockWriter) FailOperation(ctx context.Context, referenceID string, cause error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailOperation", ctx, referenceID, cause)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailOperation indicates an expected call of FailOperation.
+func (mr *MockWriterMockRecorder) FailOperation(ctx, referenceID, cause interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOperation", reflect.TypeOf((*MockWriter)(nil).FailOperation), ctx, referenceID, cause)
+}
+
+// SetCreditLimit mocks base method.
+func (m *MockWriter) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCreditLimit", ctx, walletUUID, creditLimit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SetCreditLimit indicates an expected call of SetCreditLimit.
+func (mr *MockWriterMockRecorder) SetCreditLimit(ctx, walletUUID, creditLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCreditLimit", reflect.TypeOf((*MockWriter)(nil).SetCreditLimit), ctx, walletUUID, creditLimit)
+}
+
+// SetWalletFrozen mocks base method.
+func (m *MockWriter) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletFrozen", ctx, walletUUID, frozen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletFrozen indicates an expected call of SetWalletFrozen.
+func (mr *MockWriterMockRecorder) SetWalletFrozen(ctx, walletUUID, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletFrozen", reflect.TypeOf((*MockWriter)(nil).SetWalletFrozen), ctx, walletUUID, frozen)
+}
+
+// SetWalletLimits mocks base method.
+func (m *MockWriter) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimits", ctx, walletUUID, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimits indicates an expected call of SetWalletLimits.
+func (mr *MockWriterMockRecorder) SetWalletLimits(ctx, walletUUID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimits", reflect.TypeOf((*MockWriter)(nil).SetWalletLimits), ctx, walletUUID, update)
+}
+
+// SnapshotBalances mocks base method.
+func (m *MockWriter) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotBalances", ctx, day)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotBalances indicates an expected call of SnapshotBalances.
+func (mr *MockWriterMockRecorder) SnapshotBalances(ctx, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotBalances", reflect.TypeOf((*MockWriter)(nil).SnapshotBalances), ctx, day)
+}
+
+// SweepPendingOperations mocks base method.
+func (m *MockWriter) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepPendingOperations", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepPendingOperations indicates an expected call of SweepPendingOperations.
+func (mr *MockWriterMockRecorder) SweepPendingOperations(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepPendingOperations", reflect.TypeOf((*MockWriter)(nil).SweepPendingOperations), ctx, olderThan)
+}
+
+// UpdateBalance mocks base method.
+func (m *MockWriter) UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) (types.Fee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, wallet)
+	ret0, _ := ret[0].(types.Fee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateBalance indicates an expected call of UpdateBalance.
 func (mr *MockWriterMockRecorder) UpdateBalance(ctx, wallet interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWriter)(nil).UpdateBalance), ctx, wallet)
 }
 
+// UpdateBalanceBatch mocks base method.
+func (m *MockWriter) UpdateBalanceBatch(ctx context.Context, wallets []*types.WalletUpdateRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalanceBatch", ctx, wallets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBalanceBatch indicates an expected call of UpdateBalanceBatch.
+func (mr *MockWriterMockRecorder) UpdateBalanceBatch(ctx, wallets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceBatch", reflect.TypeOf((*MockWriter)(nil).UpdateBalanceBatch), ctx, wallets)
+}
+
 // MockReadWriter is a mock of ReadWriter interface.
 type MockReadWriter struct {
 	ctrl     *gomock.Controller
@@ -140,11 +451,85 @@ func (mr *MockReadWriterMockRecorder) CheckOperationExists(ctx, referenceID inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOperationExists", reflect.TypeOf((*MockReadWriter)(nil).CheckOperationExists), ctx, referenceID)
 }
 
+// ClaimQueuedOperations mocks base method.
+func (m *MockReadWriter) ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimQueuedOperations", ctx, limit, lease)
+	ret0, _ := ret[0].([]*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimQueuedOperations indicates an expected call of ClaimQueuedOperations.
+func (mr *MockReadWriterMockRecorder) ClaimQueuedOperations(ctx, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQueuedOperations", reflect.TypeOf((*MockReadWriter)(nil).ClaimQueuedOperations), ctx, limit, lease)
+}
+
+// CountFailedOperations mocks base method.
+func (m *MockReadWriter) CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFailedOperations", ctx, since)
+	ret0, _ := ret[0].([]types.FailureCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFailedOperations indicates an expected call of CountFailedOperations.
+func (mr *MockReadWriterMockRecorder) CountFailedOperations(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFailedOperations", reflect.TypeOf((*MockReadWriter)(nil).CountFailedOperations), ctx, since)
+}
+
+// CreateWallet mocks base method.
+func (m *MockReadWriter) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, owner, creditLimit)
+	ret0, _ := ret[0].(*types.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockReadWriterMockRecorder) CreateWallet(ctx, owner, creditLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockReadWriter)(nil).CreateWallet), ctx, owner, creditLimit)
+}
+
+// EnqueueOperation mocks base method.
+func (m *MockReadWriter) EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueOperation", ctx, req)
+	ret0, _ := ret[0].(*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueOperation indicates an expected call of EnqueueOperation.
+func (mr *MockReadWriterMockRecorder) EnqueueOperation(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueOperation", reflect.TypeOf((*MockReadWriter)(nil).EnqueueOperation), ctx, req)
+}
+
+// FailOperation mocks base method.
+func (m *MockReadWriter) FailOperation(ctx context.Context, referenceID string, cause error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailOperation", ctx, referenceID, cause)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailOperation indicates an expected call of FailOperation.
+func (mr *MockReadWriterMockRecorder) FailOperation(ctx, referenceID, cause interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOperation", reflect.TypeOf((*MockReadWriter)(nil).FailOperation), ctx, referenceID, cause)
+}
+
 // GetBalance mocks base method.
-func (m *MockReadWriter) GetBalance(ctx context.Context, walletUUID string) (int, error) {
+func (m *MockReadWriter) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, walletUUID)
-	ret0, _ := ret[0].(int)
+	ret0, _ := ret[0].(types.Funds)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -155,16 +540,252 @@ func (mr *MockReadWriterMockRecorder) GetBalance(ctx, walletUUID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockReadWriter)(nil).GetBalance), ctx, walletUUID)
 }
 
-// UpdateBalance mocks base method.
-func (m *MockReadWriter) UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) error {
+// GetBalanceAt mocks base method.
+func (m *MockReadWriter) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", ctx, wallet)
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletUUID, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockReadWriterMockRecorder) GetBalanceAt(ctx, walletUUID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockReadWriter)(nil).GetBalanceAt), ctx, walletUUID, at)
+}
+
+// GetBalances mocks base method.
+func (m *MockReadWriter) GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalances", ctx, walletUUIDs)
+	ret0, _ := ret[0].(map[string]types.Funds)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalances indicates an expected call of GetBalances.
+func (mr *MockReadWriterMockRecorder) GetBalances(ctx, walletUUIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalances", reflect.TypeOf((*MockReadWriter)(nil).GetBalances), ctx, walletUUIDs)
+}
+
+// GetOperation mocks base method.
+func (m *MockReadWriter) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperation", ctx, referenceID)
+	ret0, _ := ret[0].(*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperation indicates an expected call of GetOperation.
+func (mr *MockReadWriterMockRecorder) GetOperation(ctx, referenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperation", reflect.TypeOf((*MockReadWriter)(nil).GetOperation), ctx, referenceID)
+}
+
+// GetOverdraftWallets mocks base method.
+func (m *MockReadWriter) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftWallets", ctx)
+	ret0, _ := ret[0].([]types.OverdraftWallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftWallets indicates an expected call of GetOverdraftWallets.
+func (mr *MockReadWriterMockRecorder) GetOverdraftWallets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftWallets", reflect.TypeOf((*MockReadWriter)(nil).GetOverdraftWallets), ctx)
+}
+
+// GetWallet mocks base method.
+func (m *MockReadWriter) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletUUID)
+	ret0, _ := ret[0].(*types.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockReadWriterMockRecorder) GetWallet(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockReadWriter)(nil).GetWallet), ctx, walletUUID)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockReadWriter) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletUUID)
+	ret0, _ := ret[0].(*types.WalletLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockReadWriterMockRecorder) GetWalletLimits(ctx, walletUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockReadWriter)(nil).GetWalletLimits), ctx, walletUUID)
+}
+
+// GetWalletOwners mocks base method.
+func (m *MockReadWriter) GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletOwners", ctx, walletUUIDs)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletOwners indicates an expected call of GetWalletOwners.
+func (mr *MockReadWriterMockRecorder) GetWalletOwners(ctx, walletUUIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletOwners", reflect.TypeOf((*MockReadWriter)(nil).GetWalletOwners), ctx, walletUUIDs)
+}
+
+// ListOperations mocks base method.
+func (m *MockReadWriter) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOperations", ctx, walletUUID, filter)
+	ret0, _ := ret[0].([]*types.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOperations indicates an expected call of ListOperations.
+func (mr *MockReadWriterMockRecorder) ListOperations(ctx, walletUUID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOperations", reflect.TypeOf((*MockReadWriter)(nil).ListOperations), ctx, walletUUID, filter)
+}
+
+// ReconcileBalances mocks base method.
+func (m *MockReadWriter) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileBalances", ctx)
+	ret0, _ := ret[0].([]types.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileBalances indicates an expected call of ReconcileBalances.
+func (mr *MockReadWriterMockRecorder) ReconcileBalances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileBalances", reflect.TypeOf((*MockReadWriter)(nil).ReconcileBalances), ctx)
+}
+
+// SetCreditLimit mocks base method.
+func (m *MockReadWriter) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCreditLimit", ctx, walletUUID, creditLimit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCreditLimit indicates an expected call of SetCreditLimit.
+func (mr *MockReadWriterMockRecorder) SetCreditLimit(ctx, walletUUID, creditLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCreditLimit", reflect.TypeOf((*MockReadWriter)(nil).SetCreditLimit), ctx, walletUUID, creditLimit)
+}
+
+// SetWalletFrozen mocks base method.
+func (m *MockReadWriter) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletFrozen", ctx, walletUUID, frozen)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SetWalletFrozen indicates an expected call of SetWalletFrozen.
+func (mr *MockReadWriterMockRecorder) SetWalletFrozen(ctx, walletUUID, frozen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletFrozen", reflect.TypeOf((*MockReadWriter)(nil).SetWalletFrozen), ctx, walletUUID, frozen)
+}
+
+// SetWalletLimits mocks base method.
+func (m *MockReadWriter) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimits", ctx, walletUUID, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimits indicates an expected call of SetWalletLimits.
+func (mr *MockReadWriterMockRecorder) SetWalletLimits(ctx, walletUUID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimits", reflect.TypeOf((*MockReadWriter)(nil).SetWalletLimits), ctx, walletUUID, update)
+}
+
+// SnapshotBalances mocks base method.
+func (m *MockReadWriter) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotBalances", ctx, day)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotBalances indicates an expected call of SnapshotBalances.
+func (mr *MockReadWriterMockRecorder) SnapshotBalances(ctx, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotBalances", reflect.TypeOf((*MockReadWriter)(nil).SnapshotBalances), ctx, day)
+}
+
+// StreamStatement mocks base method.
+func (m *MockReadWriter) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", ctx, walletUUID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockReadWriterMockRecorder) StreamStatement(ctx, walletUUID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockReadWriter)(nil).StreamStatement), ctx, walletUUID, from, to, w)
+}
+
+// SweepPendingOperations mocks base method.
+func (m *MockReadWriter) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepPendingOperations", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepPendingOperations indicates an expected call of SweepPendingOperations.
+func (mr *MockReadWriterMockRecorder) SweepPendingOperations(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepPendingOperations", reflect.TypeOf((*MockReadWriter)(nil).SweepPendingOperations), ctx, olderThan)
+}
+
+// UpdateBalance mocks base method.
+func (m *MockReadWriter) UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) (types.Fee, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, wallet)
+	ret0, _ := ret[0].(types.Fee)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateBalance indicates an expected call of UpdateBalance.
 func (mr *MockReadWriterMockRecorder) UpdateBalance(ctx, wallet interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockReadWriter)(nil).UpdateBalance), ctx, wallet)
 }
+
+// UpdateBalanceBatch mocks base method.
+func (m *MockReadWriter) UpdateBalanceBatch(ctx context.Context, wallets []*types.WalletUpdateRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalanceBatch", ctx, wallets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBalanceBatch indicates an expected call of UpdateBalanceBatch.
+func (mr *MockReadWriterMockRecorder) UpdateBalanceBatch(ctx, wallets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceBatch", reflect.TypeOf((*MockReadWriter)(nil).UpdateBalanceBatch), ctx, wallets)
+}
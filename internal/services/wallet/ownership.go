@@ -0,0 +1,75 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// checkOwnership fails with 404 if any of the wallets is not owned by the user
+// the request is made for, so that other users' wallets look nonexistent.
+// Requests made without an owner are not restricted.
+func (s *Service) checkOwnership(ctx context.Context, logger *zap.Logger, walletUUIDs ...string) error {
+	hidden, err := s.hiddenWallets(ctx, logger, walletUUIDs)
+	if err != nil {
+		return err
+	}
+	if len(hidden) > 0 {
+		return types.ErrNotFound(types.ErrWalletNotFound)
+	}
+	return nil
+}
+
+// hiddenWallets returns the wallets among walletUUIDs that the caller must not
+// see, keyed as given. It is empty for requests made without an owner.
+func (s *Service) hiddenWallets(ctx context.Context, logger *zap.Logger, walletUUIDs []string) (map[string]struct{}, error) {
+	ownerID, ok := types.OwnerFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	// Владельцы приходят по каноническому UUID, а клиент может прислать его в
+	// другом регистре или формате
+	canonical := make([]string, len(walletUUIDs))
+	for i, walletUUID := range walletUUIDs {
+		canonical[i] = canonicalUUID(walletUUID)
+	}
+
+	owners, err := s.repo.GetWalletOwners(ctx, canonical)
+	if err != nil {
+		logger.Error("failed to get wallet owners",
+			zap.Error(err))
+		if errors.Is(err, types.ErrQueryCanceled) {
+			return nil, types.ErrServiceUnavailable(err)
+		}
+		return nil, types.ErrInternalServerError(err)
+	}
+
+	hidden := make(map[string]struct{})
+	for i, walletUUID := range walletUUIDs {
+		if owners[canonical[i]] != ownerID {
+			hidden[walletUUID] = struct{}{}
+		}
+	}
+
+	if len(hidden) > 0 {
+		logger.Warn("wallets are not owned by the caller",
+			zap.String("owner_id", ownerID),
+			zap.Int("count", len(hidden)))
+	}
+
+	return hidden, nil
+}
+
+// canonicalUUID returns the lowercase hyphenated form of walletUUID, or
+// walletUUID itself if it is not a UUID.
+func canonicalUUID(walletUUID string) string {
+	parsed, err := uuid.Parse(walletUUID)
+	if err != nil {
+		return walletUUID
+	}
+	return parsed.String()
+}
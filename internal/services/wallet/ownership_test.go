@@ -0,0 +1,126 @@
+package walletservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOwnership(t *testing.T) {
+	owned := uuid.New().String()
+	foreign := uuid.New().String()
+	ctx := types.ContextWithOwner(context.Background(), "user-1")
+
+	setup := func(t *testing.T) (*walletservice.Service, *MockRepository, redismock.ClientMock) {
+		redisMockClient, redisMock := redismock.NewClientMock()
+		repo := new(MockRepository)
+		return walletservice.NewService(repo, redisMockClient, zaptest.NewLogger(t)), repo, redisMock
+	}
+
+	t.Run("GetBalance of own wallet", func(t *testing.T) {
+		service, repo, redisMock := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{owned}).Return(map[string]string{owned: "user-1"}, nil)
+		redisMock.ExpectGet("wallet:balance:" + owned).SetVal("500:0")
+
+		balance, err := service.GetBalance(ctx, owned)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewFunds(500, 0), balance)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("GetBalance of own wallet in uppercase", func(t *testing.T) {
+		service, repo, redisMock := setup(t)
+		upper := strings.ToUpper(owned)
+		repo.On("GetWalletOwners", ctx, []string{owned}).Return(map[string]string{owned: "user-1"}, nil)
+		redisMock.ExpectGet("wallet:balance:" + upper).SetVal("500:0")
+
+		balance, err := service.GetBalance(ctx, upper)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewFunds(500, 0), balance)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("GetBalance of foreign wallet looks missing", func(t *testing.T) {
+		service, repo, redisMock := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{foreign}).Return(map[string]string{foreign: "user-2"}, nil)
+
+		_, err := service.GetBalance(ctx, foreign)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		assert.ErrorIs(t, err, types.ErrWalletNotFound)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+	})
+
+	t.Run("GetBalance of wallet without owner looks missing", func(t *testing.T) {
+		service, repo, _ := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{foreign}).Return(map[string]string{}, nil)
+
+		_, err := service.GetBalance(ctx, foreign)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+	})
+
+	t.Run("UpdateBalance of foreign wallet looks missing", func(t *testing.T) {
+		service, repo, _ := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{foreign}).Return(map[string]string{foreign: "user-2"}, nil)
+
+		_, err := service.UpdateBalance(ctx, types.NewWalletUpdateRequest(foreign, types.OperationTypeWithdraw, 100, uuid.New().String()))
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+		repo.AssertNotCalled(t, "CheckOperationExists", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner lookup fails", func(t *testing.T) {
+		service, repo, _ := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{owned}).Return(nil, errors.New("connection refused"))
+
+		_, err := service.GetBalance(ctx, owned)
+		require.Error(t, err)
+		assert.Equal(t, 500, err.(types.HTTPError).Code)
+	})
+
+	t.Run("GetBalances hides foreign wallets", func(t *testing.T) {
+		service, repo, redisMock := setup(t)
+		repo.On("GetWalletOwners", ctx, []string{owned, foreign}).
+			Return(map[string]string{owned: "user-1", foreign: "user-2"}, nil)
+		redisMock.ExpectMGet("wallet:balance:" + owned).SetVal([]interface{}{"500:0"})
+
+		balances, err := service.GetBalances(ctx, []string{owned, foreign})
+		require.NoError(t, err)
+		assert.Equal(t, []types.WalletBalance{
+			{WalletUUID: owned, Funds: types.NewFunds(500, 0), Found: true},
+			{WalletUUID: foreign, Found: false},
+		}, balances)
+		repo.AssertNotCalled(t, "GetBalances", mock.Anything, mock.Anything)
+	})
+
+	t.Run("atomic batch with foreign wallet", func(t *testing.T) {
+		service, repo, _ := setup(t)
+		reqs := []*types.WalletUpdateRequest{
+			types.NewWalletUpdateRequest(owned, types.OperationTypeDeposit, 100, uuid.New().String()),
+			types.NewWalletUpdateRequest(foreign, types.OperationTypeWithdraw, 100, uuid.New().String()),
+		}
+		repo.On("GetWalletOwners", ctx, []string{owned, foreign}).
+			Return(map[string]string{owned: "user-1", foreign: "user-2"}, nil)
+
+		_, err := service.UpdateBalanceBatch(ctx, true, reqs)
+		require.Error(t, err)
+		assert.Equal(t, 404, err.(types.HTTPError).Code)
+
+		var itemErr *types.BatchItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		repo.AssertNotCalled(t, "UpdateBalanceBatch", ctx, reqs)
+	})
+}
@@ -0,0 +1,692 @@
+// Package readwritertest is the conformance suite for implementations of
+// walletservice.ReadWriter. Every implementation must pass it, so that the
+// service behaves the same whichever storage it runs on.
+//
+// The suite only relies on the wallets it creates itself, so it can run
+// against a database shared with other tests.
+package readwritertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	walletservice "github.com/artyomkorchagin/wallet-task/internal/services/wallet"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	statusPending = "PENDING"
+	statusApplied = "APPLIED"
+	statusFailed  = "FAILED"
+)
+
+// Run runs the suite. newRepo is called once per test and may return the same
+// repository every time.
+func Run(t *testing.T, newRepo func(t *testing.T) walletservice.ReadWriter) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo walletservice.ReadWriter)
+	}{
+		{"wallets", testWallets},
+		{"deposit and withdraw", testDepositWithdraw},
+		{"insufficient funds", testInsufficientFunds},
+		{"idempotency", testIdempotency},
+		{"not found", testNotFound},
+		{"overdraft", testOverdraft},
+		{"frozen wallet", testFrozen},
+		{"limits", testLimits},
+		{"batch", testBatch},
+		{"balance history", testBalanceHistory},
+		{"statement", testStatement},
+		{"maintenance", testMaintenance},
+		{"async queue", testAsyncQueue},
+		{"operation details", testOperationDetails},
+		{"concurrent writers", testConcurrentWriters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+func newWallet(t *testing.T, repo walletservice.ReadWriter, owner string, creditLimit int) string {
+	t.Helper()
+	wallet, err := repo.CreateWallet(context.Background(), owner, creditLimit)
+	require.NoError(t, err)
+	return wallet.WalletUUID
+}
+
+func apply(t *testing.T, repo walletservice.ReadWriter, walletUUID, operation string, amount int) string {
+	t.Helper()
+	referenceID := uuid.NewString()
+	_, err := repo.UpdateBalance(context.Background(),
+		types.NewWalletUpdateRequest(walletUUID, operation, amount, referenceID))
+	require.NoError(t, err)
+	return referenceID
+}
+
+// tick makes sure the next operation gets a later timestamp than the
+// previous one, which time-based queries rely on.
+func tick() {
+	time.Sleep(2 * time.Millisecond)
+}
+
+func balance(t *testing.T, repo walletservice.ReadWriter, walletUUID string) int {
+	t.Helper()
+	funds, err := repo.GetBalance(context.Background(), walletUUID)
+	require.NoError(t, err)
+	return funds.Balance
+}
+
+func testWallets(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	owned := newWallet(t, repo, "user-"+uuid.NewString(), 100)
+	unowned := newWallet(t, repo, "", 0)
+
+	wallet, err := repo.GetWallet(ctx, owned)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewFunds(0, 100), wallet.Funds)
+	assert.NotEmpty(t, wallet.Owner)
+	assert.False(t, wallet.Frozen)
+	assert.False(t, wallet.CreatedAt.IsZero())
+
+	funds, err := repo.GetBalance(ctx, unowned)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewFunds(0, 0), funds)
+
+	missing := uuid.NewString()
+	balances, err := repo.GetBalances(ctx, []string{owned, unowned, missing})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.Funds{
+		owned:   types.NewFunds(0, 100),
+		unowned: types.NewFunds(0, 0),
+	}, balances)
+
+	owners, err := repo.GetWalletOwners(ctx, []string{owned, unowned, missing})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{owned: wallet.Owner}, owners)
+
+	_, err = repo.GetWallet(ctx, types.FeeWalletUUID)
+	assert.NoError(t, err, "the fee wallet must exist")
+}
+
+func testDepositWithdraw(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+
+	deposit := apply(t, repo, walletUUID, types.OperationTypeDeposit, 500)
+	withdraw := apply(t, repo, walletUUID, types.OperationTypeWithdraw, 200)
+	assert.Equal(t, 300, balance(t, repo, walletUUID))
+
+	op, err := repo.GetOperation(ctx, deposit)
+	require.NoError(t, err)
+	assert.Equal(t, walletUUID, op.WalletUUID)
+	assert.Equal(t, types.OperationTypeDeposit, op.Operation)
+	assert.Equal(t, 500, op.Amount)
+	assert.Equal(t, "APPLIED", op.Status)
+	require.NotNil(t, op.BalanceAfter)
+	assert.Equal(t, 500, *op.BalanceAfter)
+	assert.NotNil(t, op.AppliedAt)
+
+	next, err := repo.GetOperation(ctx, withdraw)
+	require.NoError(t, err)
+	require.NotNil(t, next.BalanceAfter)
+	assert.Equal(t, 300, *next.BalanceAfter)
+	assert.Greater(t, next.ID, op.ID)
+
+	exists, err := repo.CheckOperationExists(ctx, withdraw)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.CheckOperationExists(ctx, uuid.NewString())
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testInsufficientFunds(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+
+	referenceID := uuid.NewString()
+	_, err := repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 101, referenceID))
+	assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+
+	// Отклонённая операция не занимает reference_id
+	exists, err := repo.CheckOperationExists(ctx, referenceID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 100, referenceID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, balance(t, repo, walletUUID))
+}
+
+func testIdempotency(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	referenceID := apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+
+	_, err := repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 100, referenceID))
+	assert.ErrorIs(t, err, types.ErrOperationExists)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+}
+
+func testNotFound(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	missing := uuid.NewString()
+
+	_, err := repo.GetBalance(ctx, missing)
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	_, err = repo.GetWallet(ctx, missing)
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	_, err = repo.GetWalletLimits(ctx, missing)
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	_, err = repo.GetBalanceAt(ctx, missing, time.Now())
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	assert.ErrorIs(t, repo.SetCreditLimit(ctx, missing, 10), types.ErrWalletNotFound)
+	assert.ErrorIs(t, repo.SetWalletFrozen(ctx, missing, true), types.ErrWalletNotFound)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(missing, types.OperationTypeDeposit, 100, uuid.NewString()))
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	_, err = repo.GetOperation(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, types.ErrOperationNotFound)
+}
+
+func testOverdraft(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 100)
+
+	apply(t, repo, walletUUID, types.OperationTypeWithdraw, 80)
+	funds, err := repo.GetBalance(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewFunds(-80, 100), funds)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 21, uuid.NewString()))
+	assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+
+	wallets, err := repo.GetOverdraftWallets(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, wallets, types.OverdraftWallet{WalletUUID: walletUUID, Funds: types.NewFunds(-80, 100)})
+	for i := 1; i < len(wallets); i++ {
+		assert.LessOrEqual(t, wallets[i-1].Balance, wallets[i].Balance, "deepest overdraft first")
+	}
+
+	assert.ErrorIs(t, repo.SetCreditLimit(ctx, walletUUID, 50), types.ErrInsufficientFunds)
+	require.NoError(t, repo.SetCreditLimit(ctx, walletUUID, 80))
+	funds, err = repo.GetBalance(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewFunds(-80, 80), funds)
+}
+
+func testFrozen(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+
+	require.NoError(t, repo.SetWalletFrozen(ctx, walletUUID, true))
+	wallet, err := repo.GetWallet(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.True(t, wallet.Frozen)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 10, uuid.NewString()))
+	assert.ErrorIs(t, err, types.ErrWalletFrozen)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+
+	require.NoError(t, repo.SetWalletFrozen(ctx, walletUUID, false))
+	apply(t, repo, walletUUID, types.OperationTypeWithdraw, 10)
+	assert.Equal(t, 90, balance(t, repo, walletUUID))
+}
+
+func testLimits(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+
+	limits, err := repo.GetWalletLimits(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, types.DefaultLimitTier, limits.Tier)
+	assert.Equal(t, types.LimitValues{}, limits.Overrides)
+
+	maxWithdrawal, daily, maxBalance := 100, 150, 1000
+	err = repo.SetWalletLimits(ctx, walletUUID, &types.WalletLimitsUpdate{
+		Tier: types.DefaultLimitTier,
+		Overrides: types.LimitValues{
+			MaxWithdrawal:   &maxWithdrawal,
+			DailyWithdrawal: &daily,
+			MaxBalance:      &maxBalance,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 1001, uuid.NewString()))
+	var limitErr *types.LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, types.LimitMaxBalance, limitErr.Limit)
+
+	apply(t, repo, walletUUID, types.OperationTypeDeposit, 1000)
+
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 101, uuid.NewString()))
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, types.LimitMaxWithdrawal, limitErr.Limit)
+
+	apply(t, repo, walletUUID, types.OperationTypeWithdraw, 100)
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 51, uuid.NewString()))
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, types.LimitDailyWithdrawal, limitErr.Limit)
+	assert.Equal(t, 151, limitErr.Value)
+	assert.ErrorIs(t, err, types.ErrLimitExceeded)
+
+	limits, err = repo.GetWalletLimits(ctx, walletUUID)
+	require.NoError(t, err)
+	assert.Equal(t, &maxWithdrawal, limits.Overrides.MaxWithdrawal)
+	assert.Equal(t, &maxWithdrawal, limits.Limits.MaxWithdrawal)
+	assert.Equal(t, 100, limits.DailyWithdrawn)
+	assert.Equal(t, 100, limits.MonthlyWithdrawn)
+
+	err = repo.SetWalletLimits(ctx, walletUUID, &types.WalletLimitsUpdate{Tier: "no-such-tier-" + uuid.NewString()[:8]})
+	assert.ErrorIs(t, err, types.ErrUnknownTier)
+
+	err = repo.SetWalletLimits(ctx, uuid.NewString(), &types.WalletLimitsUpdate{Tier: types.DefaultLimitTier})
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+}
+
+func testBatch(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletA := newWallet(t, repo, "", 0)
+	walletB := newWallet(t, repo, "", 0)
+	apply(t, repo, walletA, types.OperationTypeDeposit, 100)
+
+	failing := []*types.WalletUpdateRequest{
+		types.NewWalletUpdateRequest(walletB, types.OperationTypeDeposit, 50, uuid.NewString()),
+		types.NewWalletUpdateRequest(walletA, types.OperationTypeWithdraw, 150, uuid.NewString()),
+	}
+	err := repo.UpdateBalanceBatch(ctx, failing)
+	assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+	var itemErr *types.BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.Equal(t, failing[1].ReferenceID, itemErr.ReferenceID)
+
+	assert.Equal(t, 100, balance(t, repo, walletA))
+	assert.Equal(t, 0, balance(t, repo, walletB), "a failed batch must not apply anything")
+	exists, err := repo.CheckOperationExists(ctx, failing[0].ReferenceID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = repo.UpdateBalanceBatch(ctx, []*types.WalletUpdateRequest{
+		types.NewWalletUpdateRequest(walletB, types.OperationTypeDeposit, 50, uuid.NewString()),
+		types.NewWalletUpdateRequest(walletA, types.OperationTypeWithdraw, 30, uuid.NewString()),
+		types.NewWalletUpdateRequest(walletA, types.OperationTypeWithdraw, 30, uuid.NewString()),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 40, balance(t, repo, walletA))
+	assert.Equal(t, 50, balance(t, repo, walletB))
+}
+
+func testBalanceHistory(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	first := apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+	tick()
+	second := apply(t, repo, walletUUID, types.OperationTypeWithdraw, 30)
+
+	firstOp, err := repo.GetOperation(ctx, first)
+	require.NoError(t, err)
+	secondOp, err := repo.GetOperation(ctx, second)
+	require.NoError(t, err)
+
+	cases := []struct {
+		at   time.Time
+		want int
+	}{
+		{firstOp.AppliedAt.Add(-time.Microsecond), 0},
+		{*firstOp.AppliedAt, 100},
+		{*secondOp.AppliedAt, 70},
+		{secondOp.AppliedAt.Add(time.Hour), 70},
+	}
+	for _, c := range cases {
+		got, err := repo.GetBalanceAt(ctx, walletUUID, c.at)
+		require.NoError(t, err)
+		assert.Equal(t, c.want, got, "balance at %s", c.at)
+	}
+
+	// Снимок текущего дня не меняет исторические балансы
+	_, err = repo.SnapshotBalances(ctx, time.Now().UTC())
+	require.NoError(t, err)
+	for _, c := range cases {
+		got, err := repo.GetBalanceAt(ctx, walletUUID, c.at)
+		require.NoError(t, err)
+		assert.Equal(t, c.want, got, "balance at %s after snapshot", c.at)
+	}
+}
+
+type statementRecorder struct {
+	opening, closing int
+	entries          []types.StatementEntry
+}
+
+func (r *statementRecorder) WriteOpening(balance int) error {
+	r.opening = balance
+	return nil
+}
+
+func (r *statementRecorder) WriteEntry(entry *types.StatementEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *statementRecorder) WriteClosing(balance int) error {
+	r.closing = balance
+	return nil
+}
+
+func testStatement(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	first := apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+	tick()
+	second := apply(t, repo, walletUUID, types.OperationTypeWithdraw, 30)
+	tick()
+	third := apply(t, repo, walletUUID, types.OperationTypeDeposit, 5)
+
+	firstOp, err := repo.GetOperation(ctx, first)
+	require.NoError(t, err)
+	thirdOp, err := repo.GetOperation(ctx, third)
+	require.NoError(t, err)
+
+	var rec statementRecorder
+	require.NoError(t, repo.StreamStatement(ctx, walletUUID, *firstOp.AppliedAt, thirdOp.AppliedAt.Add(-time.Microsecond), &rec))
+	assert.Equal(t, 100, rec.opening)
+	assert.Equal(t, 70, rec.closing)
+	require.Len(t, rec.entries, 1)
+	assert.Equal(t, second, rec.entries[0].ReferenceID)
+	assert.Equal(t, types.OperationTypeWithdraw, rec.entries[0].Operation)
+	assert.Equal(t, 30, rec.entries[0].Amount)
+	assert.Equal(t, 70, rec.entries[0].Balance)
+
+	err = repo.StreamStatement(ctx, uuid.NewString(), time.Time{}, time.Now(), &statementRecorder{})
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+}
+
+func testMaintenance(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+
+	mismatches, err := repo.ReconcileBalances(ctx)
+	require.NoError(t, err)
+	for _, m := range mismatches {
+		assert.NotEqual(t, walletUUID, m.WalletUUID)
+	}
+
+	_, err = repo.SweepPendingOperations(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+}
+
+// testAsyncQueue walks queued operations through the states a worker moves
+// them through. Claims may pick up operations of other tests, only the ones
+// queued here are looked at.
+func testAsyncQueue(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+
+	enqueue := func(operation string, amount int) *types.WalletUpdateRequest {
+		t.Helper()
+		req := types.NewWalletUpdateRequest(walletUUID, operation, amount, uuid.NewString())
+		op, err := repo.EnqueueOperation(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, statusPending, op.Status)
+		assert.True(t, op.Async)
+		return req
+	}
+	status := func(referenceID string) *types.Operation {
+		t.Helper()
+		op, err := repo.GetOperation(ctx, referenceID)
+		require.NoError(t, err)
+		return op
+	}
+	claimed := func() map[string]*types.Operation {
+		t.Helper()
+		ops, err := repo.ClaimQueuedOperations(ctx, 1000, time.Minute)
+		require.NoError(t, err)
+		byRef := make(map[string]*types.Operation, len(ops))
+		for _, op := range ops {
+			byRef[op.ReferenceID] = op
+		}
+		return byRef
+	}
+
+	deposit := enqueue(types.OperationTypeDeposit, 100)
+	withdraw := enqueue(types.OperationTypeWithdraw, 500)
+	abandoned := enqueue(types.OperationTypeDeposit, 10)
+
+	_, err := repo.EnqueueOperation(ctx, deposit)
+	assert.ErrorIs(t, err, types.ErrOperationExists)
+	_, err = repo.EnqueueOperation(ctx,
+		types.NewWalletUpdateRequest(uuid.NewString(), types.OperationTypeDeposit, 100, uuid.NewString()))
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+
+	exists, err := repo.CheckOperationExists(ctx, deposit.ReferenceID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Zero(t, balance(t, repo, walletUUID))
+
+	queued := status(deposit.ReferenceID)
+	first := claimed()
+	for _, req := range []*types.WalletUpdateRequest{deposit, withdraw, abandoned} {
+		if assert.Contains(t, first, req.ReferenceID) {
+			assert.Equal(t, 1, first[req.ReferenceID].Attempts)
+			assert.Equal(t, req.Amount, first[req.ReferenceID].Amount)
+		}
+	}
+	assert.NotContains(t, claimed(), deposit.ReferenceID, "a leased operation must not be claimed again")
+
+	// Другая сумма под той же ссылкой не должна провести queued-операцию
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 999, deposit.ReferenceID))
+	assert.ErrorIs(t, err, types.ErrOperationExists)
+
+	_, err = repo.UpdateBalance(ctx, deposit)
+	require.NoError(t, err)
+	applied := status(deposit.ReferenceID)
+	assert.Equal(t, statusApplied, applied.Status)
+	assert.Equal(t, queued.ID, applied.ID)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+
+	_, err = repo.UpdateBalance(ctx, deposit)
+	assert.ErrorIs(t, err, types.ErrOperationExists)
+
+	_, err = repo.UpdateBalance(ctx, withdraw)
+	assert.ErrorIs(t, err, types.ErrInsufficientFunds)
+	rejected := status(withdraw.ReferenceID)
+	assert.Equal(t, statusFailed, rejected.Status)
+	assert.Equal(t, types.FailureInsufficientFunds, rejected.FailureCode)
+	assert.Equal(t, types.ErrInsufficientFunds.Error(), rejected.FailureMessage)
+
+	require.NoError(t, repo.FailOperation(ctx, abandoned.ReferenceID, context.DeadlineExceeded))
+	failed := status(abandoned.ReferenceID)
+	assert.Equal(t, statusFailed, failed.Status)
+	assert.Equal(t, types.FailureTimeout, failed.FailureCode)
+	assert.Equal(t, context.DeadlineExceeded.Error(), failed.FailureMessage)
+
+	require.NoError(t, repo.FailOperation(ctx, deposit.ReferenceID, context.DeadlineExceeded))
+	assert.Equal(t, statusApplied, status(deposit.ReferenceID).Status)
+	assert.Empty(t, status(deposit.ReferenceID).FailureCode)
+	assert.Equal(t, 100, balance(t, repo, walletUUID))
+
+	// Другие тесты могли провалить свои операции, поэтому проверяется нижняя граница
+	counts, err := repo.CountFailedOperations(ctx, rejected.CreatedAt.Add(-time.Minute))
+	require.NoError(t, err)
+	byCode := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byCode[c.Code] = c.Count
+	}
+	assert.GreaterOrEqual(t, byCode[types.FailureInsufficientFunds], int64(1))
+	assert.GreaterOrEqual(t, byCode[types.FailureTimeout], int64(1))
+
+	counts, err = repo.CountFailedOperations(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func testOperationDetails(t *testing.T, repo walletservice.ReadWriter) {
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	list := func(filter types.OperationFilter) []string {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = types.DefaultOperationsPage
+		}
+		ops, err := repo.ListOperations(ctx, walletUUID, filter)
+		require.NoError(t, err)
+		refs := make([]string, 0, len(ops))
+		for _, op := range ops {
+			refs = append(refs, op.ReferenceID)
+		}
+		return refs
+	}
+
+	plain := apply(t, repo, walletUUID, types.OperationTypeDeposit, 100)
+
+	invoice := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeDeposit, 50, uuid.NewString())
+	invoice.OperationDetails = types.OperationDetails{
+		Description: "invoice payment",
+		ExternalRef: "INV-1",
+		Metadata:    map[string]string{"order": "42", "channel": "web"},
+	}
+	_, err := repo.UpdateBalance(ctx, invoice)
+	require.NoError(t, err)
+
+	refund := types.NewWalletUpdateRequest(walletUUID, types.OperationTypeWithdraw, 20, uuid.NewString())
+	refund.OperationDetails = types.OperationDetails{
+		ExternalRef: "INV-1",
+		Metadata:    map[string]string{"order": "42", "reason": "refund"},
+	}
+	_, err = repo.EnqueueOperation(ctx, refund)
+	require.NoError(t, err)
+	// Воркер проводит операцию без деталей, они остаются от постановки в очередь
+	_, err = repo.UpdateBalance(ctx,
+		types.NewWalletUpdateRequest(walletUUID, refund.Operation, refund.Amount, refund.ReferenceID))
+	require.NoError(t, err)
+
+	op, err := repo.GetOperation(ctx, invoice.ReferenceID)
+	require.NoError(t, err)
+	assert.Equal(t, invoice.OperationDetails, op.OperationDetails)
+	op, err = repo.GetOperation(ctx, refund.ReferenceID)
+	require.NoError(t, err)
+	assert.Equal(t, statusApplied, op.Status)
+	assert.Equal(t, refund.OperationDetails, op.OperationDetails)
+	op, err = repo.GetOperation(ctx, plain)
+	require.NoError(t, err)
+	assert.Zero(t, op.OperationDetails)
+
+	assert.Equal(t, []string{refund.ReferenceID, invoice.ReferenceID, plain}, list(types.OperationFilter{}))
+	assert.Equal(t, []string{refund.ReferenceID, invoice.ReferenceID},
+		list(types.OperationFilter{ExternalRef: "INV-1"}))
+	assert.Empty(t, list(types.OperationFilter{ExternalRef: "INV-2"}))
+	assert.Equal(t, []string{refund.ReferenceID, invoice.ReferenceID},
+		list(types.OperationFilter{Metadata: map[string]string{"order": "42"}}))
+	assert.Equal(t, []string{invoice.ReferenceID},
+		list(types.OperationFilter{Metadata: map[string]string{"order": "42", "channel": "web"}}))
+	assert.Equal(t, []string{refund.ReferenceID},
+		list(types.OperationFilter{Metadata: map[string]string{"reason": ""}}))
+	assert.Empty(t, list(types.OperationFilter{Metadata: map[string]string{"order": "43"}}))
+
+	page := list(types.OperationFilter{Limit: 2})
+	assert.Equal(t, []string{refund.ReferenceID, invoice.ReferenceID}, page)
+	last, err := repo.GetOperation(ctx, invoice.ReferenceID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{plain}, list(types.OperationFilter{Limit: 2, BeforeID: last.ID}))
+
+	_, err = repo.ListOperations(ctx, uuid.NewString(), types.OperationFilter{Limit: 1})
+	assert.ErrorIs(t, err, types.ErrWalletNotFound)
+}
+
+// testConcurrentWriters hits a single wallet from many goroutines. An
+// implementation may reject an update with types.ErrConcurrentUpdate, the
+// service retries those, but it must never lose or double-apply one.
+func testConcurrentWriters(t *testing.T, repo walletservice.ReadWriter) {
+	const (
+		writers   = 8
+		perWriter = 10
+	)
+
+	ctx := context.Background()
+	walletUUID := newWallet(t, repo, "", 0)
+	apply(t, repo, walletUUID, types.OperationTypeDeposit, 1000)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+	)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				operation, delta := types.OperationTypeDeposit, 7
+				if (w+i)%2 == 1 {
+					operation, delta = types.OperationTypeWithdraw, -5
+				}
+				_, err := repo.UpdateBalance(ctx,
+					types.NewWalletUpdateRequest(walletUUID, operation, abs(delta), uuid.NewString()))
+				if err != nil {
+					if !isConflict(err) {
+						t.Errorf("unexpected error: %v", err)
+					}
+					continue
+				}
+				mu.Lock()
+				applied += delta
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000+applied, balance(t, repo, walletUUID))
+
+	mismatches, err := repo.ReconcileBalances(ctx)
+	require.NoError(t, err)
+	for _, m := range mismatches {
+		assert.NotEqual(t, walletUUID, m.WalletUUID)
+	}
+}
+
+func isConflict(err error) bool {
+	return errors.Is(err, types.ErrConcurrentUpdate) ||
+		errors.Is(err, types.ErrDeadlock) ||
+		errors.Is(err, types.ErrSerializationFailure)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -2,17 +2,39 @@ package walletservice
 
 import (
 	"context"
+	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 )
 
 type Reader interface {
-	GetBalance(ctx context.Context, walletUUID string) (int, error)
+	GetBalance(ctx context.Context, walletUUID string) (types.Funds, error)
+	GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error)
+	GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error)
 	CheckOperationExists(ctx context.Context, referenceID string) (bool, error)
+	GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error)
+	GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error)
+	GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error)
+	StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error
+	GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error)
+	GetOperation(ctx context.Context, referenceID string) (*types.Operation, error)
+	ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error)
+	CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error)
+	ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error)
 }
 
 type Writer interface {
-	UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) error
+	UpdateBalance(ctx context.Context, wallet *types.WalletUpdateRequest) (types.Fee, error)
+	UpdateBalanceBatch(ctx context.Context, wallets []*types.WalletUpdateRequest) error
+	SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error
+	SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error
+	SnapshotBalances(ctx context.Context, day time.Time) (int64, error)
+	CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error)
+	SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error
+	SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error)
+	EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error)
+	ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error)
+	FailOperation(ctx context.Context, referenceID string, cause error) error
 }
 
 type ReadWriter interface {
@@ -0,0 +1,68 @@
+package walletservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// isRetryable reports whether the transaction failed only because of a
+// concurrent one, so running it again may succeed.
+func isRetryable(err error) bool {
+	return errors.Is(err, types.ErrDeadlock) ||
+		errors.Is(err, types.ErrSerializationFailure) ||
+		errors.Is(err, types.ErrConcurrentUpdate)
+}
+
+// isRetryableCacheError reports whether a redis call failed for a reason other
+// than a missing key or the caller giving up.
+func isRetryableCacheError(err error) bool {
+	return !errors.Is(err, redis.Nil) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+func (s *Service) dbPolicy(logger *zap.Logger) retry.Policy {
+	policy := s.dbRetry
+	policy.Retryable = isRetryable
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		logger.Warn("Transaction conflict, retrying...",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+	}
+	return policy
+}
+
+func (s *Service) cachePolicy(logger *zap.Logger) retry.Policy {
+	policy := s.cacheRetry
+	policy.Retryable = isRetryableCacheError
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		logger.Warn("Redis call failed, retrying...",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+	}
+	return policy
+}
+
+// retryResult turns the error returned by a db retry policy into the error
+// returned to the caller.
+func retryResult(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, retry.ErrAttemptsExhausted):
+		return types.ErrConflict(fmt.Errorf("too many retries due to concurrent updates: %w", err))
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		return types.ErrServiceUnavailable(fmt.Errorf("request canceled while retrying: %w", err))
+	default:
+		return err
+	}
+}
@@ -2,27 +2,79 @@ package walletservice
 
 import (
 	"context"
+	"time"
 
+	"github.com/artyomkorchagin/wallet-task/internal/logger"
+	"github.com/artyomkorchagin/wallet-task/internal/retry"
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 type ServiceInterface interface {
-	GetBalance(ctx context.Context, walletUUID string) (int, error)
-	UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) error
+	GetBalance(ctx context.Context, walletUUID string) (types.Funds, error)
+	GetBalances(ctx context.Context, walletUUIDs []string) ([]types.WalletBalance, error)
+	UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error)
+	EnqueueUpdate(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error)
+	UpdateBalanceBatch(ctx context.Context, atomic bool, reqs []*types.WalletUpdateRequest) ([]types.BatchItemResult, error)
+	GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error)
+	SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) (*types.WalletLimits, error)
+	SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) (types.Funds, error)
+	GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error)
+	GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (*types.HistoricalBalance, error)
+	StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error
+	CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error)
+	GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error)
+	SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) (*types.Wallet, error)
+	GetOperation(ctx context.Context, referenceID string) (*types.Operation, error)
+	ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) (*types.OperationsPage, error)
+	ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error)
+	FailureReport(ctx context.Context, since time.Time) (*types.FailureReport, error)
+	SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error)
 }
 
 type Service struct {
-	repo   ReadWriter
-	redis  *redis.Client
-	logger *zap.Logger
+	repo       ReadWriter
+	redis      *redis.Client
+	logger     *zap.Logger
+	dbRetry    retry.Policy
+	cacheRetry retry.Policy
 }
 
-func NewService(repo ReadWriter, redis *redis.Client, logger *zap.Logger) *Service {
-	return &Service{
-		repo:   repo,
-		redis:  redis,
-		logger: logger,
+// loggerFrom returns the logger of the request ctx belongs to, which carries
+// the request ID, route and client, or the service logger outside requests.
+func (s *Service) loggerFrom(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+type Option func(*Service)
+
+// WithDBRetry sets how balance updates are retried after transaction conflicts.
+// The retryable predicate is always supplied by the service.
+func WithDBRetry(policy retry.Policy) Option {
+	return func(s *Service) {
+		s.dbRetry = policy
+	}
+}
+
+// WithCacheRetry sets how redis calls are retried.
+// The retryable predicate is always supplied by the service.
+func WithCacheRetry(policy retry.Policy) Option {
+	return func(s *Service) {
+		s.cacheRetry = policy
+	}
+}
+
+func NewService(repo ReadWriter, redis *redis.Client, logger *zap.Logger, opts ...Option) *Service {
+	s := &Service{
+		repo:       repo,
+		redis:      redis,
+		logger:     logger,
+		dbRetry:    retry.DefaultPolicy(),
+		cacheRetry: retry.DefaultPolicy(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
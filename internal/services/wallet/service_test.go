@@ -2,6 +2,7 @@ package walletservice_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
 	"github.com/stretchr/testify/mock"
@@ -16,12 +17,133 @@ func (m *MockRepository) CheckOperationExists(ctx context.Context, referenceID s
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRepository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) error {
+func (m *MockRepository) UpdateBalance(ctx context.Context, req *types.WalletUpdateRequest) (types.Fee, error) {
 	args := m.Called(ctx, req)
+	fee, _ := args.Get(0).(types.Fee)
+	return fee, args.Error(1)
+}
+
+func (m *MockRepository) GetBalance(ctx context.Context, walletUUID string) (types.Funds, error) {
+	args := m.Called(ctx, walletUUID)
+	funds, _ := args.Get(0).(types.Funds)
+	return funds, args.Error(1)
+}
+
+func (m *MockRepository) UpdateBalanceBatch(ctx context.Context, reqs []*types.WalletUpdateRequest) error {
+	args := m.Called(ctx, reqs)
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetBalance(ctx context.Context, walletUUID string) (int, error) {
+func (m *MockRepository) GetBalances(ctx context.Context, walletUUIDs []string) (map[string]types.Funds, error) {
+	args := m.Called(ctx, walletUUIDs)
+	balances, _ := args.Get(0).(map[string]types.Funds)
+	return balances, args.Error(1)
+}
+
+func (m *MockRepository) GetWalletLimits(ctx context.Context, walletUUID string) (*types.WalletLimits, error) {
 	args := m.Called(ctx, walletUUID)
+	limits, _ := args.Get(0).(*types.WalletLimits)
+	return limits, args.Error(1)
+}
+
+func (m *MockRepository) SetWalletLimits(ctx context.Context, walletUUID string, update *types.WalletLimitsUpdate) error {
+	args := m.Called(ctx, walletUUID, update)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetCreditLimit(ctx context.Context, walletUUID string, creditLimit int) error {
+	args := m.Called(ctx, walletUUID, creditLimit)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetOverdraftWallets(ctx context.Context) ([]types.OverdraftWallet, error) {
+	args := m.Called(ctx)
+	wallets, _ := args.Get(0).([]types.OverdraftWallet)
+	return wallets, args.Error(1)
+}
+
+func (m *MockRepository) GetWalletOwners(ctx context.Context, walletUUIDs []string) (map[string]string, error) {
+	args := m.Called(ctx, walletUUIDs)
+	owners, _ := args.Get(0).(map[string]string)
+	return owners, args.Error(1)
+}
+
+func (m *MockRepository) GetBalanceAt(ctx context.Context, walletUUID string, at time.Time) (int, error) {
+	args := m.Called(ctx, walletUUID, at)
 	return args.Int(0), args.Error(1)
 }
+
+func (m *MockRepository) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	args := m.Called(ctx, day)
+	n, _ := args.Get(0).(int64)
+	return n, args.Error(1)
+}
+
+func (m *MockRepository) StreamStatement(ctx context.Context, walletUUID string, from, to time.Time, w types.StatementWriter) error {
+	args := m.Called(ctx, walletUUID, from, to, w)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWallet(ctx context.Context, owner string, creditLimit int) (*types.Wallet, error) {
+	args := m.Called(ctx, owner, creditLimit)
+	wallet, _ := args.Get(0).(*types.Wallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockRepository) GetWallet(ctx context.Context, walletUUID string) (*types.Wallet, error) {
+	args := m.Called(ctx, walletUUID)
+	wallet, _ := args.Get(0).(*types.Wallet)
+	return wallet, args.Error(1)
+}
+
+func (m *MockRepository) SetWalletFrozen(ctx context.Context, walletUUID string, frozen bool) error {
+	args := m.Called(ctx, walletUUID, frozen)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetOperation(ctx context.Context, referenceID string) (*types.Operation, error) {
+	args := m.Called(ctx, referenceID)
+	op, _ := args.Get(0).(*types.Operation)
+	return op, args.Error(1)
+}
+
+func (m *MockRepository) ReconcileBalances(ctx context.Context) ([]types.BalanceMismatch, error) {
+	args := m.Called(ctx)
+	mismatches, _ := args.Get(0).([]types.BalanceMismatch)
+	return mismatches, args.Error(1)
+}
+
+func (m *MockRepository) SweepPendingOperations(ctx context.Context, olderThan time.Duration) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	n, _ := args.Get(0).(int64)
+	return n, args.Error(1)
+}
+
+func (m *MockRepository) EnqueueOperation(ctx context.Context, req *types.WalletUpdateRequest) (*types.Operation, error) {
+	args := m.Called(ctx, req)
+	op, _ := args.Get(0).(*types.Operation)
+	return op, args.Error(1)
+}
+
+func (m *MockRepository) ClaimQueuedOperations(ctx context.Context, limit int, lease time.Duration) ([]*types.Operation, error) {
+	args := m.Called(ctx, limit, lease)
+	ops, _ := args.Get(0).([]*types.Operation)
+	return ops, args.Error(1)
+}
+
+func (m *MockRepository) FailOperation(ctx context.Context, referenceID string, cause error) error {
+	args := m.Called(ctx, referenceID, cause)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListOperations(ctx context.Context, walletUUID string, filter types.OperationFilter) ([]*types.Operation, error) {
+	args := m.Called(ctx, walletUUID, filter)
+	ops, _ := args.Get(0).([]*types.Operation)
+	return ops, args.Error(1)
+}
+
+func (m *MockRepository) CountFailedOperations(ctx context.Context, since time.Time) ([]types.FailureCount, error) {
+	args := m.Called(ctx, since)
+	counts, _ := args.Get(0).([]types.FailureCount)
+	return counts, args.Error(1)
+}
@@ -0,0 +1,93 @@
+package walletservice
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BalanceSnapshotter stores the end-of-day balances of a UTC day. It is
+// implemented by Service.
+type BalanceSnapshotter interface {
+	SnapshotBalances(ctx context.Context, day time.Time) (int64, error)
+}
+
+// SnapshotJob takes the end-of-day balance snapshots once a day, shortly after
+// UTC midnight. Running it in several instances is safe: a snapshot taken
+// twice is overwritten with the same balances.
+type SnapshotJob struct {
+	snapshotter BalanceSnapshotter
+	logger      *zap.Logger
+	now         func() time.Time
+	delay       time.Duration
+	retryDelay  time.Duration
+}
+
+type SnapshotJobOption func(*SnapshotJob)
+
+// WithSnapshotDelay sets how long after midnight the snapshot of the previous
+// day is taken. Operations are stamped with the start of their transaction, so
+// the delay must outlast the longest transaction still running at midnight.
+func WithSnapshotDelay(delay time.Duration) SnapshotJobOption {
+	return func(j *SnapshotJob) {
+		j.delay = delay
+	}
+}
+
+func NewSnapshotJob(snapshotter BalanceSnapshotter, logger *zap.Logger, opts ...SnapshotJobOption) *SnapshotJob {
+	j := &SnapshotJob{
+		snapshotter: snapshotter,
+		logger:      logger,
+		now:         time.Now,
+		delay:       5 * time.Minute,
+		retryDelay:  time.Minute,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Run takes the snapshot of the last finished day right away, so that a day
+// missed during downtime is caught up, and then once a day until ctx is done.
+// A failed snapshot is retried after a minute.
+func (j *SnapshotJob) Run(ctx context.Context) {
+	j.logger.Info("Balance snapshot job started",
+		zap.Duration("delay", j.delay))
+
+	for {
+		wait := j.nextRun().Sub(j.now())
+		if err := j.RunOnce(ctx); err != nil {
+			wait = j.retryDelay
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			j.logger.Info("Balance snapshot job stopped")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// RunOnce takes the snapshot of the last day that is over for at least delay.
+func (j *SnapshotJob) RunOnce(ctx context.Context) error {
+	day := j.now().UTC().Add(-j.delay).AddDate(0, 0, -1)
+	_, err := j.snapshotter.SnapshotBalances(ctx, day)
+	if err != nil && ctx.Err() == nil {
+		j.logger.Error("Failed to snapshot balances",
+			zap.String("day", day.Format(time.DateOnly)),
+			zap.Error(err))
+	}
+	return err
+}
+
+// nextRun returns when the snapshot of the current day is due.
+func (j *SnapshotJob) nextRun() time.Time {
+	now := j.now().UTC().Add(-j.delay)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return midnight.Add(j.delay)
+}
@@ -0,0 +1,69 @@
+package walletservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSnapshotter struct {
+	days []time.Time
+	err  error
+}
+
+func (f *fakeSnapshotter) SnapshotBalances(ctx context.Context, day time.Time) (int64, error) {
+	f.days = append(f.days, day)
+	return 1, f.err
+}
+
+func TestSnapshotJob_RunOnce(t *testing.T) {
+	tests := []struct {
+		name        string
+		now         time.Time
+		expectedDay string
+		expectedRun time.Time
+	}{
+		{
+			name:        "after the delay",
+			now:         time.Date(2026, 10, 1, 0, 10, 0, 0, time.UTC),
+			expectedDay: "2026-09-30",
+			expectedRun: time.Date(2026, 10, 2, 0, 5, 0, 0, time.UTC),
+		},
+		{
+			name:        "within the delay",
+			now:         time.Date(2026, 10, 1, 0, 2, 0, 0, time.UTC),
+			expectedDay: "2026-09-29",
+			expectedRun: time.Date(2026, 10, 1, 0, 5, 0, 0, time.UTC),
+		},
+		{
+			name:        "other time zone",
+			now:         time.Date(2026, 10, 1, 1, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+			expectedDay: "2026-09-29",
+			expectedRun: time.Date(2026, 10, 1, 0, 5, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshotter := &fakeSnapshotter{}
+			job := NewSnapshotJob(snapshotter, zap.NewNop(), WithSnapshotDelay(5*time.Minute))
+			job.now = func() time.Time { return tt.now }
+
+			require.NoError(t, job.RunOnce(context.Background()))
+			require.Len(t, snapshotter.days, 1)
+			assert.Equal(t, tt.expectedDay, snapshotter.days[0].Format(time.DateOnly))
+			assert.True(t, tt.expectedRun.Equal(job.nextRun()), "next run %s", job.nextRun())
+		})
+	}
+}
+
+func TestSnapshotJob_RunOnce_Error(t *testing.T) {
+	snapshotter := &fakeSnapshotter{err: assert.AnError}
+	job := NewSnapshotJob(snapshotter, zap.NewNop())
+
+	assert.ErrorIs(t, job.RunOnce(context.Background()), assert.AnError)
+}
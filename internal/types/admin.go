@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+// Wallet is a wallet as seen by administrators.
+type Wallet struct {
+	WalletUUID string `json:"valletId"`
+	Funds
+	Owner     string    `json:"owner,omitempty"`
+	Frozen    bool      `json:"frozen"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Operation is a single row of the operation log.
+type Operation struct {
+	ID                int64      `json:"id"`
+	WalletUUID        string     `json:"valletId"`
+	Operation         string     `json:"operationType"`
+	Amount            int        `json:"amount"`
+	ReferenceID       string     `json:"referenceId"`
+	ParentReferenceID string     `json:"parentReferenceId,omitempty"`
+	Status            string     `json:"status"`
+	BalanceAfter      *int       `json:"balanceAfter,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	AppliedAt         *time.Time `json:"appliedAt,omitempty"`
+	OperationDetails
+	// Async operations are queued by the API and applied by a worker
+	Async    bool `json:"async"`
+	Attempts int  `json:"attempts,omitempty"`
+	// FailureCode is one of the Failure* codes, set for FAILED operations
+	FailureCode    string `json:"failureCode,omitempty"`
+	FailureMessage string `json:"failureMessage,omitempty"`
+}
+
+// FailureCount is how many operations failed with a failure code.
+type FailureCount struct {
+	Code  string `json:"code"`
+	Count int64  `json:"count"`
+}
+
+// FailureReport counts the operations failed since a moment by failure code,
+// the most frequent first.
+type FailureReport struct {
+	Since  time.Time      `json:"since"`
+	Total  int64          `json:"total"`
+	Counts []FailureCount `json:"counts"`
+}
+
+// BalanceMismatch is a wallet whose balance differs from the balance after
+// its last applied operation.
+type BalanceMismatch struct {
+	WalletUUID      string `json:"valletId"`
+	Balance         int    `json:"balance"`
+	ExpectedBalance int    `json:"expectedBalance"`
+	LastReferenceID string `json:"lastReferenceId"`
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ScopeWalletRead  = "wallet:read"
+	ScopeWalletWrite = "wallet:write"
+	ScopeAdmin       = "admin"
+)
+
+// ValidScope reports whether scope is one of the known scopes.
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeWalletRead, ScopeWalletWrite, ScopeAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey is a key a service uses to call the API. Only the hash of the key is
+// stored, the key itself is shown once when it is created.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// HasScope reports whether the key grants scope. The admin scope grants all.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// JoinScopes and SplitScopes convert scopes to and from the space separated
+// form they are stored in.
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func SplitScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
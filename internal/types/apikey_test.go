@@ -0,0 +1,32 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey_HasScope(t *testing.T) {
+	read := &types.APIKey{Scopes: []string{types.ScopeWalletRead}}
+	assert.True(t, read.HasScope(types.ScopeWalletRead))
+	assert.False(t, read.HasScope(types.ScopeWalletWrite))
+	assert.False(t, read.HasScope(types.ScopeAdmin))
+
+	admin := &types.APIKey{Scopes: []string{types.ScopeAdmin}}
+	assert.True(t, admin.HasScope(types.ScopeWalletRead))
+	assert.True(t, admin.HasScope(types.ScopeWalletWrite))
+
+	none := &types.APIKey{}
+	assert.False(t, none.HasScope(types.ScopeWalletRead))
+}
+
+func TestScopes(t *testing.T) {
+	assert.True(t, types.ValidScope(types.ScopeWalletWrite))
+	assert.False(t, types.ValidScope("wallet:delete"))
+
+	scopes := []string{types.ScopeWalletRead, types.ScopeWalletWrite}
+	assert.Equal(t, "wallet:read wallet:write", types.JoinScopes(scopes))
+	assert.Equal(t, scopes, types.SplitScopes(" wallet:read  wallet:write "))
+	assert.Empty(t, types.SplitScopes(""))
+}
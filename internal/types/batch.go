@@ -0,0 +1,57 @@
+package types
+
+import "fmt"
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+const MaxBatchSize = 1000
+
+type BatchUpdateRequest struct {
+	Mode  string            `json:"mode" binding:"required,oneof=atomic best_effort"`
+	Items []BatchUpdateItem `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+type BatchUpdateItem struct {
+	WalletUUID  string `json:"valletId" binding:"required"`
+	Operation   string `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	ReferenceID string `json:"referenceId" binding:"required"`
+	OperationDetails
+}
+
+// Requests converts the batch items to the requests the service works with.
+func (b *BatchUpdateRequest) Requests() []*WalletUpdateRequest {
+	reqs := make([]*WalletUpdateRequest, 0, len(b.Items))
+	for _, item := range b.Items {
+		req := NewWalletUpdateRequest(item.WalletUUID, item.Operation, item.Amount, item.ReferenceID)
+		req.OperationDetails = item.OperationDetails
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+// BatchItemResult is the outcome of a single item of a batch.
+type BatchItemResult struct {
+	ReferenceID string `json:"referenceId"`
+	Status      int    `json:"status"`
+	Code        string `json:"code,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchItemError tells which item made an atomic batch fail.
+type BatchItemError struct {
+	Index       int
+	ReferenceID string
+	Err         error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d (reference_id %s): %s", e.Index, e.ReferenceID, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
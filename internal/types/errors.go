@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"errors"
 	"net/http"
 )
@@ -29,11 +30,19 @@ func (e HTTPError) Error() string {
 	return e.Err.Error()
 }
 
+func (e HTTPError) Unwrap() error {
+	return e.Err
+}
+
 var (
 	ErrBadRequest          = func(err error) HTTPError { return HTTPError{Code: http.StatusBadRequest, Err: err} }
+	ErrUnauthorized        = func(err error) HTTPError { return HTTPError{Code: http.StatusUnauthorized, Err: err} }
+	ErrForbidden           = func(err error) HTTPError { return HTTPError{Code: http.StatusForbidden, Err: err} }
 	ErrNotFound            = func(err error) HTTPError { return HTTPError{Code: http.StatusNotFound, Err: err} }
 	ErrInternalServerError = func(err error) HTTPError { return HTTPError{Code: http.StatusInternalServerError, Err: err} }
 	ErrConflict            = func(err error) HTTPError { return HTTPError{Code: http.StatusConflict, Err: err} }
+	ErrTooManyRequests     = func(err error) HTTPError { return HTTPError{Code: http.StatusTooManyRequests, Err: err} }
+	ErrServiceUnavailable  = func(err error) HTTPError { return HTTPError{Code: http.StatusServiceUnavailable, Err: err} }
 )
 
 var (
@@ -43,4 +52,118 @@ var (
 	ErrInvalidOperation  = errors.New("invalid operation type, must be DEPOSIT or WITHDRAW")
 	ErrOperationExists   = errors.New("operation with this reference_id already exists")
 	ErrDB                = errors.New("database error")
+	ErrAPIKeyMissing     = errors.New("api key is required")
+	ErrAPIKeyInvalid     = errors.New("api key is invalid")
+	ErrAPIKeyRevoked     = errors.New("api key is revoked")
+	ErrAPIKeyNotFound    = errors.New("api key not found")
+	ErrInsufficientScope = errors.New("api key does not grant the required scope")
+	ErrTokenMissing      = errors.New("bearer token is required")
+	ErrTokenInvalid      = errors.New("bearer token is invalid")
+	ErrRateLimited       = errors.New("rate limit exceeded")
+	ErrLimitExceeded     = errors.New("limit exceeded")
+	ErrUnknownTier       = errors.New("unknown limit tier")
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrWalletFrozen      = errors.New("wallet is frozen")
+	ErrOperationNotFound = errors.New("operation not found")
+)
+
+// Errors produced by classifying database failures by their SQLSTATE code.
+// They are wrapped together with the original driver error, so both
+// errors.Is against these values and errors.As to *pgconn.PgError work.
+var (
+	ErrDeadlock             = errors.New("deadlock detected")
+	ErrSerializationFailure = errors.New("could not serialize access due to concurrent update")
+	ErrUniqueViolation      = errors.New("unique constraint violated")
+	ErrCheckViolation       = errors.New("check constraint violated")
+	ErrForeignKeyViolation  = errors.New("foreign key constraint violated")
+	ErrQueryCanceled        = errors.New("query canceled")
 )
+
+// IsRejected reports whether an operation was refused because of the request
+// itself or the state of its wallet, so applying it again would fail the same
+// way. Conflicts with concurrent transactions and infrastructure failures are
+// not rejections.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrWalletNotFound) ||
+		errors.Is(err, ErrWalletFrozen) ||
+		errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrLimitExceeded) ||
+		errors.Is(err, ErrInvalidOperation) ||
+		errors.Is(err, ErrCheckViolation)
+}
+
+// Failure codes stored with FAILED operations. Dashboards group by them, so
+// they must not change once released.
+const (
+	FailureWalletNotFound    = "WALLET_NOT_FOUND"
+	FailureWalletFrozen      = "WALLET_FROZEN"
+	FailureInsufficientFunds = "INSUFFICIENT_FUNDS"
+	FailureLimitExceeded     = "LIMIT_EXCEEDED"
+	FailureInvalidOperation  = "INVALID_OPERATION"
+	FailureConcurrentUpdate  = "CONCURRENT_UPDATE"
+	FailureTimeout           = "TIMEOUT"
+	// FailureAbandoned marks operations swept after being pending for too long
+	FailureAbandoned = "ABANDONED"
+	FailureInternal  = "INTERNAL"
+	// FailureUnknown is reported for operations failed before codes were stored
+	FailureUnknown = "UNKNOWN"
+)
+
+// FailureCode returns the failure code stored for an operation that failed
+// with err.
+func FailureCode(err error) string {
+	switch {
+	case errors.Is(err, ErrWalletNotFound):
+		return FailureWalletNotFound
+	case errors.Is(err, ErrWalletFrozen):
+		return FailureWalletFrozen
+	case errors.Is(err, ErrInsufficientFunds):
+		return FailureInsufficientFunds
+	case errors.Is(err, ErrLimitExceeded):
+		return FailureLimitExceeded
+	case errors.Is(err, ErrInvalidOperation), errors.Is(err, ErrCheckViolation):
+		return FailureInvalidOperation
+	case errors.Is(err, ErrConcurrentUpdate), errors.Is(err, ErrDeadlock), errors.Is(err, ErrSerializationFailure):
+		return FailureConcurrentUpdate
+	case errors.Is(err, ErrQueryCanceled), errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return FailureTimeout
+	default:
+		return FailureInternal
+	}
+}
+
+// failureCauses are the errors whose text is stored as the failure message,
+// matched in order.
+var failureCauses = []error{
+	ErrWalletNotFound,
+	ErrWalletFrozen,
+	ErrInsufficientFunds,
+	ErrInvalidOperation,
+	ErrCheckViolation,
+	ErrConcurrentUpdate,
+	ErrDeadlock,
+	ErrSerializationFailure,
+	ErrQueryCanceled,
+	context.DeadlineExceeded,
+	context.Canceled,
+}
+
+// FailureMessage returns the failure message stored for an operation that
+// failed with err. Clients read it, so it is the text of the recognized cause
+// rather than err itself, which may carry database errors and other
+// internals.
+func FailureMessage(err error) string {
+	var limitErr *LimitExceededError
+	if errors.As(err, &limitErr) {
+		return limitErr.Error()
+	}
+	for _, cause := range failureCauses {
+		if errors.Is(err, cause) {
+			return cause.Error()
+		}
+	}
+	if errors.Is(err, ErrLimitExceeded) {
+		return ErrLimitExceeded.Error()
+	}
+	return "internal error"
+}
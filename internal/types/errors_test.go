@@ -1,11 +1,14 @@
 package types_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,6 +19,13 @@ func TestHTTPErrors_HaveCorrectCodes(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, types.ErrNotFound(err).Code)
 	assert.Equal(t, http.StatusInternalServerError, types.ErrInternalServerError(err).Code)
 	assert.Equal(t, http.StatusConflict, types.ErrConflict(err).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, types.ErrServiceUnavailable(err).Code)
+}
+
+func TestHTTPErrors_Unwrap(t *testing.T) {
+	httpErr := types.ErrInternalServerError(fmt.Errorf("failed to update wallet: %w", types.ErrDeadlock))
+
+	assert.ErrorIs(t, httpErr, types.ErrDeadlock)
 }
 
 func TestHTTPErrors_ErrorMethod(t *testing.T) {
@@ -33,3 +43,53 @@ func TestErrorConstants(t *testing.T) {
 	assert.Equal(t, "operation with this reference_id already exists", types.ErrOperationExists.Error())
 	assert.Equal(t, "database error", types.ErrDB.Error())
 }
+
+func TestFailureCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("failed to lock wallet: %w", types.ErrWalletNotFound), types.FailureWalletNotFound},
+		{types.ErrWalletFrozen, types.FailureWalletFrozen},
+		{types.ErrInsufficientFunds, types.FailureInsufficientFunds},
+		{fmt.Errorf("%w: daily withdrawal", types.ErrLimitExceeded), types.FailureLimitExceeded},
+		{types.ErrCheckViolation, types.FailureInvalidOperation},
+		{types.ErrSerializationFailure, types.FailureConcurrentUpdate},
+		{types.ErrConcurrentUpdate, types.FailureConcurrentUpdate},
+		{fmt.Errorf("failed to commit: %w", types.ErrQueryCanceled), types.FailureTimeout},
+		{context.DeadlineExceeded, types.FailureTimeout},
+		{errors.New("connection reset"), types.FailureInternal},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, types.FailureCode(tt.err), tt.err.Error())
+	}
+}
+
+func TestFailureMessage(t *testing.T) {
+	pgErr := &pgconn.PgError{
+		Code:    "40P01",
+		Message: "deadlock detected",
+		Detail:  "Process 4242 waits for ShareLock on transaction 1001; blocked by process 4243.",
+	}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("failed to lock wallet: %w", types.ErrWalletNotFound), "wallet not found"},
+		{fmt.Errorf("failed to update balance: %w: %w", types.ErrInsufficientFunds, pgErr), "insufficient funds"},
+		{&types.LimitExceededError{Limit: "daily_withdrawal", Max: 1000, Value: 1200},
+			"limit exceeded: daily_withdrawal is 1000, operation would make it 1200"},
+		{fmt.Errorf("failed to commit: %w: %w", types.ErrDeadlock, pgErr), "deadlock detected"},
+		{context.DeadlineExceeded, "context deadline exceeded"},
+		{fmt.Errorf("failed to begin transaction: %w", pgErr), "internal error"},
+	}
+
+	for _, tt := range tests {
+		got := types.FailureMessage(tt.err)
+		assert.Equal(t, tt.want, got, tt.err.Error())
+		assert.NotContains(t, got, "SQLSTATE")
+		assert.NotContains(t, got, "Process 4242")
+	}
+}
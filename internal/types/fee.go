@@ -0,0 +1,39 @@
+package types
+
+// FeeWalletUUID is the system wallet that collects the fees.
+const FeeWalletUUID = "00000000-0000-0000-0000-000000000fee"
+
+// FeeRule is how the fee of a withdrawal is computed for a limit tier: a flat
+// part plus a percentage of the amount in basis points, kept within the
+// optional MinFee and MaxFee.
+type FeeRule struct {
+	Flat       int  `json:"flat"`
+	PercentBps int  `json:"percentBps"`
+	MinFee     *int `json:"minFee"`
+	MaxFee     *int `json:"maxFee"`
+}
+
+// Fee is the fee charged for an operation. Total is what the wallet pays, it
+// differs from Flat+Percent when the fee was capped.
+type Fee struct {
+	Flat    int `json:"flat"`
+	Percent int `json:"percent"`
+	Total   int `json:"total"`
+}
+
+// Compute returns the fee for withdrawing amount. The percentage part is
+// rounded up so that fractions are never lost.
+func (r FeeRule) Compute(amount int) Fee {
+	fee := Fee{
+		Flat:    r.Flat,
+		Percent: (amount*r.PercentBps + 9999) / 10000,
+	}
+	fee.Total = fee.Flat + fee.Percent
+	if r.MinFee != nil && fee.Total < *r.MinFee {
+		fee.Total = *r.MinFee
+	}
+	if r.MaxFee != nil && fee.Total > *r.MaxFee {
+		fee.Total = *r.MaxFee
+	}
+	return fee
+}
@@ -0,0 +1,33 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeRule_Compute(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name   string
+		rule   types.FeeRule
+		amount int
+		want   types.Fee
+	}{
+		{"no fee", types.FeeRule{}, 1000, types.Fee{}},
+		{"flat", types.FeeRule{Flat: 10}, 1000, types.Fee{Flat: 10, Total: 10}},
+		{"percentage", types.FeeRule{PercentBps: 150}, 1000, types.Fee{Percent: 15, Total: 15}},
+		{"percentage rounded up", types.FeeRule{PercentBps: 150}, 101, types.Fee{Percent: 2, Total: 2}},
+		{"flat and percentage", types.FeeRule{Flat: 5, PercentBps: 100}, 1000, types.Fee{Flat: 5, Percent: 10, Total: 15}},
+		{"min fee", types.FeeRule{PercentBps: 100, MinFee: intPtr(20)}, 1000, types.Fee{Percent: 10, Total: 20}},
+		{"max fee", types.FeeRule{Flat: 5, PercentBps: 100, MaxFee: intPtr(50)}, 10000, types.Fee{Flat: 5, Percent: 100, Total: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Compute(tt.amount))
+		})
+	}
+}
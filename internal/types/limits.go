@@ -0,0 +1,56 @@
+package types
+
+import "fmt"
+
+// DefaultLimitTier is used for wallets that were not assigned a tier.
+const DefaultLimitTier = "standard"
+
+// Names of the limits, reported in LimitExceededError.
+const (
+	LimitMaxWithdrawal     = "max_withdrawal"
+	LimitDailyWithdrawal   = "daily_withdrawal"
+	LimitMonthlyWithdrawal = "monthly_withdrawal"
+	LimitMaxBalance        = "max_balance"
+)
+
+// LimitValues is a set of spending limits. A nil value means there is no such
+// limit.
+type LimitValues struct {
+	MaxWithdrawal     *int `json:"maxWithdrawal"`
+	DailyWithdrawal   *int `json:"dailyWithdrawal"`
+	MonthlyWithdrawal *int `json:"monthlyWithdrawal"`
+	MaxBalance        *int `json:"maxBalance"`
+}
+
+// WalletLimits describes the limits of a wallet: the ones of its tier with the
+// wallet's own overrides applied, and how much of the periodic ones is used.
+type WalletLimits struct {
+	WalletUUID       string      `json:"valletId"`
+	Tier             string      `json:"tier"`
+	Limits           LimitValues `json:"limits"`
+	Overrides        LimitValues `json:"overrides"`
+	DailyWithdrawn   int         `json:"dailyWithdrawn"`
+	MonthlyWithdrawn int         `json:"monthlyWithdrawn"`
+}
+
+// WalletLimitsUpdate replaces the tier and the overrides of a wallet.
+type WalletLimitsUpdate struct {
+	Tier      string      `json:"tier"`
+	Overrides LimitValues `json:"overrides"`
+}
+
+// LimitExceededError reports which limit an operation would break.
+// It matches ErrLimitExceeded with errors.Is.
+type LimitExceededError struct {
+	Limit string
+	Max   int
+	Value int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s is %d, operation would make it %d", ErrLimitExceeded, e.Limit, e.Max, e.Value)
+}
+
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
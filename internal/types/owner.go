@@ -0,0 +1,18 @@
+package types
+
+import "context"
+
+type ownerContextKey struct{}
+
+// ContextWithOwner marks ctx as a request made on behalf of an end user.
+// Such requests may only access wallets owned by that user.
+func ContextWithOwner(ctx context.Context, ownerID string) context.Context {
+	return context.WithValue(ctx, ownerContextKey{}, ownerID)
+}
+
+// OwnerFromContext returns the user a request is made for. It returns false
+// for requests made by services with API keys.
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	ownerID, ok := ctx.Value(ownerContextKey{}).(string)
+	return ownerID, ok && ownerID != ""
+}
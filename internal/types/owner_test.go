@@ -0,0 +1,21 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerContext(t *testing.T) {
+	_, ok := types.OwnerFromContext(context.Background())
+	assert.False(t, ok)
+
+	ownerID, ok := types.OwnerFromContext(types.ContextWithOwner(context.Background(), "user-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", ownerID)
+
+	_, ok = types.OwnerFromContext(types.ContextWithOwner(context.Background(), ""))
+	assert.False(t, ok)
+}
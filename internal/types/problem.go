@@ -0,0 +1,193 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ProblemContentType is the media type of error responses, see RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix turns an error code into the problem type URI.
+const problemTypePrefix = "urn:wallet-task:problem:"
+
+// Error codes returned in the code field of a Problem. Clients branch on
+// them, so they must not change once released.
+const (
+	CodeWalletNotFound       = "wallet_not_found"
+	CodeWalletFrozen         = "wallet_frozen"
+	CodeInsufficientFunds    = "insufficient_funds"
+	CodeLimitExceeded        = "limit_exceeded"
+	CodeInvalidOperation     = "invalid_operation"
+	CodeIdempotencyConflict  = "idempotency_conflict"
+	CodeConcurrentUpdate     = "concurrent_update"
+	CodeDeadlock             = "deadlock"
+	CodeSerializationFailure = "serialization_failure"
+	CodeOperationNotFound    = "operation_not_found"
+	CodeScheduleNotFound     = "schedule_not_found"
+	CodeUnknownTier          = "unknown_tier"
+	CodeAPIKeyMissing        = "api_key_missing"
+	CodeAPIKeyInvalid        = "api_key_invalid"
+	CodeAPIKeyRevoked        = "api_key_revoked"
+	CodeAPIKeyNotFound       = "api_key_not_found"
+	CodeTokenMissing         = "token_missing"
+	CodeTokenInvalid         = "token_invalid"
+	CodeInsufficientScope    = "insufficient_scope"
+	CodeRateLimited          = "rate_limited"
+	CodeValidationFailed     = "validation_failed"
+
+	// Codes of errors that are not recognized, by status
+	CodeBadRequest         = "bad_request"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodeInternal           = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// Problem is the body of an error response, an RFC 7807 problem details
+// object extended with a stable code, the request ID and field errors.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a validation error of a single request field. It is an error
+// itself, so the service can return it wrapped in ErrBadRequest.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// NewFieldError returns a validation error of the field, named as in the
+// request JSON.
+func NewFieldError(field, format string, args ...any) *FieldError {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+type problemType struct {
+	err   error
+	code  string
+	title string
+}
+
+// problemTypes are matched with errors.Is in order; the first match gives
+// the code and title of the problem.
+var problemTypes = []problemType{
+	{ErrWalletNotFound, CodeWalletNotFound, "Wallet not found"},
+	{ErrWalletFrozen, CodeWalletFrozen, "Wallet is frozen"},
+	{ErrInsufficientFunds, CodeInsufficientFunds, "Insufficient funds"},
+	{ErrLimitExceeded, CodeLimitExceeded, "Limit exceeded"},
+	{ErrInvalidOperation, CodeInvalidOperation, "Invalid operation"},
+	{ErrCheckViolation, CodeInvalidOperation, "Invalid operation"},
+	{ErrOperationExists, CodeIdempotencyConflict, "Operation already exists"},
+	{ErrConcurrentUpdate, CodeConcurrentUpdate, "Concurrent update"},
+	{ErrDeadlock, CodeDeadlock, "Deadlock detected"},
+	{ErrSerializationFailure, CodeSerializationFailure, "Serialization failure"},
+	{ErrOperationNotFound, CodeOperationNotFound, "Operation not found"},
+	{ErrScheduleNotFound, CodeScheduleNotFound, "Schedule not found"},
+	{ErrUnknownTier, CodeUnknownTier, "Unknown limit tier"},
+	{ErrAPIKeyMissing, CodeAPIKeyMissing, "API key required"},
+	{ErrAPIKeyInvalid, CodeAPIKeyInvalid, "Invalid API key"},
+	{ErrAPIKeyRevoked, CodeAPIKeyRevoked, "API key revoked"},
+	{ErrAPIKeyNotFound, CodeAPIKeyNotFound, "API key not found"},
+	{ErrTokenMissing, CodeTokenMissing, "Bearer token required"},
+	{ErrTokenInvalid, CodeTokenInvalid, "Invalid bearer token"},
+	{ErrInsufficientScope, CodeInsufficientScope, "Insufficient scope"},
+	{ErrRateLimited, CodeRateLimited, "Rate limit exceeded"},
+}
+
+// statusProblemTypes give the code and title of errors without a known cause.
+var statusProblemTypes = map[int]problemType{
+	http.StatusBadRequest:          {code: CodeBadRequest, title: "Bad request"},
+	http.StatusUnauthorized:        {code: CodeUnauthorized, title: "Unauthorized"},
+	http.StatusForbidden:           {code: CodeForbidden, title: "Forbidden"},
+	http.StatusNotFound:            {code: CodeNotFound, title: "Not found"},
+	http.StatusConflict:            {code: CodeConflict, title: "Conflict"},
+	http.StatusTooManyRequests:     {code: CodeRateLimited, title: "Rate limit exceeded"},
+	http.StatusInternalServerError: {code: CodeInternal, title: "Internal server error"},
+	http.StatusServiceUnavailable:  {code: CodeServiceUnavailable, title: "Service unavailable"},
+}
+
+// NewProblem describes err for the client. The status is the one of the
+// HTTPError in err, 500 for any other error. Server errors never carry the
+// error text, nor do errors that wrap a database error: their detail is the
+// message of the recognized cause, if any. Logging err is up to the caller.
+func NewProblem(err error) *Problem {
+	status := http.StatusInternalServerError
+	var httpErr HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+	}
+
+	pt, known := statusProblemTypes[status]
+	if !known {
+		pt = problemType{code: CodeBadRequest, title: http.StatusText(status)}
+		if status >= http.StatusInternalServerError {
+			pt.code = CodeInternal
+		}
+	}
+	detail := ""
+	if status < http.StatusInternalServerError {
+		for _, t := range problemTypes {
+			if errors.Is(err, t.err) {
+				pt = t
+				break
+			}
+		}
+		detail = err.Error()
+		if wrapsDatabaseError(err) {
+			detail = ""
+			if pt.err != nil {
+				detail = pt.err.Error()
+			}
+		}
+	}
+
+	p := &Problem{
+		Type:   problemTypePrefix + pt.code,
+		Title:  pt.title,
+		Status: status,
+		Detail: detail,
+		Code:   pt.code,
+	}
+
+	var fieldErr *FieldError
+	if status < http.StatusInternalServerError && errors.As(err, &fieldErr) {
+		fe := *fieldErr
+		var itemErr *BatchItemError
+		if errors.As(err, &itemErr) {
+			fe.Field = fmt.Sprintf("items[%d].%s", itemErr.Index, fe.Field)
+		}
+		p.SetFieldErrors([]FieldError{fe})
+	}
+	return p
+}
+
+// SetFieldErrors attaches validation errors, which makes p a
+// validation_failed problem.
+func (p *Problem) SetFieldErrors(errs []FieldError) {
+	p.Type = problemTypePrefix + CodeValidationFailed
+	p.Title = "Validation failed"
+	p.Code = CodeValidationFailed
+	p.Errors = errs
+}
+
+// wrapsDatabaseError reports whether err carries a driver error, whose text
+// may reveal queries, constraints and other internals.
+func wrapsDatabaseError(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	return errors.As(err, &sqlErr)
+}
@@ -0,0 +1,112 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/artyomkorchagin/wallet-task/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqlStateError stands in for a driver error such as *pgconn.PgError.
+type sqlStateError struct{}
+
+func (sqlStateError) Error() string    { return `ERROR: relation "wallet_operations" (SQLSTATE 40P01)` }
+func (sqlStateError) SQLState() string { return "40P01" }
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{
+			name:   "known cause",
+			err:    types.ErrBadRequest(fmt.Errorf("failed to apply: %w", types.ErrInsufficientFunds)),
+			status: http.StatusBadRequest,
+			code:   types.CodeInsufficientFunds,
+			detail: "failed to apply: insufficient funds",
+		},
+		{
+			name:   "idempotency conflict",
+			err:    types.ErrConflict(types.ErrOperationExists),
+			status: http.StatusConflict,
+			code:   types.CodeIdempotencyConflict,
+			detail: types.ErrOperationExists.Error(),
+		},
+		{
+			name:   "database error detail is hidden",
+			err:    types.ErrConflict(fmt.Errorf("failed to update wallet: %w: %w", types.ErrDeadlock, sqlStateError{})),
+			status: http.StatusConflict,
+			code:   types.CodeDeadlock,
+			detail: types.ErrDeadlock.Error(),
+		},
+		{
+			name:   "serialization failure",
+			err:    types.ErrConflict(fmt.Errorf("failed to commit: %w: %w", types.ErrSerializationFailure, sqlStateError{})),
+			status: http.StatusConflict,
+			code:   types.CodeSerializationFailure,
+			detail: types.ErrSerializationFailure.Error(),
+		},
+		{
+			name:   "unknown cause",
+			err:    types.ErrConflict(errors.New("too many retries")),
+			status: http.StatusConflict,
+			code:   types.CodeConflict,
+			detail: "too many retries",
+		},
+		{
+			name:   "internal error",
+			err:    types.ErrInternalServerError(fmt.Errorf("failed to load wallet: %w", sqlStateError{})),
+			status: http.StatusInternalServerError,
+			code:   types.CodeInternal,
+		},
+		{
+			name:   "internal error wrapping a known cause",
+			err:    types.ErrInternalServerError(types.ErrWalletNotFound),
+			status: http.StatusInternalServerError,
+			code:   types.CodeInternal,
+		},
+		{
+			name:   "plain error",
+			err:    errors.New("boom"),
+			status: http.StatusInternalServerError,
+			code:   types.CodeInternal,
+		},
+		{
+			name:   "service unavailable",
+			err:    types.ErrServiceUnavailable(types.ErrQueryCanceled),
+			status: http.StatusServiceUnavailable,
+			code:   types.CodeServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := types.NewProblem(tt.err)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, "urn:wallet-task:problem:"+tt.code, p.Type)
+			assert.NotEmpty(t, p.Title)
+			assert.Equal(t, tt.detail, p.Detail)
+			assert.Empty(t, p.Errors)
+		})
+	}
+}
+
+func TestNewProblem_FieldErrors(t *testing.T) {
+	p := types.NewProblem(types.ErrBadRequest(types.NewFieldError("amount", "amount must be positive")))
+	assert.Equal(t, types.CodeValidationFailed, p.Code)
+	assert.Equal(t, "amount must be positive", p.Detail)
+	assert.Equal(t, []types.FieldError{{Field: "amount", Message: "amount must be positive"}}, p.Errors)
+
+	p = types.NewProblem(types.ErrBadRequest(&types.BatchItemError{
+		Index: 2,
+		Err:   types.NewFieldError("referenceId", "ReferenceID is not valid UUID"),
+	}))
+	assert.Equal(t, []types.FieldError{{Field: "items[2].referenceId", Message: "ReferenceID is not valid UUID"}}, p.Errors)
+}
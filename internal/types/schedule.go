@@ -0,0 +1,77 @@
+package types
+
+import "time"
+
+// Statuses of a scheduled operation. An active schedule runs when it is due,
+// a paused one waits to be resumed, completed and cancelled ones never run
+// again.
+const (
+	ScheduleStatusActive    = "ACTIVE"
+	ScheduleStatusPaused    = "PAUSED"
+	ScheduleStatusCompleted = "COMPLETED"
+	ScheduleStatusCancelled = "CANCELLED"
+)
+
+// Outcomes of a single run of a schedule.
+const (
+	ScheduleRunApplied = "APPLIED"
+	ScheduleRunFailed  = "FAILED"
+)
+
+// Schedule is an operation applied to a wallet at RunAt once, or repeatedly
+// according to Recurrence, a cron expression evaluated in UTC.
+type Schedule struct {
+	ID         string     `json:"id"`
+	WalletUUID string     `json:"valletId"`
+	Operation  string     `json:"operationType"`
+	Amount     int        `json:"amount"`
+	RunAt      *time.Time `json:"runAt,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+	Status     string     `json:"status"`
+	NextRunAt  *time.Time `json:"nextRunAt,omitempty"`
+	Failures   int        `json:"failures"`
+	LastRunAt  *time.Time `json:"lastRunAt,omitempty"`
+	LastError  string     `json:"lastError,omitempty"`
+	CreatedBy  string     `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// ScheduleRequest creates a schedule. Exactly one of RunAt and Recurrence must
+// be set.
+type ScheduleRequest struct {
+	WalletUUID string     `json:"valletId" binding:"required"`
+	Operation  string     `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	Amount     int        `json:"amount" binding:"required,gt=0"`
+	RunAt      *time.Time `json:"runAt"`
+	Recurrence string     `json:"recurrence"`
+}
+
+// ScheduleUpdate changes a schedule. Nil fields are left as they are.
+type ScheduleUpdate struct {
+	Amount     *int    `json:"amount" binding:"omitempty,gt=0"`
+	Recurrence *string `json:"recurrence"`
+	Status     *string `json:"status" binding:"omitempty,oneof=ACTIVE PAUSED"`
+}
+
+// ScheduleChanges are the columns of a schedule changed through the API. Nil
+// fields are left as they are, so that an edit does not overwrite what a
+// concurrent run records.
+type ScheduleChanges struct {
+	Amount     *int
+	Recurrence *string
+	Status     *string
+	NextRunAt  *time.Time
+	// ClearNextRunAt unsets the next run, NextRunAt is ignored then
+	ClearNextRunAt bool
+	ResetFailures  bool
+}
+
+// ScheduleRun is the outcome of applying one occurrence of a schedule.
+type ScheduleRun struct {
+	ScheduleID  string    `json:"scheduleId"`
+	Occurrence  time.Time `json:"occurrence"`
+	ReferenceID string    `json:"referenceId"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
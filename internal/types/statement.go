@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// StatementEntry is an applied operation in an account statement. Balance is
+// the balance of the wallet after the operation, fee included.
+type StatementEntry struct {
+	AppliedAt   time.Time `json:"appliedAt"`
+	ReferenceID string    `json:"referenceId"`
+	Operation   string    `json:"operationType"`
+	Amount      int       `json:"amount"`
+	Fee         int       `json:"fee"`
+	Balance     int       `json:"balance"`
+}
+
+// StatementWriter receives a statement as it is read: the opening balance,
+// every operation in order and the closing balance.
+type StatementWriter interface {
+	WriteOpening(balance int) error
+	WriteEntry(entry *StatementEntry) error
+	WriteClosing(balance int) error
+}
@@ -1,22 +1,153 @@
 package types
 
+import (
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
 type WalletUpdateRequest struct {
 	WalletUUID  string `json:"valletId" binding:"required"`
 	Operation   string `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
 	Amount      int    `json:"amount" binding:"required,gt=0"`
-	ReferenceID string
+	ReferenceID string `json:"referenceId"`
+	OperationDetails
 }
 
 func NewWalletUpdateRequest(walletUUID string, operationType string, amount int, referenceid string) *WalletUpdateRequest {
 	return &WalletUpdateRequest{
-		walletUUID,
-		operationType,
-		amount,
-		referenceid,
+		WalletUUID:  walletUUID,
+		Operation:   operationType,
+		Amount:      amount,
+		ReferenceID: referenceid,
 	}
 }
 
+// Limits of the details a caller may attach to an operation.
+const (
+	MaxDescriptionLength   = 255
+	MaxExternalRefLength   = 128
+	MaxMetadataKeys        = 20
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 256
+)
+
+// OperationDetails is what the caller attaches to an operation to correlate
+// it later. None of it affects how the operation is applied.
+type OperationDetails struct {
+	Description string            `json:"description,omitempty"`
+	ExternalRef string            `json:"externalRef,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate checks the details against the limits above. Metadata keys may
+// only contain letters, digits, '_', '-' and '.'.
+func (d OperationDetails) Validate() error {
+	if utf8.RuneCountInString(d.Description) > MaxDescriptionLength {
+		return NewFieldError("description", "description must be at most %d characters", MaxDescriptionLength)
+	}
+	if utf8.RuneCountInString(d.ExternalRef) > MaxExternalRefLength {
+		return NewFieldError("externalRef", "externalRef must be at most %d characters", MaxExternalRefLength)
+	}
+	if len(d.Metadata) > MaxMetadataKeys {
+		return NewFieldError("metadata", "metadata must have at most %d keys", MaxMetadataKeys)
+	}
+	for key, value := range d.Metadata {
+		if err := ValidateMetadataKey(key); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			return NewFieldError("metadata."+key, "metadata value of %q must be at most %d characters", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// ValidateMetadataKey checks a metadata key of an operation or of a filter.
+func ValidateMetadataKey(key string) error {
+	if key == "" || utf8.RuneCountInString(key) > MaxMetadataKeyLength {
+		return NewFieldError("metadata", "metadata keys must be 1 to %d characters", MaxMetadataKeyLength)
+	}
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.' {
+			return NewFieldError("metadata", "metadata key %q may only contain letters, digits, '_', '-' and '.'", key)
+		}
+	}
+	return nil
+}
+
 var (
 	OperationTypeDeposit  = "DEPOSIT"
 	OperationTypeWithdraw = "WITHDRAW"
+	OperationTypeFee      = "FEE"
+)
+
+const MaxBalancesLookup = 500
+
+type BalancesRequest struct {
+	WalletUUIDs []string `json:"valletIds" binding:"required,min=1,max=500"`
+}
+
+// Funds describes how much money a wallet has. The balance may be negative
+// down to -CreditLimit, Available is what can still be withdrawn.
+type Funds struct {
+	Balance     int `json:"balance"`
+	CreditLimit int `json:"creditLimit"`
+	Available   int `json:"available"`
+}
+
+func NewFunds(balance, creditLimit int) Funds {
+	return Funds{
+		Balance:     balance,
+		CreditLimit: creditLimit,
+		Available:   balance + creditLimit,
+	}
+}
+
+// WalletBalance is the balance of a single wallet in a bulk lookup.
+type WalletBalance struct {
+	WalletUUID string `json:"valletId"`
+	Funds
+	Found bool `json:"found"`
+}
+
+// OverdraftWallet is a wallet whose balance is below zero.
+type OverdraftWallet struct {
+	WalletUUID string `json:"valletId"`
+	Funds
+}
+
+type CreditLimitRequest struct {
+	CreditLimit *int `json:"creditLimit" binding:"required,gte=0"`
+}
+
+// HistoricalBalance is the balance a wallet had at a point in time.
+type HistoricalBalance struct {
+	WalletUUID string    `json:"valletId"`
+	At         time.Time `json:"at"`
+	Balance    int       `json:"balance"`
+}
+
+// Limits of a page of operation history.
+const (
+	DefaultOperationsPage = 50
+	MaxOperationsPage     = 500
 )
+
+// OperationFilter selects the operations of a wallet's history. Metadata
+// matches operations whose metadata has every listed key with the given
+// value, an empty value only requires the key to be present. BeforeID pages
+// back from an earlier result.
+type OperationFilter struct {
+	ExternalRef string
+	Metadata    map[string]string
+	BeforeID    int64
+	Limit       int
+}
+
+// OperationsPage is a page of operation history, newest first. NextBeforeID
+// is set when there may be older operations.
+type OperationsPage struct {
+	Operations   []*Operation `json:"operations"`
+	NextBeforeID int64        `json:"nextBeforeId,omitempty"`
+}
@@ -2,6 +2,8 @@ package types_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/artyomkorchagin/wallet-task/internal/types"
@@ -42,3 +44,56 @@ func TestNewWalletUpdateRequest(t *testing.T) {
 	assert.Equal(t, 50, req.Amount)
 	assert.Equal(t, "ref-789", req.ReferenceID)
 }
+
+func TestOperationDetails_Validate(t *testing.T) {
+	valid := types.OperationDetails{
+		Description: "invoice payment",
+		ExternalRef: "INV-1",
+		Metadata:    map[string]string{"order.id": "42", "sales-channel_v2": "web"},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, types.OperationDetails{}.Validate())
+
+	tooManyKeys := make(map[string]string, types.MaxMetadataKeys+1)
+	for i := 0; i <= types.MaxMetadataKeys; i++ {
+		tooManyKeys[fmt.Sprintf("key%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		details types.OperationDetails
+	}{
+		{"description too long", types.OperationDetails{Description: strings.Repeat("ж", types.MaxDescriptionLength+1)}},
+		{"external ref too long", types.OperationDetails{ExternalRef: strings.Repeat("x", types.MaxExternalRefLength+1)}},
+		{"too many keys", types.OperationDetails{Metadata: tooManyKeys}},
+		{"empty key", types.OperationDetails{Metadata: map[string]string{"": "v"}}},
+		{"key too long", types.OperationDetails{Metadata: map[string]string{strings.Repeat("k", types.MaxMetadataKeyLength+1): "v"}}},
+		{"key with space", types.OperationDetails{Metadata: map[string]string{"order id": "42"}}},
+		{"value too long", types.OperationDetails{Metadata: map[string]string{"note": strings.Repeat("v", types.MaxMetadataValueLength+1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.details.Validate())
+		})
+	}
+
+	// Ограничения считаются в символах, а не в байтах
+	assert.NoError(t, types.OperationDetails{Description: strings.Repeat("ж", types.MaxDescriptionLength)}.Validate())
+}
+
+func TestWalletUpdateRequest_DetailsJSON(t *testing.T) {
+	var req types.WalletUpdateRequest
+	err := json.Unmarshal([]byte(`{
+        "valletId": "a1b2c3e4-5678-9012-3456-789012345678",
+        "operationType": "DEPOSIT",
+        "amount": 100,
+        "description": "top up",
+        "externalRef": "PSP-1",
+        "metadata": {"channel": "web"}
+    }`), &req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "top up", req.Description)
+	assert.Equal(t, "PSP-1", req.ExternalRef)
+	assert.Equal(t, map[string]string{"channel": "web"}, req.Metadata)
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE wallet ADD COLUMN IF NOT EXISTS owner_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_owner_id ON wallet(owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_owner_id;
+
+ALTER TABLE wallet DROP COLUMN IF EXISTS owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_limit_tiers (
+    tier VARCHAR(32) PRIMARY KEY,
+    max_withdrawal INTEGER CHECK (max_withdrawal > 0),
+    daily_withdrawal INTEGER CHECK (daily_withdrawal > 0),
+    monthly_withdrawal INTEGER CHECK (monthly_withdrawal > 0),
+    max_balance INTEGER CHECK (max_balance >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- NULL means there is no such limit
+INSERT INTO wallet_limit_tiers (tier) VALUES ('standard') ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS wallet_limits (
+    wallet_uuid UUID PRIMARY KEY REFERENCES wallet(wallet_uuid) ON DELETE CASCADE,
+    tier VARCHAR(32) NOT NULL DEFAULT 'standard' REFERENCES wallet_limit_tiers(tier),
+    max_withdrawal INTEGER CHECK (max_withdrawal > 0),
+    daily_withdrawal INTEGER CHECK (daily_withdrawal > 0),
+    monthly_withdrawal INTEGER CHECK (monthly_withdrawal > 0),
+    max_balance INTEGER CHECK (max_balance >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_withdrawals
+    ON wallet_operations(wallet_id, applied_at)
+    WHERE operation_type = 'WITHDRAW' AND status = 'APPLIED';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_operations_withdrawals;
+
+DROP TABLE IF EXISTS wallet_limits;
+
+DROP TABLE IF EXISTS wallet_limit_tiers;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE wallet ADD COLUMN IF NOT EXISTS credit_limit INTEGER NOT NULL DEFAULT 0
+    CONSTRAINT wallet_credit_limit_check CHECK (credit_limit >= 0);
+
+-- The balance may go down to -credit_limit. The constraint keeps its name,
+-- the repository maps its violation to insufficient funds.
+ALTER TABLE wallet DROP CONSTRAINT IF EXISTS wallet_balance_check;
+ALTER TABLE wallet ADD CONSTRAINT wallet_balance_check CHECK (balance >= -credit_limit);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_overdraft ON wallet(balance) WHERE balance < 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_overdraft;
+
+ALTER TABLE wallet DROP CONSTRAINT IF EXISTS wallet_balance_check;
+ALTER TABLE wallet ADD CONSTRAINT wallet_balance_check CHECK (balance >= 0);
+
+ALTER TABLE wallet DROP COLUMN IF EXISTS credit_limit;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_fee_rules (
+    tier VARCHAR(32) PRIMARY KEY REFERENCES wallet_limit_tiers(tier) ON DELETE CASCADE,
+    flat_fee INTEGER NOT NULL DEFAULT 0 CHECK (flat_fee >= 0),
+    percent_bps INTEGER NOT NULL DEFAULT 0 CHECK (percent_bps BETWEEN 0 AND 10000),
+    min_fee INTEGER CHECK (min_fee >= 0),
+    max_fee INTEGER CHECK (max_fee >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_fee IS NULL OR max_fee IS NULL OR min_fee <= max_fee)
+);
+
+-- Tiers without a rule are not charged
+INSERT INTO wallet_fee_rules (tier) VALUES ('standard') ON CONFLICT DO NOTHING;
+
+-- System wallet that collects the fees
+INSERT INTO wallet (wallet_uuid, balance) VALUES ('00000000-0000-0000-0000-000000000fee', 0)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE wallet_operations DROP CONSTRAINT IF EXISTS wallet_operations_operation_type_check;
+ALTER TABLE wallet_operations ADD CONSTRAINT wallet_operations_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'FEE'));
+
+-- A FEE operation points at the withdrawal it was charged for
+ALTER TABLE wallet_operations ADD COLUMN IF NOT EXISTS parent_reference_id UUID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM wallet_operations WHERE operation_type = 'FEE';
+
+ALTER TABLE wallet_operations DROP COLUMN IF EXISTS parent_reference_id;
+
+ALTER TABLE wallet_operations DROP CONSTRAINT IF EXISTS wallet_operations_operation_type_check;
+ALTER TABLE wallet_operations ADD CONSTRAINT wallet_operations_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW'));
+
+DELETE FROM wallet WHERE wallet_uuid = '00000000-0000-0000-0000-000000000fee';
+
+DROP TABLE IF EXISTS wallet_fee_rules;
+-- +goose StatementEnd
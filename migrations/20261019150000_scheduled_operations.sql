@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS scheduled_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_uuid UUID NOT NULL REFERENCES wallet(wallet_uuid) ON DELETE CASCADE,
+    operation_type VARCHAR(10) NOT NULL CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    run_at TIMESTAMP WITH TIME ZONE,
+    recurrence VARCHAR(128),
+    status VARCHAR(16) NOT NULL DEFAULT 'ACTIVE'
+        CHECK (status IN ('ACTIVE', 'PAUSED', 'COMPLETED', 'CANCELLED')),
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    -- Not picked up before this moment: a run in progress or a retry backoff
+    locked_until TIMESTAMP WITH TIME ZONE,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT scheduled_operations_when_check CHECK ((run_at IS NULL) <> (recurrence IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_operations_wallet ON scheduled_operations(wallet_uuid);
+CREATE INDEX IF NOT EXISTS idx_scheduled_operations_due
+    ON scheduled_operations(next_run_at) WHERE status = 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS scheduled_operation_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES scheduled_operations(id) ON DELETE CASCADE,
+    occurrence TIMESTAMP WITH TIME ZONE NOT NULL,
+    reference_id UUID NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('APPLIED', 'FAILED')),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_operation_runs_schedule
+    ON scheduled_operation_runs(schedule_id, occurrence);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scheduled_operation_runs;
+
+DROP TABLE IF EXISTS scheduled_operations;
+-- +goose StatementEnd
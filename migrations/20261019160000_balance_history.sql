@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Balance of the wallet right after an applied operation
+ALTER TABLE wallet_operations ADD COLUMN IF NOT EXISTS balance_after INTEGER;
+
+-- Rebuild the history of existing operations backwards from the current
+-- balances. A withdrawal also took the fee logged for it on the fee wallet.
+WITH effects AS (
+    SELECT o.id, o.wallet_id, o.applied_at,
+        CASE o.operation_type
+            WHEN 'WITHDRAW' THEN -(o.amount + COALESCE(f.amount, 0))
+            ELSE o.amount
+        END AS effect
+    FROM wallet_operations o
+    LEFT JOIN wallet_operations f ON f.parent_reference_id = o.reference_id
+    WHERE o.status = 'APPLIED'
+), history AS (
+    SELECT e.id,
+        w.balance - COALESCE(SUM(e.effect) OVER (
+            PARTITION BY e.wallet_id
+            ORDER BY e.applied_at DESC, e.id DESC
+            ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+        ), 0) AS balance_after
+    FROM effects e
+    JOIN wallet w ON w.wallet_uuid = e.wallet_id
+)
+UPDATE wallet_operations o SET balance_after = h.balance_after
+FROM history h
+WHERE o.id = h.id;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_wallet_applied
+    ON wallet_operations(wallet_id, applied_at) WHERE status = 'APPLIED';
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_parent_reference_id
+    ON wallet_operations(parent_reference_id) WHERE parent_reference_id IS NOT NULL;
+
+-- End-of-day balances; as_of is the midnight (UTC) that ends the day
+CREATE TABLE IF NOT EXISTS wallet_balance_snapshots (
+    wallet_uuid UUID NOT NULL REFERENCES wallet(wallet_uuid) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    balance INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wallet_uuid, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wallet_balance_snapshots;
+
+DROP INDEX IF EXISTS idx_wallet_operations_parent_reference_id;
+DROP INDEX IF EXISTS idx_wallet_operations_wallet_applied;
+
+ALTER TABLE wallet_operations DROP COLUMN IF EXISTS balance_after;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Frozen wallets accept no operations
+ALTER TABLE wallet ADD COLUMN IF NOT EXISTS frozen BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE wallet DROP COLUMN IF EXISTS frozen;
+-- +goose StatementEnd
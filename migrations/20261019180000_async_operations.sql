@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Async operations are stored PENDING by the API and claimed by workers for
+-- a lease, attempts counts the claims
+ALTER TABLE wallet_operations
+    ADD COLUMN IF NOT EXISTS async BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_queued
+    ON wallet_operations(id) WHERE async AND status = 'PENDING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_operations_queued;
+
+ALTER TABLE wallet_operations
+    DROP COLUMN IF EXISTS failure_reason,
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS async;
+-- +goose StatementEnd
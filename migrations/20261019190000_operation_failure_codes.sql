@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failure_code is one of the stable codes of types.Failure*, failure_message
+-- is the error text and may change between releases
+ALTER TABLE wallet_operations RENAME COLUMN failure_reason TO failure_message;
+ALTER TABLE wallet_operations ADD COLUMN IF NOT EXISTS failure_code TEXT;
+
+UPDATE wallet_operations SET failure_code = 'UNKNOWN'
+WHERE status = 'FAILED' AND failure_code IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_failed
+    ON wallet_operations(applied_at, failure_code) WHERE status = 'FAILED';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_operations_failed;
+
+ALTER TABLE wallet_operations DROP COLUMN IF EXISTS failure_code;
+ALTER TABLE wallet_operations RENAME COLUMN failure_message TO failure_reason;
+-- +goose StatementEnd
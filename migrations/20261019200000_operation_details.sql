@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The limits mirror types.Max*, the service rejects longer values before they
+-- get here
+ALTER TABLE wallet_operations
+    ADD COLUMN IF NOT EXISTS description TEXT
+        CHECK (char_length(description) <= 255),
+    ADD COLUMN IF NOT EXISTS external_ref TEXT
+        CHECK (char_length(external_ref) <= 128),
+    ADD COLUMN IF NOT EXISTS metadata JSONB
+        CHECK (jsonb_typeof(metadata) = 'object');
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_external_ref
+    ON wallet_operations(wallet_id, external_ref) WHERE external_ref IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_operations_metadata
+    ON wallet_operations USING GIN (metadata);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wallet_operations_metadata;
+DROP INDEX IF EXISTS idx_wallet_operations_external_ref;
+
+ALTER TABLE wallet_operations
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS external_ref,
+    DROP COLUMN IF EXISTS description;
+-- +goose StatementEnd
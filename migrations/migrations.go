@@ -0,0 +1,16 @@
+// Package migrations embeds the database schema migrations and the
+// development seed data, so the binaries do not depend on the working
+// directory they are started from.
+package migrations
+
+import "embed"
+
+// FS holds the goose migrations at its root.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Seed holds the test data applied by the opt-in seed mode.
+//
+//go:embed seed/*.sql
+var Seed embed.FS